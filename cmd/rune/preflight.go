@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/siddhartha/rune/internal/config"
+	"github.com/siddhartha/rune/internal/git"
+	"github.com/siddhartha/rune/internal/llm"
+	"github.com/siddhartha/rune/internal/models"
+	"github.com/siddhartha/rune/internal/ui"
+)
+
+const (
+	// maxLargerModels limits the models offered when the diff does not fit the selected one
+	maxLargerModels = 3
+	// defaultSummarizeAboveTokens is the prompt size above which diffs are summarized in parts
+	defaultSummarizeAboveTokens = 100_000
+	// summaryPartTokens is the size of the parts a large diff is split into
+	summaryPartTokens = 16_000
+)
+
+// errPreflightAborted is returned when the user decides not to send the prompt
+var errPreflightAborted = errors.New("aborted before sending")
+
+// preflight checks that the prompt fits the context window of the model and asks before
+// requests whose estimated cost exceeds the configured threshold. Very large diffs are
+// summarized in parts first. When the diff does not fit, the user can switch to a larger
+// model, summarize or compact the diff, or abort. It returns the model and client to generate
+// with, which change when the user switches.
+func preflight(ctx context.Context, cfg *config.Config, model *models.ModelInfo, client llm.LLMClient, request *llm.Request, preview *ui.StreamPreview, tracker *usageTracker) (*models.ModelInfo, llm.LLMClient, error) {
+	tokens, exact := countPromptTokens(ctx, cfg.GetTimeout(), client, request)
+	if verboseFlag {
+		ui.Info(fmt.Sprintf("Prompt: %s tokens", describeTokens(tokens, exact)))
+	}
+
+	threshold := cfg.SummarizeAboveTokens
+	if threshold == 0 {
+		threshold = defaultSummarizeAboveTokens
+	}
+	confirmed := false
+	if canSummarize(model) && threshold > 0 && tokens > threshold {
+		ui.Info(fmt.Sprintf("The diff is very large (%s tokens), summarizing it in parts first", describeTokens(tokens, exact)))
+		if err := confirmCost(cfg, model, tokens); err != nil {
+			return nil, nil, err
+		}
+		confirmed = true
+		if err := summarizeRequest(ctx, cfg.GetTimeout(), client, model, request); err != nil {
+			return nil, nil, err
+		}
+		tokens, exact = countPromptTokens(ctx, cfg.GetTimeout(), client, request)
+	}
+
+	canCompact := true
+	for model.ContextSize > 0 && tokens+llm.OutputReserve(model) > model.ContextSize {
+		limit := model.ContextSize - llm.OutputReserve(model)
+		larger := largerModels(model, tokens)
+		summarize := canSummarize(model) && len(request.Summaries) == 0
+		compact := canCompact && len(request.Summaries) == 0
+
+		var options []string
+		for _, candidate := range larger {
+			options = append(options, fmt.Sprintf("🔀 Switch to %s (%dk context)", modelLabel(candidate), candidate.ContextSize/1000))
+		}
+		if summarize {
+			options = append(options, "🧩 Summarize the diff in parts and write the message from the summaries")
+		}
+		if compact {
+			options = append(options, "🗜️  Compact the diff (drop context lines, summarize lock files, truncate large files)")
+		}
+		options = append(options, "🚫 Quit (cleanup staged files)")
+
+		ui.ShowPromptTooLarge(modelLabel(model), tokens, limit, exact, options)
+		var choice int
+		_, err := fmt.Fscanln(menuInput, &choice)
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errPreflightAborted
+		}
+		if err != nil || choice < 1 || choice > len(options) {
+			ui.Warning(fmt.Sprintf("Invalid choice. Please enter a number from 1 to %d.", len(options)))
+			continue
+		}
+
+		switch {
+		case choice <= len(larger):
+			var err error
+			model, client, err = switchModel(cfg, larger[choice-1], preview, tracker)
+			if err != nil {
+				return nil, nil, err
+			}
+			ui.Info(fmt.Sprintf("Switched to %s", modelLabel(model)))
+		case summarize && choice == len(larger)+1:
+			if err := summarizeRequest(ctx, cfg.GetTimeout(), client, model, request); err != nil {
+				return nil, nil, err
+			}
+		case compact && choice == len(options)-1:
+			compacted := compactRequestDiff(request, tokens, limit)
+			if len(compacted) >= len(request.Diff) {
+				ui.Warning("The diff cannot be compacted any further")
+				canCompact = false
+				continue
+			}
+			ui.Info(fmt.Sprintf("Compacted the diff from %d to %d characters", len(request.Diff), len(compacted)))
+			request.Diff = compacted
+		default:
+			return nil, nil, errPreflightAborted
+		}
+		tokens, exact = countPromptTokens(ctx, cfg.GetTimeout(), client, request)
+	}
+
+	if !confirmed {
+		if err := confirmCost(cfg, model, tokens); err != nil {
+			return nil, nil, err
+		}
+	}
+	return model, client, nil
+}
+
+// canSummarize reports whether a model can summarize the parts of a diff. The heuristic
+// generator only writes commit messages.
+func canSummarize(model *models.ModelInfo) bool {
+	return model.Provider != config.ProviderHeuristic
+}
+
+// summarizeRequest replaces the diff of request with summaries of its parts, each within timeout,
+// showing the progress in a spinner. Ctrl-C cancels the requests and returns errGenerationCancelled.
+func summarizeRequest(ctx context.Context, timeout time.Duration, client llm.LLMClient, model *models.ModelInfo, request *llm.Request) error {
+	partTokens := summaryPartTokens
+	if model.ContextSize > 0 {
+		partTokens = min(partTokens, (model.ContextSize-llm.OutputReserve(model))*3/4)
+	}
+
+	summarizeCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	spinner := ui.NewSpinner("Summarizing changes...")
+	spinner.Start()
+	defer spinner.Stop()
+
+	summarizeCtx = llm.WithRetryNotifier(summarizeCtx, func(event llm.RetryEvent) {
+		spinner.UpdateMessage(event.String())
+	})
+
+	summaries, err := llm.SummarizeDiff(summarizeCtx, client, request, llm.EstimateBytes(partTokens), timeout, func(done, total int) {
+		spinner.UpdateMessage(fmt.Sprintf("Summarizing changes (%d/%d parts)...", done, total))
+	})
+	if err != nil && summarizeCtx.Err() != nil && ctx.Err() == nil {
+		// Only the interrupt cancelled the requests, not a deadline
+		return errGenerationCancelled
+	}
+	if err != nil {
+		return fmt.Errorf("failed to summarize the diff: %w", err)
+	}
+
+	request.Summaries = summaries
+	if verboseFlag {
+		ui.Info(fmt.Sprintf("Summarized the diff in %d parts", len(summaries)))
+	}
+	return nil
+}
+
+// countPromptTokens counts the prompt tokens of request within timeout, behind a spinner when
+// the provider is asked
+func countPromptTokens(ctx context.Context, timeout time.Duration, client llm.LLMClient, request *llm.Request) (int, bool) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if _, ok := client.(llm.TokenCounter); !ok {
+		return llm.CountPromptTokens(ctx, client, request)
+	}
+
+	spinner := ui.NewSpinner("Counting prompt tokens...")
+	spinner.Start()
+	defer spinner.Stop()
+	return llm.CountPromptTokens(ctx, client, request)
+}
+
+// largerModels returns registry models whose context window holds a prompt of tokens and
+// their output, models of the current provider first and then the smallest sufficient windows
+func largerModels(current *models.ModelInfo, tokens int) []*models.ModelInfo {
+	var larger []*models.ModelInfo
+	for _, model := range models.GetAllModels() {
+		if model.ContextSize < tokens+llm.OutputReserve(model) || (model.ID == current.ID && model.Provider == current.Provider) {
+			continue
+		}
+		larger = append(larger, model)
+	}
+
+	sort.SliceStable(larger, func(i, j int) bool {
+		iSame, jSame := larger[i].Provider == current.Provider, larger[j].Provider == current.Provider
+		if iSame != jSame {
+			return iSame
+		}
+		return larger[i].ContextSize < larger[j].ContextSize
+	})
+
+	if len(larger) > maxLargerModels {
+		larger = larger[:maxLargerModels]
+	}
+	return larger
+}
+
+// switchModel makes model the selected model for this run and creates its client
+func switchModel(cfg *config.Config, model *models.ModelInfo, preview *ui.StreamPreview, tracker *usageTracker) (*models.ModelInfo, llm.LLMClient, error) {
+	if model.Provider != cfg.Provider {
+		if err := cfg.EnsureAPIKeyForProvider(model.Provider); err != nil {
+			return nil, nil, fmt.Errorf("failed to setup provider %s: %w", model.Provider, err)
+		}
+		cfg.Provider = model.Provider
+	}
+	cfg.Model = model.ID
+
+	client, err := newGenerationClient(cfg, model, preview, tracker)
+	if err != nil {
+		return nil, nil, err
+	}
+	return model, client, nil
+}
+
+// compactRequestDiff compacts the diff of request so that a prompt of tokens fits limit,
+// scaling the diff with the ratio of tokens to characters of the current prompt
+func compactRequestDiff(request *llm.Request, tokens, limit int) string {
+	overhead := llm.EstimateTokens(llm.BuildCommitPrompt(&llm.Request{Notes: request.Notes, Intent: request.Intent}))
+	diffTokens := tokens - overhead
+	if diffTokens <= 0 || limit <= overhead {
+		return request.Diff
+	}
+
+	// Aim 10% below the limit, the ratio of the compacted diff differs slightly
+	target := len(request.Diff) * (limit - overhead) / diffTokens * 9 / 10
+	return git.CompactDiff(request.Diff, target)
+}
+
+// confirmCost asks before sending when the estimated cost exceeds the confirm_cost_above threshold.
+// The estimate assumes every candidate uses the whole output reserve, so it is an upper bound.
+func confirmCost(cfg *config.Config, model *models.ModelInfo, tokens int) error {
+	if cfg.ConfirmCostAbove <= 0 || model.Pricing == nil {
+		return nil
+	}
+
+	cost := model.Pricing.Cost(tokens*candidatesFlag, llm.OutputReserve(model)*candidatesFlag)
+	if cost <= cfg.ConfirmCostAbove {
+		return nil
+	}
+
+	ui.ShowCostConfirmation(modelLabel(model), formatCost(cost), formatCost(cfg.ConfirmCostAbove))
+	var answer string
+	_, _ = fmt.Fscanln(menuInput, &answer)
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return errPreflightAborted
+	}
+}
+
+// describeTokens formats a token count, marking estimates
+func describeTokens(tokens int, exact bool) string {
+	if exact {
+		return fmt.Sprintf("%d", tokens)
+	}
+	return fmt.Sprintf("~%d", tokens)
+}
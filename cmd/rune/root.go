@@ -2,16 +2,23 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"os/signal"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/siddhartha/rune/internal/apidiff"
 	"github.com/siddhartha/rune/internal/commit"
 	"github.com/siddhartha/rune/internal/config"
+	"github.com/siddhartha/rune/internal/deps"
 	"github.com/siddhartha/rune/internal/git"
 	"github.com/siddhartha/rune/internal/llm"
 	"github.com/siddhartha/rune/internal/models"
@@ -19,10 +26,15 @@ import (
 )
 
 const (
-	// Default timeout for API requests
-	defaultTimeoutSeconds = 60
+	// Maximum number of breaking API changes listed in the prompt and footer
+	maxBreakingChangesShown = 10
+	// Time allowed for type checking the changed Go packages before the check is skipped
+	breakingChangeTimeout = 15 * time.Second
 )
 
+// errGenerationCancelled is returned when the user presses Ctrl-C while a message is generated
+var errGenerationCancelled = errors.New("generation cancelled")
+
 var (
 	// Command line flags
 	editFlag       bool
@@ -34,8 +46,28 @@ var (
 	dryRunFlag     bool
 	verboseFlag    bool
 	setupFlag      bool
+	excludeWSFlag  bool
+	candidatesFlag int
+
+	// Generation options, overriding the model_options of the config for this run
+	temperatureFlag  float64
+	topPFlag         float64
+	maxTokensFlag    int
+	seedFlag         int
+	stopFlag         []string
+	systemPromptFlag string
+
+	showReasoningFlag bool
+
+	// Why the change was made, given inline ("-" reads stdin) or in a file
+	contextFlag     string
+	contextFileFlag string
 )
 
+// menuInput is where the menus and the editor read from. It is the terminal when stdin
+// carried the intent hint.
+var menuInput = os.Stdin
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "rune",
@@ -48,6 +80,17 @@ the generated message before committing.` + models.FormatModelsHelp(),
 	RunE: generateCommitMessage,
 }
 
+// modelsCmd lists the registry models and the models installed on the local Ollama server
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List available models, including locally installed Ollama models",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		printAllModels()
+		return nil
+	},
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	err := rootCmd.Execute()
@@ -68,6 +111,20 @@ func init() {
 	rootCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Generate commit message without actually committing")
 	rootCmd.Flags().BoolVarP(&verboseFlag, "verbose", "v", false, "Enable verbose output")
 	rootCmd.Flags().BoolVar(&setupFlag, "setup", false, "Run interactive setup to configure AI provider")
+	rootCmd.Flags().BoolVar(&excludeWSFlag, "exclude-whitespace", false, "Leave whitespace-only hunks out of the prompt")
+	rootCmd.Flags().IntVar(&candidatesFlag, "candidates", 1, fmt.Sprintf("Number of alternative commit messages to generate (1-%d)", llm.MaxCandidates))
+	rootCmd.Flags().Float64Var(&temperatureFlag, "temperature", llm.DefaultTemperature, "Sampling temperature (0-2), overrides the config for this run")
+	rootCmd.Flags().Float64Var(&topPFlag, "top-p", 1, "Nucleus sampling probability (0-1], overrides the config for this run")
+	rootCmd.Flags().IntVar(&maxTokensFlag, "max-tokens", 0, "Maximum tokens of the generated message, overrides the config for this run")
+	rootCmd.Flags().IntVar(&seedFlag, "seed", 0, "Seed for reproducible sampling where the provider supports it")
+	rootCmd.Flags().StringArrayVar(&stopFlag, "stop", nil, "Stop sequence, can be repeated")
+	rootCmd.Flags().StringVar(&systemPromptFlag, "system-prompt", "", "Replace the system prompt for this run")
+	rootCmd.Flags().BoolVar(&showReasoningFlag, "show-reasoning", false, "Print the reasoning of reasoning models separately from the message")
+	rootCmd.Flags().StringVarP(&contextFlag, "context", "c", "", "Why the change was made, the message body reflects it (\"-\" reads it from stdin)")
+	rootCmd.Flags().StringVar(&contextFileFlag, "context-file", "", "Read why the change was made from a file")
+
+	rootCmd.AddCommand(modelsCmd)
+	rootCmd.AddCommand(usageCmd)
 }
 
 // generateCommitMessage is the main function that orchestrates the commit message generation
@@ -98,6 +155,26 @@ func generateCommitMessage(cmd *cobra.Command, args []string) error {
 	if allFlag && stagedOnlyFlag {
 		return fmt.Errorf("cannot use both --all and --staged-only flags together")
 	}
+	if candidatesFlag < 1 || candidatesFlag > llm.MaxCandidates {
+		return fmt.Errorf("--candidates must be between 1 and %d", llm.MaxCandidates)
+	}
+	options := optionsFromFlags(cmd)
+	if err := options.Validate(); err != nil {
+		return fmt.Errorf("invalid generation options: %w", err)
+	}
+	intent, err := intentFromFlags(os.Stdin)
+	if err != nil {
+		return err
+	}
+	if contextFlag == "-" {
+		// Stdin is used up by the hint, so the menus read from the terminal
+		tty, err := os.Open("/dev/tty")
+		if err != nil {
+			return fmt.Errorf("--context - reads stdin, which leaves no terminal for the menu; use --context-file instead: %w", err)
+		}
+		defer tty.Close()
+		menuInput = tty
+	}
 
 	// Load configuration
 	cfg, err := config.Load()
@@ -114,14 +191,9 @@ func generateCommitMessage(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Use configurable timeout with constant default
-	timeout := defaultTimeoutSeconds * time.Second
-	if cfg.TimeoutSeconds > 0 {
-		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	// Each provider call gets its own deadline, time spent in the menus does not count
+	ctx := context.Background()
+	timeout := cfg.GetTimeout()
 
 	// check if the current directory is a git repository
 	if !isGitRepository() {
@@ -142,6 +214,9 @@ func generateCommitMessage(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to resolve model: %w", err)
 	}
+	if selectedModel.Endpoint != "" {
+		cfg.Endpoint = selectedModel.Endpoint
+	}
 
 	// Check if we need to switch providers
 	if selectedModel.Provider != cfg.Provider {
@@ -240,34 +315,227 @@ func generateCommitMessage(cmd *cobra.Command, args []string) error {
 		ui.Info(fmt.Sprintf("Found %d characters of changes", len(diff)))
 	}
 
-	// Initialize the LLM client with selected model
+	// The request keeps the intent for the whole session, so regenerations and refinements use it
+	request := &llm.Request{Diff: diff, Options: options, Intent: intent}
+
+	// Dependency-only changes get a deterministic message without calling the provider
+	var pendingMessage *commit.Message
+	if update := deps.Detect(git.ParseDiff(diff)); update != nil {
+		pendingMessage = update.Message()
+		if verboseFlag {
+			ui.Info(fmt.Sprintf("Detected dependency update (%d changes), skipping AI generation", len(update.Changes)))
+		}
+	}
+
+	// Formatting-only changes get a style message, mixed diffs can drop formatting hunks
+	var formattingFiles []string
+	whitespace, err := git.AnalyzeWhitespace(getStagedDiff, diff)
+	if err != nil {
+		if verboseFlag {
+			ui.Warning(fmt.Sprintf("Skipped whitespace analysis: %v", err))
+		}
+	} else if whitespace.OnlyWhitespace {
+		if pendingMessage == nil {
+			pendingMessage = commit.FormattingMessage(changedPaths(diff))
+		}
+		if verboseFlag {
+			ui.Info("Detected formatting-only changes, skipping AI generation")
+		}
+	} else if whitespace.HasWhitespaceHunks() && (excludeWSFlag || cfg.ExcludeWhitespaceHunks) {
+		request.Diff = whitespace.FilteredDiff
+		formattingFiles = whitespace.Files
+		if verboseFlag {
+			ui.Info(fmt.Sprintf("Excluded %d whitespace-only hunks from the prompt", whitespace.ExcludedHunks))
+		}
+	}
+
+	// Check exported Go APIs for incompatible changes
+	var breakingChanges []apidiff.Change
+	if cfg.BreakingChangeDetection() && touchesGoFiles(diff) {
+		spinner = ui.NewSpinner("Checking Go APIs for breaking changes...")
+		spinner.Start()
+		detectCtx, cancel := context.WithTimeout(ctx, breakingChangeTimeout)
+		breakingChanges, err = apidiff.DetectBreakingChanges(detectCtx, getStagedDiff)
+		cancel()
+		spinner.Stop()
+
+		if err != nil {
+			if verboseFlag {
+				ui.Warning(fmt.Sprintf("Skipped breaking change detection: %v", err))
+			}
+		} else if len(breakingChanges) > 0 {
+			request.Notes = append(request.Notes, "This change breaks the exported Go API. Use a conventional type with a \"!\" marker and describe the break in a \"BREAKING CHANGE:\" footer:\n"+
+				apidiff.Summarize(breakingChanges, maxBreakingChangesShown))
+		}
+	}
+
+	// The streamed preview is cleared before warnings are printed over it
+	preview := ui.NewStreamPreview()
+
+	// Token usage of every call is priced and recorded in the usage ledger
+	ledger, err := openLedger()
+	if err != nil {
+		return err
+	}
+	tracker := newUsageTracker(ledger)
+	ctx = llm.WithUsageRecorder(ctx, tracker.record)
+	defer tracker.flush() // calls of runs that end before a message is generated
+
+	// Reasoning models think before answering, their reasoning is kept out of the message
+	reasoning := &reasoningLog{}
+	if verboseFlag || showReasoningFlag {
+		ctx = llm.WithReasoningHandler(ctx, reasoning.record)
+	}
+
+	// Initialize the LLM client with selected model and its fallbacks
 	cfg.Model = selectedModel.ID // Update model for client creation
-	client, err := llm.NewLLMClient(cfg)
+	client, err := newGenerationClient(cfg, selectedModel, preview, tracker)
 	if err != nil {
-		return fmt.Errorf("failed to initialize LLM client: %w", err)
+		return err
+	}
+	checked := false
+
+	// decorate adds the notes from the diff analysis to a generated message
+	decorate := func(message *commit.Message) *commit.Message {
+		if len(formattingFiles) > 0 {
+			message.AppendParagraph(commit.FormattingNote(formattingFiles))
+		}
+		if len(breakingChanges) > 0 {
+			message.MarkBreaking(breakingChangeDescription(breakingChanges))
+		}
+		return message
+	}
+
+	// Every message seen in this session stays in the menu so the user can go back to it
+	var session []*commit.Message
+	current := 0
+	if pendingMessage != nil {
+		session = append(session, decorate(pendingMessage))
+	}
+
+	// Refinements continue the conversation that produced a message, and can be undone
+	origins := map[*commit.Message]*llm.Request{}
+	var refinement *llm.Request
+	var undo []int
+
+	// recordUsage writes the calls made so far to the ledger
+	recordUsage := func() {
+		for _, entry := range tracker.flush() {
+			if verboseFlag {
+				ui.Info(describeUsage(entry))
+			}
+		}
 	}
 
 	var finalMessage string
 	for {
-		spinner := ui.NewSpinner("Generating commit message...")
-		spinner.Start()
+		if current == len(session) {
+			if !checked {
+				// Make sure the prompt fits the model and is affordable before the first request
+				selectedModel, client, err = preflight(ctx, cfg, selectedModel, client, request, preview, tracker)
+				if errors.Is(err, errPreflightAborted) {
+					ui.Info("Aborted. No commit was made.")
+					return nil // defer will handle cleanup
+				}
+				if errors.Is(err, errGenerationCancelled) {
+					ui.Info("Generation cancelled. No commit was made.")
+					return nil // defer will handle cleanup
+				}
+				if err != nil {
+					return err
+				}
+				checked = true
+			}
 
-		// Generate the commit message
-		rawMessage, err := client.GenerateCommitMessage(ctx, diff)
-		spinner.UpdateMessage("Formatting commit message...")
+			// Every round is checked against the budget, including the calls of earlier rounds
+			recordUsage()
+			if err := checkBudget(cfg, ledger, selectedModel); err != nil {
+				return err
+			}
 
-		if err != nil {
-			spinner.Stop()
-			return fmt.Errorf("failed to generate commit message: %w", err)
-		}
+			// Generate new messages, showing a single one as it streams in
+			generation := request
+			if refinement != nil {
+				generation = refinement
+			}
+			var rawMessages []string
+			if candidatesFlag > 1 && refinement == nil {
+				rawMessages, err = generateCandidates(ctx, timeout, client, generation, candidatesFlag)
+			} else {
+				var rawMessage string
+				rawMessage, err = streamCommitMessage(ctx, timeout, client, generation, preview)
+				preview.Clear()
+				rawMessages = []string{rawMessage}
+			}
 
-		// Format the commit message
-		message, err := commit.FormatCommitMessage(rawMessage)
-		spinner.Stop()
+			recordUsage()
+			for _, text := range reasoning.flush() {
+				if showReasoningFlag {
+					ui.ShowReasoning(text)
+				} else {
+					ui.Info(fmt.Sprintf("The model reasoned for ~%d tokens before answering (--show-reasoning prints it)", llm.EstimateTokens(text)))
+				}
+			}
 
-		if err != nil {
-			return fmt.Errorf("failed to format commit message: %w", err)
+			if errors.Is(err, errGenerationCancelled) {
+				ui.Info("Generation cancelled. No commit was made.")
+				return nil // defer will handle cleanup
+			}
+			if errors.Is(err, llm.ErrRefinementNotSupported) {
+				ui.Warning(err.Error())
+				current, refinement = undo[len(undo)-1], nil
+				undo = undo[:len(undo)-1]
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to generate commit message: %w", err)
+			}
+
+			if verboseFlag {
+				producer := modelLabel(selectedModel)
+				if chain, ok := client.(*llm.FallbackClient); ok {
+					producer = chain.LastUsed()
+				}
+				ui.Info(fmt.Sprintf("Message generated by %s", producer))
+			}
+
+			// Format the commit messages, showing the first new one
+			selected := -1
+			var rejected error
+			for _, rawMessage := range rawMessages {
+				message, err := commit.ParseGenerated(rawMessage)
+				if errors.Is(err, commit.ErrNotAMessage) {
+					rejected = err
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("failed to format commit message: %w", err)
+				}
+				index := indexOfMessage(session, decorate(message))
+				if index < 0 {
+					session = append(session, message)
+					origins[message] = generation
+					index = len(session) - 1
+				}
+				if selected < 0 {
+					selected = index
+				}
+			}
+			if selected < 0 {
+				// Every answer was a refusal, show the latest message when there is one
+				if len(session) == 0 {
+					return rejected
+				}
+				ui.Warning(fmt.Sprintf("Discarded the new answer: %v", rejected))
+				selected = len(session) - 1
+				if refinement != nil {
+					selected = undo[len(undo)-1]
+					undo = undo[:len(undo)-1]
+				}
+			}
+			current, refinement = selected, nil
 		}
+		message := session[current]
 
 		// Validate the message
 		if err := commit.ValidateMessage(message); err != nil {
@@ -275,18 +543,33 @@ func generateCommitMessage(cmd *cobra.Command, args []string) error {
 		}
 
 		ui.PreviewCommitMessage(message.Format())
-		ui.ShowCommitOptions()
+		if len(breakingChanges) > 0 {
+			ui.ShowBreakingChangeWarning(strings.Split(apidiff.Summarize(breakingChanges, maxBreakingChangesShown), "\n"))
+		}
+		if len(session) > 1 {
+			subjects := make([]string, len(session))
+			for i, candidate := range session {
+				subjects[i] = candidate.Subject
+			}
+			ui.ShowCandidates(subjects, current)
+		}
+		ui.ShowCommitOptions(len(session), len(undo) > 0)
 		var choice string
-		if _, err := fmt.Scanln(&choice); err != nil {
+		if _, err := fmt.Fscanln(menuInput, &choice); err != nil {
+			if errors.Is(err, io.EOF) {
+				ui.Info("Input closed. Aborted, no commit was made.")
+				return nil // defer will handle cleanup
+			}
 			ui.Warning(fmt.Sprintf("Failed to read input: %v", err))
 		}
 
-		switch choice {
-		case "1":
-			continue // re-generate
-		case "2":
+		switch {
+		case choice == "1":
+			current = len(session) // re-generate
+			continue
+		case choice == "2":
 			finalMessage = message.Format()
-		case "3":
+		case choice == "3":
 			editedMessage, err := openEditor(message.Format())
 			if err != nil {
 				return fmt.Errorf("failed to open editor: %w", err)
@@ -296,11 +579,50 @@ func generateCommitMessage(cmd *cobra.Command, args []string) error {
 				continue
 			}
 			finalMessage = editedMessage
-		case "4":
+		case choice == "4":
 			ui.Info("Aborted. No commit was made.")
 			return nil // defer will handle cleanup
+		case choice == "5" && len(session) > 1:
+			ui.ShowCandidatePrompt(len(session))
+			var number int
+			if _, err := fmt.Fscanln(menuInput, &number); err != nil || number < 1 || number > len(session) {
+				ui.Warning(fmt.Sprintf("Invalid candidate. Please enter a number from 1 to %d.", len(session)))
+				continue
+			}
+			current = number - 1
+			continue
+		case choice == "r":
+			ui.ShowRefinePrompt()
+			instruction, err := readLine()
+			if err != nil || instruction == "" {
+				ui.Info("No instruction given. Returning to options.")
+				continue
+			}
+			origin := origins[message]
+			if origin == nil {
+				origin = request
+			}
+			refinement = llm.Refine(origin, message.Format(), instruction)
+			refinement.Intent = request.Intent
+			undo = append(undo, current)
+			current = len(session) // generate the refined message
+			continue
+		case choice == "h":
+			ui.ShowIntentPrompt(request.Intent)
+			intent, err := readLine()
+			if err != nil {
+				ui.Info("No changes made. Returning to options.")
+				continue
+			}
+			request.Intent = intent
+			current = len(session) // re-generate with the new intent
+			continue
+		case choice == "u" && len(undo) > 0:
+			current = undo[len(undo)-1]
+			undo = undo[:len(undo)-1]
+			continue
 		default:
-			ui.Warning("Invalid choice. Please enter 1, 2, 3, or 4.")
+			ui.Warning(invalidChoiceMessage(len(session), len(undo) > 0))
 			continue
 		}
 		break
@@ -316,6 +638,291 @@ func generateCommitMessage(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// newGenerationClient creates the client for the selected model. When fallback models or the
+// heuristic fallback are configured, they are tried in order after the selected model fails.
+// The models in the chain are added to tracker for pricing, and paid fallback models are
+// skipped once the budget is used up with the "block" action.
+func newGenerationClient(cfg *config.Config, selectedModel *models.ModelInfo, preview *ui.StreamPreview, tracker *usageTracker) (llm.LLMClient, error) {
+	var chain []llm.NamedClient
+
+	primary, primaryErr := llm.NewLLMClient(cfg)
+	if primaryErr == nil {
+		tracker.addModel(selectedModel, primary)
+		chain = append(chain, llm.NamedClient{Name: modelLabel(selectedModel), Client: primary})
+	} else if len(cfg.FallbackModels) == 0 && !cfg.HeuristicFallback {
+		return nil, fmt.Errorf("failed to initialize LLM client: %w", primaryErr)
+	} else {
+		ui.Warning(fmt.Sprintf("Failed to initialize %s: %v", modelLabel(selectedModel), primaryErr))
+	}
+
+	hasHeuristic := cfg.Provider == config.ProviderHeuristic
+	for _, entry := range cfg.FallbackModels {
+		model, err := cfg.FindModel(entry)
+		if err != nil {
+			ui.Warning(fmt.Sprintf("Skipping fallback model %s: %v", entry, err))
+			continue
+		}
+		if model.ID == selectedModel.ID && model.Provider == selectedModel.Provider {
+			continue
+		}
+
+		// Fallbacks never prompt for keys, providers without one are skipped
+		client, err := llm.NewModelClient(cfg, model)
+		if err != nil {
+			if verboseFlag {
+				ui.Warning(fmt.Sprintf("Skipping fallback model %s: %v", entry, err))
+			}
+			continue
+		}
+		chain = append(chain, llm.NamedClient{
+			Name:   modelLabel(model),
+			Client: client,
+			Check:  func() error { return checkBudgetQuietly(cfg, tracker.ledger, model) },
+		})
+		tracker.addModel(model, client)
+		hasHeuristic = hasHeuristic || model.Provider == config.ProviderHeuristic
+	}
+
+	if cfg.HeuristicFallback && !hasHeuristic {
+		chain = append(chain, llm.NamedClient{Name: llm.GetProviderDisplayName(config.ProviderHeuristic), Client: llm.NewHeuristicClient()})
+	}
+
+	switch len(chain) {
+	case 0:
+		return nil, fmt.Errorf("failed to initialize LLM client: %w", primaryErr)
+	case 1:
+		return chain[0].Client, nil
+	}
+
+	if verboseFlag {
+		names := make([]string, len(chain))
+		for i, entry := range chain {
+			names[i] = entry.Name
+		}
+		ui.Info(fmt.Sprintf("Fallback order: %s", strings.Join(names, " → ")))
+	}
+
+	fallback := llm.NewFallbackClient(chain, func(failed, next string, err error) {
+		preview.Clear()
+		ui.Warning(fmt.Sprintf("%s failed, trying %s: %v", failed, next, err))
+	})
+	// A primary that times out leaves the whole timeout to each fallback model
+	fallback.SetEntryTimeout(cfg.GetTimeout())
+	return fallback, nil
+}
+
+// optionsFromFlags returns the generation options set on the command line
+func optionsFromFlags(cmd *cobra.Command) llm.Options {
+	flags := cmd.Flags()
+	options := llm.Options{
+		MaxTokens:    maxTokensFlag,
+		Stop:         stopFlag,
+		SystemPrompt: systemPromptFlag,
+	}
+	if flags.Changed("temperature") {
+		options.Temperature = &temperatureFlag
+	}
+	if flags.Changed("top-p") {
+		options.TopP = &topPFlag
+	}
+	if flags.Changed("seed") {
+		options.Seed = &seedFlag
+	}
+	return options
+}
+
+// reasoningLog collects the reasoning of the calls of a generation, it is printed once the spinner stops
+type reasoningLog struct {
+	mu      sync.Mutex
+	pending []string
+}
+
+// record stores the reasoning of a call, it is used as the llm reasoning handler
+func (l *reasoningLog) record(reasoning string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending = append(l.pending, reasoning)
+}
+
+// flush returns the reasoning recorded since the last flush
+func (l *reasoningLog) flush() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	pending := l.pending
+	l.pending = nil
+	return pending
+}
+
+// modelLabel names a model and its provider for messages
+func modelLabel(model *models.ModelInfo) string {
+	return fmt.Sprintf("%s (%s)", model.Name, llm.GetProviderDisplayName(model.Provider))
+}
+
+// streamCommitMessage generates a commit message within timeout and renders it in the preview
+// as it arrives. Ctrl-C cancels the request and returns errGenerationCancelled.
+func streamCommitMessage(ctx context.Context, timeout time.Duration, client llm.LLMClient, request *llm.Request, preview *ui.StreamPreview) (string, error) {
+	ctx, cancel := withGenerationTimeout(ctx, timeout, client)
+	defer cancel()
+	streamCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	spinner := ui.NewSpinner("Generating commit message...")
+	spinner.Start()
+	defer spinner.Stop()
+
+	// Show retries of rate limited or unavailable providers in the spinner
+	streamCtx = llm.WithRetryNotifier(streamCtx, func(event llm.RetryEvent) {
+		if event.MaxTokens > 0 {
+			// A truncated answer is generated again from the start
+			preview.Clear()
+			spinner.Start()
+		}
+		spinner.UpdateMessage(event.String())
+	})
+
+	message, err := llm.Stream(streamCtx, client, request, func(chunk string) {
+		spinner.Stop()
+		preview.Write(chunk)
+	})
+	if err != nil && streamCtx.Err() != nil && ctx.Err() == nil {
+		// Only the interrupt cancelled the request, not the deadline
+		return "", errGenerationCancelled
+	}
+	return message, err
+}
+
+// withGenerationTimeout bounds a generation by timeout. Fallback chains get no overall
+// deadline because every model in them already has its own, and Ctrl-C must still reach them.
+func withGenerationTimeout(ctx context.Context, timeout time.Duration, client llm.LLMClient) (context.Context, context.CancelFunc) {
+	if _, chained := client.(*llm.FallbackClient); chained {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// generateCandidates generates n alternative commit messages within timeout behind a spinner.
+// Ctrl-C cancels the requests and returns errGenerationCancelled.
+func generateCandidates(ctx context.Context, timeout time.Duration, client llm.LLMClient, request *llm.Request, n int) ([]string, error) {
+	ctx, cancel := withGenerationTimeout(ctx, timeout, client)
+	defer cancel()
+	generateCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	spinner := ui.NewSpinner(fmt.Sprintf("Generating %d commit messages...", n))
+	spinner.Start()
+	defer spinner.Stop()
+
+	generateCtx = llm.WithRetryNotifier(generateCtx, func(event llm.RetryEvent) {
+		spinner.UpdateMessage(event.String())
+	})
+
+	messages, err := llm.GenerateCandidates(generateCtx, client, request, n)
+	if err != nil && generateCtx.Err() != nil && ctx.Err() == nil {
+		return nil, errGenerationCancelled
+	}
+	return messages, err
+}
+
+// indexOfMessage returns the position of an identical message in the session, or -1
+func indexOfMessage(session []*commit.Message, message *commit.Message) int {
+	formatted := message.Format()
+	for i, candidate := range session {
+		if candidate.Format() == formatted {
+			return i
+		}
+	}
+	return -1
+}
+
+// invalidChoiceMessage lists the menu choices that are currently available
+func invalidChoiceMessage(candidateCount int, canUndo bool) string {
+	choices := "1, 2, 3, 4"
+	if candidateCount > 1 {
+		choices += ", 5"
+	}
+	choices += ", r, h"
+	if canUndo {
+		choices += ", u"
+	}
+	return fmt.Sprintf("Invalid choice. Please enter %s.", choices)
+}
+
+// intentFromFlags returns why the change was made, from --context or --context-file.
+// "--context -" reads it from stdin.
+func intentFromFlags(stdin io.Reader) (string, error) {
+	if contextFlag != "" && contextFileFlag != "" {
+		return "", fmt.Errorf("use either --context or --context-file, not both")
+	}
+
+	intent := contextFlag
+	switch {
+	case contextFileFlag != "":
+		data, err := os.ReadFile(contextFileFlag)
+		if err != nil {
+			return "", fmt.Errorf("failed to read context file: %w", err)
+		}
+		intent = string(data)
+	case contextFlag == "-":
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read context from stdin: %w", err)
+		}
+		intent = string(data)
+	}
+	return strings.TrimSpace(intent), nil
+}
+
+// readLine reads a line of free text from the menu input. It reads byte by byte, so no input
+// is buffered away from the fmt.Fscanln calls of the menus.
+func readLine() (string, error) {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := menuInput.Read(buf)
+		if n > 0 && buf[0] == '\n' {
+			break
+		}
+		if n > 0 {
+			line = append(line, buf[0])
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(line) > 0 {
+				break
+			}
+			return "", err
+		}
+	}
+	return strings.TrimSpace(string(line)), nil
+}
+
+// changedPaths returns the paths of all files in a diff
+func changedPaths(diff string) []string {
+	var paths []string
+	for _, file := range git.ParseDiff(diff) {
+		paths = append(paths, file.Path())
+	}
+	return paths
+}
+
+// touchesGoFiles reports whether a diff changes any Go source file
+func touchesGoFiles(diff string) bool {
+	for _, path := range changedPaths(diff) {
+		if strings.HasSuffix(path, ".go") {
+			return true
+		}
+	}
+	return false
+}
+
+// breakingChangeDescription builds the text of the BREAKING CHANGE footer
+func breakingChangeDescription(changes []apidiff.Change) string {
+	if len(changes) == 1 {
+		return changes[0].String()
+	}
+	return fmt.Sprintf("%d exported identifiers were removed or changed:\n%s",
+		len(changes), apidiff.Summarize(changes, maxBreakingChangesShown))
+}
+
 // openEditor opens the user's preferred editor to edit the commit message
 func openEditor(initialMessage string) (string, error) {
 	// Create a temporary file with .gitcommit extension for syntax highlighting
@@ -348,7 +955,7 @@ func openEditor(initialMessage string) (string, error) {
 
 	// Open the editor
 	cmd := exec.Command(editor, tmpFile.Name())
-	cmd.Stdin = os.Stdin
+	cmd.Stdin = menuInput
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -478,6 +1085,10 @@ func printAllModels() {
 			defaultMarker)
 	}
 
+	printOllamaModels()
+	printEndpoints()
+	printAzureDeployments()
+
 	fmt.Printf("\n%sUsage:%s\n", "\033[1m", "\033[0m")
 	fmt.Printf("  rune --model <short-name>   # Use short name\n")
 	fmt.Printf("  rune --model <full-id>      # Use full model ID\n")
@@ -486,11 +1097,72 @@ func printAllModels() {
 	fmt.Printf("  rune --model d         # DeepSeek (1 char!)\n")
 	fmt.Printf("  rune --model g         # Gemini 2.0\n")
 	fmt.Printf("  rune --model q         # Qwen\n")
+	fmt.Printf("  rune --model ollama/qwen2.5-coder:7b  # Any installed Ollama model\n")
+	fmt.Printf("  rune --model openai/gpt-4o  # Model on a configured endpoint\n")
 	fmt.Printf("  rune --model deep      # DeepSeek (full name)\n")
 	fmt.Printf("  rune --set-default-model m    # Set Mistral as default\n")
 	fmt.Printf("\n* = Default model for provider\n")
 }
 
+// printOllamaModels lists the models installed on the local Ollama server, if it is running
+func printOllamaModels() {
+	cfg, err := config.Load()
+	if err != nil || cfg == nil {
+		cfg = &config.Config{}
+	}
+	host := cfg.GetOllamaHost()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	installed, err := models.FetchOllamaModels(ctx, host)
+	if err != nil {
+		fmt.Printf("\n%sOllama:%s not reachable at %s\n", "\033[1m", "\033[0m", host)
+		return
+	}
+
+	fmt.Printf("\n%sInstalled Ollama Models (%s):%s\n", "\033[1m", host, "\033[0m")
+	if len(installed) == 0 {
+		fmt.Printf("  none - pull one with 'ollama pull llama3.2'\n")
+		return
+	}
+	for _, model := range installed {
+		fmt.Printf("%-40s %-15s %s\n", models.OllamaPrefix+model.ID, model.Company, model.Description)
+	}
+}
+
+// printEndpoints lists the OpenAI-compatible endpoints from the config
+func printEndpoints() {
+	cfg, err := config.Load()
+	if err != nil || cfg == nil || len(cfg.Endpoints) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%sOpenAI-compatible Endpoints:%s\n", "\033[1m", "\033[0m")
+	for _, name := range cfg.EndpointNames() {
+		endpoint := cfg.Endpoints[name]
+		fmt.Printf("%-40s %s\n", name+"/"+endpoint.Model, endpoint.BaseURL)
+	}
+}
+
+// printAzureDeployments lists the Azure OpenAI deployments from the config
+func printAzureDeployments() {
+	cfg, err := config.Load()
+	if err != nil || cfg == nil || cfg.Azure == nil || len(cfg.Azure.Deployments) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%sAzure OpenAI Deployments (%s):%s\n", "\033[1m", cfg.Azure.GetEndpoint(), "\033[0m")
+	names := make([]string, 0, len(cfg.Azure.Deployments))
+	for name := range cfg.Azure.Deployments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%-40s %s\n", config.AzurePrefix+name, cfg.Azure.Deployments[name])
+	}
+}
+
 // handleSetDefaultModel handles the --set-default-model flag
 func handleSetDefaultModel(modelInput string) error {
 	cfg, err := config.Load()
@@ -503,7 +1175,7 @@ func handleSetDefaultModel(modelInput string) error {
 		return nil
 	}
 
-	model, err := models.FindModel(modelInput)
+	model, err := cfg.FindModel(modelInput)
 	if err != nil {
 		return fmt.Errorf("model not found: %w", err)
 	}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIntentFromFlags(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "why.txt")
+	if err := os.WriteFile(file, []byte("\nSupport asked for shorter timeouts\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write context file: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		context     string
+		contextFile string
+		stdin       string
+		want        string
+		wantError   string
+	}{
+		{name: "no hint"},
+		{name: "inline", context: "  why: customer reported timeouts ", want: "why: customer reported timeouts"},
+		{name: "stdin", context: "-", stdin: "from a pipe\n", want: "from a pipe"},
+		{name: "file", contextFile: file, want: "Support asked for shorter timeouts"},
+		{name: "missing file", contextFile: filepath.Join(t.TempDir(), "missing.txt"), wantError: "failed to read context file"},
+		{name: "both flags", context: "inline", contextFile: file, wantError: "either --context or --context-file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contextFlag, contextFileFlag = tt.context, tt.contextFile
+			defer func() { contextFlag, contextFileFlag = "", "" }()
+
+			got, err := intentFromFlags(strings.NewReader(tt.stdin))
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("Expected error containing '%s', got %v", tt.wantError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected intent %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
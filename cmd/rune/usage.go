@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/siddhartha/rune/internal/config"
+	"github.com/siddhartha/rune/internal/llm"
+	"github.com/siddhartha/rune/internal/models"
+	"github.com/siddhartha/rune/internal/ui"
+	"github.com/siddhartha/rune/internal/usage"
+)
+
+var (
+	// Usage command flags
+	usageMonthlyFlag bool
+	usageDaysFlag    int
+)
+
+// usageCmd shows the token usage and estimated cost recorded in the usage ledger
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show token usage and estimated cost per model",
+	Args:  cobra.NoArgs,
+	RunE:  showUsage,
+}
+
+func init() {
+	usageCmd.Flags().BoolVar(&usageMonthlyFlag, "monthly", false, "Show monthly instead of daily totals")
+	usageCmd.Flags().IntVar(&usageDaysFlag, "days", 30, "Number of days shown in the daily totals")
+}
+
+// openLedger returns the usage ledger in the config directory
+func openLedger() (*usage.Ledger, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return usage.NewLedger(filepath.Join(dir, usage.LedgerFile)), nil
+}
+
+// showUsage prints daily or monthly totals per model
+func showUsage(cmd *cobra.Command, args []string) error {
+	ledger, err := openLedger()
+	if err != nil {
+		return err
+	}
+	entries, err := ledger.Entries()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	layout, since, title := usage.Daily, now.AddDate(0, 0, 1-usageDaysFlag), fmt.Sprintf("Usage per day (last %d days)", usageDaysFlag)
+	if usageMonthlyFlag {
+		layout, since, title = usage.Monthly, time.Time{}, "Usage per month"
+	}
+	since = time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, now.Location())
+
+	totals := usage.Summarize(entries, layout, since)
+	if len(totals) == 0 {
+		ui.Info("No usage recorded yet")
+	} else {
+		fmt.Printf("\n%s%s%s\n", ui.ColorBold, title, ui.ColorReset)
+		period := ""
+		unpriced := false
+		for _, total := range totals {
+			if total.Period != period {
+				period = total.Period
+				fmt.Printf("\n%s%s%s\n", ui.ColorCyan, period, ui.ColorReset)
+			}
+			cost := formatCost(total.Cost)
+			if total.Unpriced > 0 {
+				cost += "*"
+				unpriced = true
+			}
+			fmt.Printf("  %-40s %5d calls %10d in %8d out %12s\n",
+				total.Model+" ("+total.Provider+")", total.Calls, total.PromptTokens, total.CompletionTokens, cost)
+		}
+		if unpriced {
+			fmt.Printf("\n%s* includes calls to models without a known price%s\n", ui.ColorDim, ui.ColorReset)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	spent := usage.MonthCost(entries, now)
+	if cfg != nil && cfg.MonthlyBudget > 0 {
+		fmt.Printf("\nThis month: %s of the %s budget\n", formatCost(spent), formatCost(cfg.MonthlyBudget))
+	} else {
+		fmt.Printf("\nThis month: %s\n", formatCost(spent))
+	}
+	return nil
+}
+
+// checkBudget compares this month's estimated cost with the configured budget. Once it is used
+// up, it warns, or with the "block" action refuses models that are not known to be free.
+func checkBudget(cfg *config.Config, ledger *usage.Ledger, model *models.ModelInfo) error {
+	spent, exceeded, err := budgetSpent(cfg, ledger)
+	if err != nil || !exceeded {
+		return err
+	}
+	if blocksModel(cfg, model) {
+		return budgetExceededError(cfg, spent)
+	}
+	ui.Warning(fmt.Sprintf("Monthly budget of %s exceeded (%s spent this month)", formatCost(cfg.MonthlyBudget), formatCost(spent)))
+	return nil
+}
+
+// checkBudgetQuietly returns the error of checkBudget without warning, for fallback models
+func checkBudgetQuietly(cfg *config.Config, ledger *usage.Ledger, model *models.ModelInfo) error {
+	if !blocksModel(cfg, model) {
+		return nil
+	}
+	spent, exceeded, err := budgetSpent(cfg, ledger)
+	if err != nil || !exceeded {
+		return err
+	}
+	return budgetExceededError(cfg, spent)
+}
+
+// budgetSpent returns this month's estimated cost and whether it used up the budget
+func budgetSpent(cfg *config.Config, ledger *usage.Ledger) (float64, bool, error) {
+	if cfg.MonthlyBudget <= 0 {
+		return 0, false, nil
+	}
+	entries, err := ledger.Entries()
+	if err != nil {
+		return 0, false, err
+	}
+	spent := usage.MonthCost(entries, time.Now())
+	return spent, spent >= cfg.MonthlyBudget, nil
+}
+
+// blocksModel reports whether the "block" action refuses a model once the budget is used up
+func blocksModel(cfg *config.Config, model *models.ModelInfo) bool {
+	free := model.Pricing != nil && model.Pricing.Input == 0 && model.Pricing.Output == 0
+	return cfg.BudgetAction == config.BudgetActionBlock && !free
+}
+
+// budgetExceededError explains why a model was refused
+func budgetExceededError(cfg *config.Config, spent float64) error {
+	return fmt.Errorf("monthly budget of %s exceeded (%s spent this month); use a free or local model, or raise monthly_budget",
+		formatCost(cfg.MonthlyBudget), formatCost(spent))
+}
+
+// usageTracker prices the provider calls of a run with the models in use
+type usageTracker struct {
+	ledger  *usage.Ledger
+	mu      sync.Mutex
+	models  map[usageKey]*models.ModelInfo
+	pending []usage.Entry
+}
+
+// usageKey identifies a model in the usage reported by its client, models of different
+// providers may share an ID
+type usageKey struct {
+	provider string // provider name reported by the client, see llm.UsageProvider
+	model    string // model ID sent to the provider
+}
+
+// newUsageTracker creates a tracker without known models that records calls in ledger
+func newUsageTracker(ledger *usage.Ledger) *usageTracker {
+	return &usageTracker{ledger: ledger, models: make(map[usageKey]*models.ModelInfo)}
+}
+
+// addModel makes the pricing of a model available for the calls of its client
+func (t *usageTracker) addModel(model *models.ModelInfo, client llm.LLMClient) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.models[usageKey{provider: llm.UsageProvider(client), model: model.ID}] = model
+}
+
+// record stores the usage of a call, it is used as the llm usage recorder
+func (t *usageTracker) record(u llm.Usage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry := usage.Entry{
+		Time:             time.Now(),
+		Provider:         u.Provider,
+		Model:            u.Model,
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		LatencyMs:        u.Latency.Milliseconds(),
+	}
+	if model, ok := t.models[usageKey{provider: u.Provider, model: u.Model}]; ok && model.Pricing != nil {
+		entry.Cost = model.Pricing.Cost(u.PromptTokens, u.CompletionTokens)
+		entry.Priced = true
+	}
+	t.pending = append(t.pending, entry)
+}
+
+// flush writes the calls recorded since the last flush to the ledger and returns them
+func (t *usageTracker) flush() []usage.Entry {
+	t.mu.Lock()
+	entries := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+
+	if err := t.ledger.Append(entries...); err != nil && verboseFlag {
+		ui.Warning(fmt.Sprintf("Failed to record usage: %v", err))
+	}
+	return entries
+}
+
+// describeUsage summarizes a call for verbose output
+func describeUsage(entry usage.Entry) string {
+	cost := "unknown cost"
+	if entry.Priced {
+		cost = "~" + formatCost(entry.Cost)
+	}
+	return fmt.Sprintf("%s: %d prompt + %d completion tokens in %.1fs, %s",
+		entry.Model, entry.PromptTokens, entry.CompletionTokens, float64(entry.LatencyMs)/1000, cost)
+}
+
+// formatCost formats US dollars, with more precision for the small amounts of single calls
+func formatCost(cost float64) string {
+	if cost > 0 && cost < 0.01 {
+		return fmt.Sprintf("$%.4f", cost)
+	}
+	return fmt.Sprintf("$%.2f", cost)
+}
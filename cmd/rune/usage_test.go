@@ -0,0 +1,74 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/siddhartha/rune/internal/config"
+	"github.com/siddhartha/rune/internal/llm"
+	"github.com/siddhartha/rune/internal/models"
+	"github.com/siddhartha/rune/internal/usage"
+)
+
+func TestUsageTracker_PricesByProvider(t *testing.T) {
+	ledger := usage.NewLedger(filepath.Join(t.TempDir(), usage.LedgerFile))
+	tracker := newUsageTracker(ledger)
+
+	novita := &models.ModelInfo{ID: "deepseek/deepseek-r1", Provider: config.ProviderNovita, Pricing: &models.Pricing{Input: 1, Output: 1}}
+	openRouter := &models.ModelInfo{ID: "deepseek/deepseek-r1", Provider: config.ProviderOpenRouter, Pricing: &models.Pricing{Input: 2, Output: 2}}
+	tracker.addModel(novita, llm.NewQwenClientWithConfig("key", "", novita.ID))
+	t.Setenv("OPENROUTER_API_KEY", "key")
+	openRouterClient, err := llm.NewOpenRouterClient(openRouter.ID)
+	if err != nil {
+		t.Fatalf("Failed to create OpenRouter client: %v", err)
+	}
+	tracker.addModel(openRouter, openRouterClient)
+
+	tracker.record(llm.Usage{Provider: "Novita", Model: "deepseek/deepseek-r1", PromptTokens: 1_000_000})
+	tracker.record(llm.Usage{Provider: "OpenRouter", Model: "deepseek/deepseek-r1", PromptTokens: 1_000_000})
+	tracker.record(llm.Usage{Provider: "Ollama", Model: "deepseek/deepseek-r1", PromptTokens: 1_000_000})
+
+	entries := tracker.flush()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Cost != 1 || entries[1].Cost != 2 {
+		t.Errorf("Expected each provider's price, got %v and %v", entries[0].Cost, entries[1].Cost)
+	}
+	if entries[2].Priced {
+		t.Errorf("Expected calls of an unknown provider not to be priced, got %+v", entries[2])
+	}
+}
+
+func TestCheckBudgetQuietly(t *testing.T) {
+	ledger := usage.NewLedger(filepath.Join(t.TempDir(), usage.LedgerFile))
+	if err := ledger.Append(usage.Entry{Time: time.Now(), Provider: "Anthropic", Model: "m", Cost: 12, Priced: true}); err != nil {
+		t.Fatalf("Failed to write ledger: %v", err)
+	}
+
+	paid := &models.ModelInfo{ID: "paid", Pricing: &models.Pricing{Input: 1, Output: 1}}
+	free := &models.ModelInfo{ID: "free", Pricing: &models.Pricing{}}
+
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		model   *models.ModelInfo
+		blocked bool
+	}{
+		{name: "paid model over budget", cfg: &config.Config{MonthlyBudget: 10, BudgetAction: config.BudgetActionBlock}, model: paid, blocked: true},
+		{name: "free model over budget", cfg: &config.Config{MonthlyBudget: 10, BudgetAction: config.BudgetActionBlock}, model: free},
+		{name: "paid model within budget", cfg: &config.Config{MonthlyBudget: 20, BudgetAction: config.BudgetActionBlock}, model: paid},
+		{name: "warn action", cfg: &config.Config{MonthlyBudget: 10, BudgetAction: config.BudgetActionWarn}, model: paid},
+		{name: "no budget", cfg: &config.Config{BudgetAction: config.BudgetActionBlock}, model: paid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkBudgetQuietly(tt.cfg, ledger, tt.model)
+			if (err != nil) != tt.blocked {
+				t.Errorf("Expected blocked %v, got %v", tt.blocked, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package apidiff
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ChangeKind describes how an exported identifier changed
+type ChangeKind string
+
+const (
+	// Removed means the identifier no longer exists
+	Removed ChangeKind = "removed"
+	// Changed means the identifier exists but its type or signature differs
+	Changed ChangeKind = "changed"
+)
+
+// Change represents a single incompatible change to a package API
+type Change struct {
+	Package string     // Directory of the package (e.g., "internal/llm")
+	Name    string     // Identifier, qualified with its type for methods and fields (e.g., "Client.Do")
+	Kind    ChangeKind // How the identifier changed
+	Old     string     // Old declaration
+	New     string     // New declaration, empty when removed
+}
+
+// String returns a one-line description of the change
+func (c Change) String() string {
+	if c.Kind == Removed {
+		return fmt.Sprintf("%s: removed %s", c.Package, c.Old)
+	}
+	return fmt.Sprintf("%s: changed %s to %s", c.Package, c.Old, c.New)
+}
+
+// Checker type-checks old and new versions of packages and compares their exported APIs.
+// Imports are resolved from source so both versions see the same dependencies.
+type Checker struct {
+	ctx      context.Context
+	fset     *token.FileSet
+	importer *contextImporter
+}
+
+// NewChecker creates a new Checker
+func NewChecker() *Checker {
+	return NewCheckerContext(context.Background())
+}
+
+// NewCheckerContext creates a Checker that stops once ctx is done. Resolving imports from
+// source type-checks the whole dependency graph, which can take a while in large modules.
+func NewCheckerContext(ctx context.Context) *Checker {
+	fset := token.NewFileSet()
+	return &Checker{
+		ctx:      ctx,
+		fset:     fset,
+		importer: &contextImporter{ctx: ctx, importer: importer.ForCompiler(fset, "source", nil).(types.ImporterFrom)},
+	}
+}
+
+// contextImporter fails every import once its context is done, so that type checking ends early
+type contextImporter struct {
+	ctx      context.Context
+	importer types.ImporterFrom
+}
+
+// Import implements types.Importer
+func (i *contextImporter) Import(path string) (*types.Package, error) {
+	return i.ImportFrom(path, "", 0)
+}
+
+// ImportFrom implements types.ImporterFrom
+func (i *contextImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if err := i.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return i.importer.ImportFrom(path, dir, mode)
+}
+
+// Compare returns the breaking changes between two versions of a package.
+// Files are keyed by their path relative to the repository root, which is
+// also used to resolve imports. A nil or empty old version means the package
+// is new and cannot break anything.
+func (c *Checker) Compare(dir string, oldFiles, newFiles map[string][]byte) ([]Change, error) {
+	if len(oldFiles) == 0 {
+		return nil, nil
+	}
+
+	oldPkg, err := c.check(dir, oldFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check old version of %s: %w", dir, err)
+	}
+	if oldPkg == nil || oldPkg.Name() == "main" {
+		return nil, nil
+	}
+
+	if len(newFiles) == 0 {
+		return []Change{{Package: dir, Name: oldPkg.Name(), Kind: Removed, Old: "package " + oldPkg.Name()}}, nil
+	}
+
+	newPkg, err := c.check(dir, newFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check new version of %s: %w", dir, err)
+	}
+	if newPkg == nil {
+		return []Change{{Package: dir, Name: oldPkg.Name(), Kind: Removed, Old: "package " + oldPkg.Name()}}, nil
+	}
+
+	return compareAPIs(dir, describeAPI(oldPkg), describeAPI(newPkg)), nil
+}
+
+// check parses and type-checks the non-test files of a package.
+// Type errors are tolerated so that a partially broken tree still yields an API.
+func (c *Checker) check(dir string, files map[string][]byte) (*types.Package, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parsed []*ast.File
+	for _, name := range names {
+		if !isPackageFile(name) {
+			continue
+		}
+		file, err := parser.ParseFile(c.fset, name, files[name], parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, file)
+	}
+	if len(parsed) == 0 {
+		return nil, nil
+	}
+
+	conf := types.Config{
+		Importer: c.importer,
+		Error:    func(error) {}, // keep going, unresolved types compare as "invalid type"
+	}
+	pkg, _ := conf.Check(dir, c.fset, parsed, nil)
+	if err := c.ctx.Err(); err != nil {
+		// Imports were left unresolved, the API would differ for the wrong reasons
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// isPackageFile reports whether a file contributes to the package API
+func isPackageFile(name string) bool {
+	return strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")
+}
+
+// describeAPI renders every exported identifier of a package, keyed by its qualified name
+func describeAPI(pkg *types.Package) map[string]string {
+	api := make(map[string]string)
+	qualifier := types.RelativeTo(pkg)
+	scope := pkg.Scope()
+
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+
+		typeName, ok := obj.(*types.TypeName)
+		if !ok {
+			api[name] = types.ObjectString(obj, qualifier)
+			continue
+		}
+
+		api[name] = "type " + name + " " + describeKind(typeName.Type().Underlying(), qualifier)
+
+		// Exported fields of structs
+		if st, ok := typeName.Type().Underlying().(*types.Struct); ok {
+			for i := 0; i < st.NumFields(); i++ {
+				field := st.Field(i)
+				if field.Exported() {
+					api[name+"."+field.Name()] = "field " + name + "." + field.Name() + " " + types.TypeString(field.Type(), qualifier)
+				}
+			}
+		}
+
+		// Interface methods; adding one breaks implementations just like removing one breaks callers
+		if iface, ok := typeName.Type().Underlying().(*types.Interface); ok {
+			var methods []string
+			for i := 0; i < iface.NumMethods(); i++ {
+				method := iface.Method(i)
+				methods = append(methods, method.Name()+strings.TrimPrefix(types.TypeString(method.Type(), qualifier), "func"))
+			}
+			api[name] = "type " + name + " interface{ " + strings.Join(methods, "; ") + " }"
+		}
+
+		// Methods declared on the named type and its pointer
+		if named, ok := typeName.Type().(*types.Named); ok {
+			for i := 0; i < named.NumMethods(); i++ {
+				method := named.Method(i)
+				if method.Exported() {
+					api[name+"."+method.Name()] = types.ObjectString(method, qualifier)
+				}
+			}
+		}
+	}
+
+	return api
+}
+
+// describeKind summarises an underlying type without listing struct fields,
+// which are compared individually so that adding a field is not breaking
+func describeKind(t types.Type, qualifier types.Qualifier) string {
+	switch t.(type) {
+	case *types.Struct:
+		return "struct"
+	case *types.Interface:
+		return "interface"
+	default:
+		return types.TypeString(t, qualifier)
+	}
+}
+
+// compareAPIs lists identifiers that were removed or whose declaration changed
+func compareAPIs(dir string, oldAPI, newAPI map[string]string) []Change {
+	var changes []Change
+	for name, oldDecl := range oldAPI {
+		newDecl, ok := newAPI[name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Package: dir, Name: name, Kind: Removed, Old: oldDecl})
+		case newDecl != oldDecl:
+			changes = append(changes, Change{Package: dir, Name: name, Kind: Changed, Old: oldDecl, New: newDecl})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// packageDirs returns the directories of changed Go files that can form a public API.
+// Test files, testdata and internal packages are skipped.
+func packageDirs(files []string) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+
+	for _, file := range files {
+		if !isPackageFile(file) {
+			continue
+		}
+		dir := path.Dir(file)
+		if seen[dir] || isPrivateDir(dir) {
+			continue
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+
+	sort.Strings(dirs)
+	return dirs
+}
+
+// isPrivateDir reports whether packages in dir cannot be imported by other modules
+func isPrivateDir(dir string) bool {
+	for _, elem := range strings.Split(dir, "/") {
+		if elem == "internal" || elem == "testdata" || strings.HasPrefix(elem, "_") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+package apidiff
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChecker_Compare(t *testing.T) {
+	const base = `package lib
+
+import "io"
+
+type Client struct {
+	Name string
+	Out  io.Writer
+	seen int
+}
+
+func (c *Client) Do(path string) error { return nil }
+
+type Store interface {
+	Get(key string) (string, error)
+}
+
+const Version = "1.0"
+
+func New(name string) *Client { return &Client{Name: name} }
+
+func helper() {}
+`
+
+	tests := []struct {
+		name        string
+		newSrc      string
+		wantChanges []string
+	}{
+		{
+			name:   "identical",
+			newSrc: base,
+		},
+		{
+			name:   "unexported changes only",
+			newSrc: strings.Replace(strings.Replace(base, "func helper() {}", "func helper(n int) {}", 1), "seen int", "seen string", 1),
+		},
+		{
+			name:   "added function and field",
+			newSrc: strings.Replace(base, "seen int", "seen int\n\tRetries int", 1) + "\nfunc Extra() {}\n",
+		},
+		{
+			name:        "removed function",
+			newSrc:      strings.Replace(base, "func New(name string) *Client { return &Client{Name: name} }", "", 1),
+			wantChanges: []string{"removed func New(name string) *Client"},
+		},
+		{
+			name:        "changed signature",
+			newSrc:      strings.Replace(base, "func New(name string) *Client", "func New(name string, retries int) *Client", 1),
+			wantChanges: []string{"changed func New(name string) *Client to func New(name string, retries int) *Client"},
+		},
+		{
+			name:        "changed method signature",
+			newSrc:      strings.Replace(base, "Do(path string) error", "Do(path string) (int, error)", 1),
+			wantChanges: []string{"changed func (*Client).Do(path string) error to func (*Client).Do(path string) (int, error)"},
+		},
+		{
+			name:        "changed imported field type",
+			newSrc:      strings.Replace(base, "Out  io.Writer", "Out  io.Reader", 1),
+			wantChanges: []string{"changed field Client.Out io.Writer to field Client.Out io.Reader"},
+		},
+		{
+			name:        "removed struct field",
+			newSrc:      strings.Replace(base, "\tName string\n", "", 1),
+			wantChanges: []string{"removed field Client.Name string"},
+		},
+		{
+			name:        "interface method added",
+			newSrc:      strings.Replace(base, "Get(key string) (string, error)", "Get(key string) (string, error)\n\tDelete(key string) error", 1),
+			wantChanges: []string{"to type Store interface{ Delete(key string) error; Get(key string) (string, error) }"},
+		},
+		{
+			name:        "constant type changed",
+			newSrc:      strings.Replace(base, `const Version = "1.0"`, `const Version = 1`, 1),
+			wantChanges: []string{`changed const Version untyped string to const Version untyped int`},
+		},
+	}
+
+	checker := NewChecker()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := checker.Compare("lib",
+				map[string][]byte{"lib/lib.go": []byte(base)},
+				map[string][]byte{"lib/lib.go": []byte(tt.newSrc)},
+			)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(changes) != len(tt.wantChanges) {
+				t.Fatalf("Expected %d changes, got %d: %v", len(tt.wantChanges), len(changes), changes)
+			}
+			for i, want := range tt.wantChanges {
+				if !strings.Contains(changes[i].String(), want) {
+					t.Errorf("Change %d = %q, want it to contain %q", i, changes[i].String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestChecker_ComparePackageLifecycle(t *testing.T) {
+	checker := NewChecker()
+	src := map[string][]byte{"lib/lib.go": []byte("package lib\n\nfunc Run() {}\n")}
+
+	t.Run("new package", func(t *testing.T) {
+		changes, err := checker.Compare("lib", nil, src)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(changes) != 0 {
+			t.Errorf("Expected no changes for a new package, got %v", changes)
+		}
+	})
+
+	t.Run("deleted package", func(t *testing.T) {
+		changes, err := checker.Compare("lib", src, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(changes) != 1 || changes[0].Kind != Removed {
+			t.Errorf("Expected package removal, got %v", changes)
+		}
+	})
+
+	t.Run("main package is ignored", func(t *testing.T) {
+		main := map[string][]byte{"cmd/main.go": []byte("package main\n\nfunc Run() {}\n\nfunc main() {}\n")}
+		changes, err := checker.Compare("cmd", main, map[string][]byte{"cmd/main.go": []byte("package main\n\nfunc main() {}\n")})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(changes) != 0 {
+			t.Errorf("Expected main package to be ignored, got %v", changes)
+		}
+	})
+
+	t.Run("test files are ignored", func(t *testing.T) {
+		withTest := map[string][]byte{
+			"lib/lib.go":      src["lib/lib.go"],
+			"lib/lib_test.go": []byte("package lib\n\nfunc Helper() {}\n"),
+		}
+		changes, err := checker.Compare("lib", withTest, src)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(changes) != 0 {
+			t.Errorf("Expected test-only helpers to be ignored, got %v", changes)
+		}
+	})
+}
+
+func TestPackageDirs(t *testing.T) {
+	files := []string{
+		"main.go",
+		"lib/lib.go",
+		"lib/lib_test.go",
+		"lib/other.go",
+		"internal/llm/client.go",
+		"pkg/internal/x.go",
+		"testdata/fixture.go",
+		"README.md",
+	}
+
+	got := packageDirs(files)
+	want := []string{".", "lib"}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("packageDirs() = %v, want %v", got, want)
+	}
+}
+
+func TestDetectBreakingChanges(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalDir, _ := os.Getwd()
+	defer func() {
+		if err := os.Chdir(originalDir); err != nil {
+			t.Logf("Failed to restore working dir: %v", err)
+		}
+	}()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	run := func(args ...string) {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatalf("Failed to create dir for %s: %v", name, err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	run("init")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Test User")
+
+	write("go.mod", "module example.com/lib\n\ngo 1.21\n")
+	write("lib/lib.go", "package lib\n\nfunc Run(name string) error { return nil }\n")
+	write("internal/x/x.go", "package x\n\nfunc Hidden() {}\n")
+	run("add", ".")
+	run("commit", "-m", "Initial commit")
+
+	write("lib/lib.go", "package lib\n\nfunc Run(name string, force bool) error { return nil }\n")
+	write("internal/x/x.go", "package x\n")
+	run("add", ".")
+
+	changes, err := DetectBreakingChanges(context.Background(), true)
+	if err != nil {
+		t.Fatalf("DetectBreakingChanges() returned error: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 change, got %v", changes)
+	}
+	if changes[0].Package != "lib" || changes[0].Name != "Run" || changes[0].Kind != Changed {
+		t.Errorf("Unexpected change: %+v", changes[0])
+	}
+}
+
+func TestChecker_CompareCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	files := map[string][]byte{"lib/lib.go": []byte("package lib\n\nimport \"io\"\n\nfunc Run(w io.Writer) {}\n")}
+	_, err := NewCheckerContext(ctx).Compare("lib", files, files)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the context error, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package apidiff
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/siddhartha/rune/internal/git"
+)
+
+// DetectBreakingChanges compares the exported API of every Go package touched by
+// the pending changes against HEAD. If staged is true the new version is read from
+// the index, otherwise from the working tree, matching git.ExtractDiff. It stops
+// with the error of ctx once ctx is done.
+func DetectBreakingChanges(ctx context.Context, staged bool) ([]Change, error) {
+	files, err := git.ChangedFiles(staged)
+	if err != nil {
+		return nil, err
+	}
+	dirs := packageDirs(files)
+	if len(dirs) == 0 {
+		return nil, nil // no Go packages changed
+	}
+
+	checker := NewCheckerContext(ctx)
+	var changes []Change
+
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		oldFiles, err := readPackage("HEAD", dir, func(file string) ([]byte, error) {
+			return git.ShowFile("HEAD", file)
+		})
+		if err != nil {
+			// No HEAD yet (initial commit) or the package is new
+			continue
+		}
+
+		readNew := func(file string) ([]byte, error) {
+			return git.ShowFile("", file)
+		}
+		if !staged {
+			readNew = os.ReadFile
+		}
+		newFiles, err := readPackage("", dir, readNew)
+		if err != nil {
+			return nil, err
+		}
+
+		dirChanges, err := checker.Compare(dir, oldFiles, newFiles)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, dirChanges...)
+	}
+
+	return changes, nil
+}
+
+// readPackage loads the Go files of a package directory at a revision
+func readPackage(rev, dir string, read func(string) ([]byte, error)) (map[string][]byte, error) {
+	names, err := git.ListFilesAt(rev, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	for _, name := range names {
+		if !isPackageFile(name) {
+			continue
+		}
+		content, err := read(name)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // deleted in the working tree but still in the index
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		files[name] = content
+	}
+	return files, nil
+}
+
+// Summarize returns a short human-readable description of the changes,
+// listing at most limit entries
+func Summarize(changes []Change, limit int) string {
+	var lines []string
+	for i, change := range changes {
+		if i == limit {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(changes)-limit))
+			break
+		}
+		lines = append(lines, change.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,155 @@
+package aws
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultProfile is the shared config profile used when none is selected
+const DefaultProfile = "default"
+
+// LoadCredentials finds AWS credentials the way the AWS CLI does.
+// Without an explicit profile the AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY environment
+// variables win; otherwise the profile (or AWS_PROFILE, or "default") is read from
+// the shared credentials file and then the shared config file.
+func LoadCredentials(profile string) (Credentials, error) {
+	if profile == "" {
+		creds := Credentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if creds.AccessKeyID != "" && creds.SecretAccessKey != "" {
+			return creds, nil
+		}
+	}
+
+	profile = resolveProfile(profile)
+	for _, source := range []struct {
+		path    string
+		section string
+	}{
+		{credentialsFilePath(), profile},
+		{configFilePath(), configSection(profile)},
+	} {
+		values, err := readProfile(source.path, source.section)
+		if err != nil {
+			return Credentials{}, err
+		}
+
+		creds := Credentials{
+			AccessKeyID:     values["aws_access_key_id"],
+			SecretAccessKey: values["aws_secret_access_key"],
+			SessionToken:    values["aws_session_token"],
+		}
+		if creds.AccessKeyID != "" && creds.SecretAccessKey != "" {
+			return creds, nil
+		}
+	}
+
+	return Credentials{}, fmt.Errorf("failed to load AWS credentials: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY or configure profile %q in %s", profile, credentialsFilePath())
+}
+
+// LoadRegion returns the region from AWS_REGION, AWS_DEFAULT_REGION or the
+// profile in the shared config file. It returns "" when none is set.
+func LoadRegion(profile string) string {
+	for _, name := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := os.Getenv(name); region != "" {
+			return region
+		}
+	}
+
+	values, err := readProfile(configFilePath(), configSection(resolveProfile(profile)))
+	if err != nil {
+		return ""
+	}
+	return values["region"]
+}
+
+// resolveProfile applies the AWS_PROFILE and default fallbacks
+func resolveProfile(profile string) string {
+	if profile != "" {
+		return profile
+	}
+	if env := os.Getenv("AWS_PROFILE"); env != "" {
+		return env
+	}
+	return DefaultProfile
+}
+
+// configSection returns the section name of a profile in the shared config file,
+// where every profile but the default is written as "[profile name]"
+func configSection(profile string) string {
+	if profile == DefaultProfile {
+		return profile
+	}
+	return "profile " + profile
+}
+
+func credentialsFilePath() string {
+	if path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); path != "" {
+		return path
+	}
+	return filepath.Join(awsDir(), "credentials")
+}
+
+func configFilePath() string {
+	if path := os.Getenv("AWS_CONFIG_FILE"); path != "" {
+		return path
+	}
+	return filepath.Join(awsDir(), "config")
+}
+
+func awsDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ".aws"
+	}
+	return filepath.Join(homeDir, ".aws")
+}
+
+// readProfile returns the key/value pairs of one section of an INI file.
+// A missing file yields no values and no error.
+func readProfile(path, section string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	values := make(map[string]string)
+	inSection := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+			inSection = name == section
+			continue
+		}
+
+		if !inSection {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return values, nil
+}
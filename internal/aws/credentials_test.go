@@ -0,0 +1,124 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupAWSFiles points the shared config files at temp files and clears the AWS environment
+func setupAWSFiles(t *testing.T, credentials, config string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	credentialsPath := filepath.Join(dir, "credentials")
+	configPath := filepath.Join(dir, "config")
+	if err := os.WriteFile(credentialsPath, []byte(credentials), 0600); err != nil {
+		t.Fatalf("Failed to write credentials file: %v", err)
+	}
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsPath)
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION"} {
+		t.Setenv(name, "")
+	}
+}
+
+const testCredentialsFile = `[default]
+aws_access_key_id = AKIDDEFAULT
+aws_secret_access_key = default-secret
+
+# Temporary credentials
+[work]
+aws_access_key_id=AKIDWORK
+aws_secret_access_key=work-secret
+aws_session_token=work-token
+`
+
+const testConfigFile = `[default]
+region = eu-west-1
+
+[profile sso]
+region = us-west-2
+aws_access_key_id = AKIDCONFIG
+aws_secret_access_key = config-secret
+`
+
+func TestLoadCredentials(t *testing.T) {
+	tests := []struct {
+		name      string
+		profile   string
+		env       map[string]string
+		wantKeyID string
+		wantToken string
+		wantErr   bool
+	}{
+		{name: "default profile", wantKeyID: "AKIDDEFAULT"},
+		{name: "explicit profile", profile: "work", wantKeyID: "AKIDWORK", wantToken: "work-token"},
+		{name: "AWS_PROFILE", env: map[string]string{"AWS_PROFILE": "work"}, wantKeyID: "AKIDWORK", wantToken: "work-token"},
+		{name: "profile from config file", profile: "sso", wantKeyID: "AKIDCONFIG"},
+		{
+			name:      "environment variables",
+			env:       map[string]string{"AWS_ACCESS_KEY_ID": "AKIDENV", "AWS_SECRET_ACCESS_KEY": "env-secret", "AWS_SESSION_TOKEN": "env-token"},
+			wantKeyID: "AKIDENV",
+			wantToken: "env-token",
+		},
+		{
+			name:      "explicit profile beats environment",
+			profile:   "work",
+			env:       map[string]string{"AWS_ACCESS_KEY_ID": "AKIDENV", "AWS_SECRET_ACCESS_KEY": "env-secret"},
+			wantKeyID: "AKIDWORK",
+			wantToken: "work-token",
+		},
+		{name: "unknown profile", profile: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupAWSFiles(t, testCredentialsFile, testConfigFile)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			creds, err := LoadCredentials(tt.profile)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", creds)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if creds.AccessKeyID != tt.wantKeyID || creds.SessionToken != tt.wantToken {
+				t.Errorf("LoadCredentials(%q) = %+v", tt.profile, creds)
+			}
+		})
+	}
+}
+
+func TestLoadRegion(t *testing.T) {
+	setupAWSFiles(t, "", testConfigFile)
+
+	if got := LoadRegion(""); got != "eu-west-1" {
+		t.Errorf("Expected region of the default profile, got %q", got)
+	}
+	if got := LoadRegion("sso"); got != "us-west-2" {
+		t.Errorf("Expected region of profile sso, got %q", got)
+	}
+	if got := LoadRegion("work"); got != "" {
+		t.Errorf("Expected no region, got %q", got)
+	}
+
+	t.Setenv("AWS_DEFAULT_REGION", "ap-south-1")
+	if got := LoadRegion("sso"); got != "ap-south-1" {
+		t.Errorf("Expected AWS_DEFAULT_REGION, got %q", got)
+	}
+	t.Setenv("AWS_REGION", "us-east-2")
+	if got := LoadRegion(""); got != "us-east-2" {
+		t.Errorf("Expected AWS_REGION, got %q", got)
+	}
+}
@@ -0,0 +1,110 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Vectors from the AWS Signature Version 4 test suite
+var testCredentials = Credentials{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+}
+
+var testTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+func TestSignRequest_TestSuite(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		wantSignature string
+	}{
+		{
+			name:          "get-vanilla",
+			method:        "GET",
+			url:           "https://example.amazonaws.com/",
+			wantSignature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:          "post-vanilla",
+			method:        "POST",
+			url:           "https://example.amazonaws.com/",
+			wantSignature: "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:          "get-vanilla-query-order-key-case",
+			method:        "GET",
+			url:           "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			wantSignature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+
+			if err := SignRequest(req, nil, testCredentials, "us-east-1", "service", testTime); err != nil {
+				t.Fatalf("SignRequest() returned error: %v", err)
+			}
+
+			want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=" + tt.wantSignature
+			if got := req.Header.Get("Authorization"); got != want {
+				t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %s, want 20150830T123600Z", got)
+			}
+		})
+	}
+}
+
+func TestSigningKey(t *testing.T) {
+	// Example from the AWS documentation on deriving the signing key
+	key := SigningKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	want := "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d"
+	if got := hex.EncodeToString(key); got != want {
+		t.Errorf("SigningKey() = %s, want %s", got, want)
+	}
+}
+
+func TestSignRequest_SessionTokenAndEscapedPath(t *testing.T) {
+	body := []byte(`{"messages":[]}`)
+	req, err := http.NewRequest("POST", "https://bedrock-runtime.us-east-1.amazonaws.com/model/anthropic.claude-3-5-haiku-20241022-v1%3A0/converse", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	creds := testCredentials
+	creds.SessionToken = "session-token"
+	if err := SignRequest(req, body, creds, "us-east-1", "bedrock", testTime); err != nil {
+		t.Fatalf("SignRequest() returned error: %v", err)
+	}
+
+	if req.Header.Get("X-Amz-Security-Token") != "session-token" {
+		t.Error("Expected X-Amz-Security-Token header")
+	}
+	auth := req.Header.Get("Authorization")
+	if !strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Unexpected signed headers: %s", auth)
+	}
+	if got := canonicalURI(req.URL); got != "/model/anthropic.claude-3-5-haiku-20241022-v1%253A0/converse" {
+		t.Errorf("canonicalURI() = %s, want the escaped path encoded twice", got)
+	}
+}
+
+func TestSignRequest_MissingCredentials(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	if err := SignRequest(req, nil, Credentials{AccessKeyID: "AKID"}, "us-east-1", "service", testTime); err == nil {
+		t.Error("Expected error for incomplete credentials")
+	}
+}
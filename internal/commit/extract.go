@@ -0,0 +1,257 @@
+package commit
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxExcerptLength bounds the part of a rejected answer quoted in errors
+const maxExcerptLength = 80
+
+// ErrNotAMessage is returned when a model answered with a refusal or a question instead of a commit message
+var ErrNotAMessage = errors.New("the model did not write a commit message")
+
+var (
+	// fencePattern matches the opening or closing line of a markdown code block, e.g. "```text"
+	fencePattern = regexp.MustCompile("^```[\\w+-]*$")
+	// headerPattern matches the marker of a markdown heading
+	headerPattern = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	// boldPattern matches **bold** text, not the stars of "**kwargs and **opts"
+	boldPattern = regexp.MustCompile(`\*\*([^*\s](?:[^*]*[^*\s])?)\*\*`)
+	// italicPattern matches *italic* text between spaces or punctuation, not "* " bullets
+	italicPattern = regexp.MustCompile(`(^|[\s(])\*([^*\s](?:[^*]*[^*\s])?)\*([\s).,:;!?]|$)`)
+	// separatorPattern matches a markdown horizontal rule
+	separatorPattern = regexp.MustCompile(`^(-{3,}|\*{3,}|_{3,})$`)
+	// trailerPattern matches footer lines such as "BREAKING CHANGE: ...", "Signed-off-by: ..." or "Fixes #12"
+	trailerPattern = regexp.MustCompile(`^(BREAKING[ -]CHANGE|[A-Z][a-z]*(-[a-z]+)+|Refs|Fixes|Closes|Resolves)(: | #)\S`)
+
+	// interjectionPattern matches the start of an answer such as "Sure! " or "Certainly, "
+	interjectionPattern = regexp.MustCompile(`^(sure|certainly|of course|absolutely|okay|ok|great|alright)\b[\s!,.]*`)
+	// subjectLabelPattern matches a label in front of the subject, e.g. "Commit message: Add x"
+	subjectLabelPattern = regexp.MustCompile(`(?i)^(commit message|commit|subject line|subject|title|summary)\s*:\s*(\S.*)$`)
+	// bodyLabelPattern matches a label in front of the body, e.g. "Body:"
+	bodyLabelPattern = regexp.MustCompile(`(?i)^(body|description|details|message body)\s*:\s*(.*)$`)
+
+	// preamblePatterns match lowercased lines that introduce the message
+	preamblePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^here('s| is| are)\b.*:$`),
+		regexp.MustCompile(`^here('s| is| are)\b.*commit message`),
+		regexp.MustCompile(`^(based on|looking at|after (reviewing|analyzing)|given)\b.*:$`),
+		regexp.MustCompile(`^i('d| would) (suggest|recommend|propose)\b.*:$`),
+		regexp.MustCompile(`^((the|a|an|my|your|suggested|proposed|generated|final|git|conventional)\s+)*commit( message)?s?\s*:?$`),
+		regexp.MustCompile(`commit message.*:$`),
+	}
+
+	// postamblePatterns match lowercased first lines of paragraphs that comment on the message.
+	// They are only applied to answers that also had a preamble or code fence.
+	postamblePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^(this|the above) (commit )?message (follows|adheres|uses|keeps|is|captures|summarizes|describes)\b`),
+		regexp.MustCompile(`^this (follows|adheres to|captures)\b`),
+		regexp.MustCompile(`^(let me know|i hope|hope this|feel free|would you like|alternatively)\b`),
+		regexp.MustCompile(`^if you('d| would)? (like|want|need|prefer)\b`),
+		regexp.MustCompile(`^you (can|could|may) (also )?(adjust|modify|change|tweak|shorten)\b`),
+		regexp.MustCompile(`^(explanation|note|notes|rationale|reasoning)\s*:`),
+	}
+
+	// refusalPatterns match lowercased subjects of answers that are not a commit message
+	refusalPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^(i'm|i am) (sorry|unable|not able|afraid)\b`),
+		regexp.MustCompile(`^i (can't|cannot|can not|won't|am unable)\b`),
+		regexp.MustCompile(`^(sorry|unfortunately|apologies)\b`),
+		regexp.MustCompile(`^i apologi[sz]e\b`),
+		regexp.MustCompile(`^as an ai\b`),
+		regexp.MustCompile(`^(there (are|is|were) no|no) (code |staged )?(changes|diff|differences)\b`),
+		regexp.MustCompile(`^(the|this|your) diff (is|appears to be|seems to be) (empty|incomplete|truncated|missing)\b`),
+		regexp.MustCompile(`^(please|could you|can you|would you) (please )?(provide|share|paste|send|include)\b`),
+		regexp.MustCompile(`^it (seems|looks like) (you|the diff|there)\b`),
+		regexp.MustCompile(`^i (don't|do not) see\b`),
+		regexp.MustCompile(`^i need (more|the|to see)\b`),
+	}
+)
+
+// extract returns the commit message in a model response. Models often wrap it in chatter:
+// preambles such as "Here is your commit message:", code fences, quotes, markdown headings
+// and emphasis, and explanations after the message. Refusals and other answers that are not
+// a commit message return ErrNotAMessage.
+func extract(raw string) (string, error) {
+	text, fenced := unfence(strings.ReplaceAll(raw, "\r\n", "\n"))
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = stripMarkdown(line)
+	}
+
+	// Commentary after the message is only expected when the model also introduced it
+	lines, introduced := dropPreamble(lines)
+	lines = dropPostamble(lines, fenced || introduced)
+	message := unquote(cleanMessage(strings.Join(lines, "\n")))
+	if message == "" {
+		return "", fmt.Errorf("%w: only %s", ErrNotAMessage, excerpt(raw))
+	}
+
+	subject, _, _ := strings.Cut(message, "\n")
+	if isRefusal(subject) {
+		return "", fmt.Errorf("%w: %s", ErrNotAMessage, excerpt(message))
+	}
+	return message, nil
+}
+
+// unfence returns the content of the first code block when only a preamble comes before it,
+// so that explanations after the block are dropped. Otherwise the fence lines are removed.
+// fenced reports whether the text had a fence.
+func unfence(text string) (result string, fenced bool) {
+	lines := strings.Split(text, "\n")
+	open, end := -1, -1
+	for i, line := range lines {
+		if fencePattern.MatchString(strings.TrimSpace(line)) {
+			if open < 0 {
+				open = i
+				continue
+			}
+			end = i
+			break
+		}
+	}
+
+	if open < 0 {
+		return text, false
+	}
+	if before, _ := dropPreamble(lines[:open]); end > open && len(before) == 0 {
+		return strings.Join(lines[open+1:end], "\n"), true
+	}
+
+	kept := lines[:0:0]
+	for _, line := range lines {
+		if !fencePattern.MatchString(strings.TrimSpace(line)) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n"), true
+}
+
+// stripMarkdown removes heading markers and bold or italic emphasis from a line
+func stripMarkdown(line string) string {
+	line = headerPattern.ReplaceAllString(line, "")
+	line = boldPattern.ReplaceAllString(line, "$1")
+	return italicPattern.ReplaceAllString(line, "$1$2$3")
+}
+
+// dropPreamble removes the lines that introduce the message and a label in front of the subject.
+// introduced reports whether there was such an introduction.
+func dropPreamble(lines []string) (result []string, introduced bool) {
+	for len(lines) > 0 {
+		line := strings.TrimSpace(lines[0])
+		if line == "" {
+			lines = lines[1:]
+			continue
+		}
+
+		// "Sure! Here is the message:" introduces it, "Sure! Add x" starts it
+		rest := interjectionPattern.ReplaceAllString(normalizeQuotes(strings.ToLower(line)), "")
+		if rest == "" || matchesAny(preamblePatterns, rest) {
+			lines, introduced = lines[1:], true
+			continue
+		}
+		if rest != normalizeQuotes(strings.ToLower(line)) {
+			lines, introduced = append([]string{line[len(line)-len(rest):]}, lines[1:]...), true
+		}
+		break
+	}
+
+	if len(lines) > 0 {
+		if match := subjectLabelPattern.FindStringSubmatch(strings.TrimSpace(lines[0])); match != nil {
+			lines, introduced = append([]string{match[2]}, lines[1:]...), true
+		}
+	}
+	return lines, introduced
+}
+
+// dropPostamble removes the paragraphs after the message that comment on it, from the first
+// such paragraph or horizontal rule on, when chatty. Trailers such as "BREAKING CHANGE:" are
+// always kept. Body labels such as "Body:" are removed as well.
+func dropPostamble(lines []string, chatty bool) []string {
+	kept := make([]string, 0, len(lines))
+	var trailers []string
+	commentary := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if i > 0 && chatty && !commentary {
+			commentary = separatorPattern.MatchString(trimmed) ||
+				strings.TrimSpace(lines[i-1]) == "" && matchesAny(postamblePatterns, normalizeQuotes(strings.ToLower(trimmed)))
+		}
+		if commentary {
+			if trailerPattern.MatchString(trimmed) {
+				trailers = append(trailers, trimmed)
+			}
+			continue
+		}
+		if i > 0 {
+			if match := bodyLabelPattern.FindStringSubmatch(trimmed); match != nil {
+				if match[2] == "" {
+					continue
+				}
+				line = match[2]
+			}
+		}
+		kept = append(kept, line)
+	}
+	if len(trailers) > 0 {
+		kept = append(append(kept, ""), trailers...)
+	}
+	return kept
+}
+
+// quotePairs are the quotes models put around a message or its subject
+var quotePairs = [][2]string{{`"`, `"`}, {"'", "'"}, {"`", "`"}, {"“", "”"}, {"‘", "’"}}
+
+// unquote removes quotes around the whole message or around its subject line
+func unquote(message string) string {
+	for _, pair := range quotePairs {
+		open, end := pair[0], pair[1]
+		if len(message) > len(open)+len(end) && strings.HasPrefix(message, open) && strings.HasSuffix(message, end) {
+			inner := message[len(open) : len(message)-len(end)]
+			if !strings.Contains(inner, open) && !strings.Contains(inner, end) {
+				return strings.TrimSpace(inner)
+			}
+		}
+	}
+
+	subject, body, hasBody := strings.Cut(message, "\n")
+	if !hasBody {
+		return message
+	}
+	if unquoted := unquote(subject); unquoted != subject {
+		return unquoted + "\n" + body
+	}
+	return message
+}
+
+// isRefusal reports whether a subject starts an answer that is not a commit message
+func isRefusal(subject string) bool {
+	return matchesAny(refusalPatterns, normalizeQuotes(strings.ToLower(strings.TrimSpace(subject))))
+}
+
+// matchesAny reports whether text matches one of the patterns
+func matchesAny(patterns []*regexp.Regexp, text string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeQuotes replaces typographic apostrophes, e.g. in "I’m sorry"
+func normalizeQuotes(text string) string {
+	return strings.ReplaceAll(text, "’", "'")
+}
+
+// excerpt quotes the start of a rejected answer for error messages
+func excerpt(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxExcerptLength {
+		text = string(runes[:maxExcerptLength-3]) + "..."
+	}
+	return fmt.Sprintf("%q", text)
+}
@@ -0,0 +1,375 @@
+package commit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		want      string
+		wantError bool
+	}{
+		// Clean answers are kept as they are
+		{
+			name:  "subject only",
+			input: "Add user authentication",
+			want:  "Add user authentication",
+		},
+		{
+			name:  "subject and body",
+			input: "Add user authentication\n\nImplement JWT-based sessions.",
+			want:  "Add user authentication\n\nImplement JWT-based sessions.",
+		},
+		{
+			name:  "conventional commit with bullets",
+			input: "feat(auth): add login\n\n- Add login handler\n- Add session store",
+			want:  "feat(auth): add login\n\n- Add login handler\n- Add session store",
+		},
+		{
+			name:  "star bullets",
+			input: "Update docs\n\n* Fix typos\n* Add *new* examples",
+			want:  "Update docs\n\n* Fix typos\n* Add new examples",
+		},
+		{
+			name:  "identifiers with underscores and stars",
+			input: "Pass **kwargs to __init__ and **opts to run",
+			want:  "Pass **kwargs to __init__ and **opts to run",
+		},
+		{
+			name:  "body mentioning this commit",
+			input: "Add cache\n\nThis commit adds an LRU cache in front of the store.",
+			want:  "Add cache\n\nThis commit adds an LRU cache in front of the store.",
+		},
+		{
+			name:  "inline code in the subject",
+			input: "Rename `Run` to `Execute`",
+			want:  "Rename `Run` to `Execute`",
+		},
+		{
+			name:  "subject starting with Here",
+			input: "Here-document support in shell parser",
+			want:  "Here-document support in shell parser",
+		},
+
+		// Preambles
+		{
+			name:  "here is preamble",
+			input: "Here is the commit message:\n\nAdd user authentication",
+			want:  "Add user authentication",
+		},
+		{
+			name:  "here's preamble with typographic apostrophe",
+			input: "Here’s a concise commit message for these changes:\nFix race in watcher",
+			want:  "Fix race in watcher",
+		},
+		{
+			name:  "here is preamble ending with a period",
+			input: "Here is a suggested commit message based on the diff.\n\nFix race in watcher",
+			want:  "Fix race in watcher",
+		},
+		{
+			name:  "sure preamble",
+			input: "Sure! Here's a commit message for your changes:\n\nAdd retry to uploads\n\nRetry failed uploads up to three times.",
+			want:  "Add retry to uploads\n\nRetry failed uploads up to three times.",
+		},
+		{
+			name:  "interjection on its own line",
+			input: "Certainly.\n\nHere is the message:\n\nRemove dead code",
+			want:  "Remove dead code",
+		},
+		{
+			name:  "interjection before the subject",
+			input: "Sure! Remove dead code",
+			want:  "Remove dead code",
+		},
+		{
+			name:  "based on preamble",
+			input: "Based on the diff, I'd write:\n\nRefactor config loading",
+			want:  "Refactor config loading",
+		},
+		{
+			name:  "suggestion preamble",
+			input: "I'd suggest the following commit message:\nRefactor config loading",
+			want:  "Refactor config loading",
+		},
+		{
+			name:  "commit message label on its own line",
+			input: "Commit message:\nUpdate dependencies",
+			want:  "Update dependencies",
+		},
+		{
+			name:  "commit message label before the subject",
+			input: "Commit message: Update dependencies",
+			want:  "Update dependencies",
+		},
+		{
+			name:  "subject and body labels",
+			input: "Subject: Update dependencies\n\nBody:\nBump cobra to v1.8.",
+			want:  "Update dependencies\n\nBump cobra to v1.8.",
+		},
+		{
+			name:  "body label before text",
+			input: "Title: Update dependencies\n\nDescription: Bump cobra to v1.8.",
+			want:  "Update dependencies\n\nBump cobra to v1.8.",
+		},
+		{
+			name:  "markdown heading label",
+			input: "## Suggested Commit Message\n\nAdd dark mode",
+			want:  "Add dark mode",
+		},
+		{
+			name:  "bold label",
+			input: "**Commit message:**\n\nAdd dark mode",
+			want:  "Add dark mode",
+		},
+
+		// Code fences
+		{
+			name:  "fenced message",
+			input: "```\nAdd dark mode\n\nToggle in settings.\n```",
+			want:  "Add dark mode\n\nToggle in settings.",
+		},
+		{
+			name:  "fence with language",
+			input: "```text\nAdd dark mode\n```",
+			want:  "Add dark mode",
+		},
+		{
+			name:  "fence between preamble and explanation",
+			input: "Here's the commit message:\n\n```git\nAdd dark mode\n\nToggle in settings.\n```\n\nI kept the subject short and used the imperative mood.",
+			want:  "Add dark mode\n\nToggle in settings.",
+		},
+		{
+			name:  "unclosed fence",
+			input: "```\nAdd dark mode",
+			want:  "Add dark mode",
+		},
+		{
+			name:  "inline fence",
+			input: "```Add dark mode```",
+			want:  "Add dark mode",
+		},
+
+		// Postambles
+		{
+			name:  "this commit message postamble",
+			input: "Commit message:\nFix null pointer in parser\n\nCheck the token before use.\n\nThis commit message follows the conventional format and keeps the subject under 50 characters.",
+			want:  "Fix null pointer in parser\n\nCheck the token before use.",
+		},
+		{
+			name:  "let me know postamble",
+			input: "Commit message:\nFix null pointer in parser\n\nLet me know if you'd like any changes!",
+			want:  "Fix null pointer in parser",
+		},
+		{
+			name:  "explanation heading postamble",
+			input: "Commit message:\nFix null pointer in parser\n\n**Explanation:**\n- The subject uses the imperative mood.",
+			want:  "Fix null pointer in parser",
+		},
+		{
+			name:  "note postamble",
+			input: "Commit message:\nFix null pointer in parser\n\nNote: I assumed the parser is only used by the CLI.",
+			want:  "Fix null pointer in parser",
+		},
+		{
+			name:  "separator postamble",
+			input: "Commit message:\nFix null pointer in parser\n\n---\n\nThe message describes the fix.",
+			want:  "Fix null pointer in parser",
+		},
+		{
+			name:  "alternative postamble",
+			input: "Commit message:\nFix null pointer in parser\n\nAlternatively, a shorter version:\nFix parser crash",
+			want:  "Fix null pointer in parser",
+		},
+		{
+			name:  "postamble in a fence",
+			input: "```\nFix null pointer in parser\n```\n\nThis message is short and uses the imperative mood.",
+			want:  "Fix null pointer in parser",
+		},
+		{
+			name:  "trailers after the postamble",
+			input: "Here is the commit message:\n\nfeat!: drop v1 API\n\nNote: the subject mentions the break.\n\nBREAKING CHANGE: v1 endpoints are gone\nRefs: #42",
+			want:  "feat!: drop v1 API\n\nBREAKING CHANGE: v1 endpoints are gone\nRefs: #42",
+		},
+		{
+			name:  "note in the body of a plain answer",
+			input: "Flush cache on config reload\n\nNote that the cache is now flushed on every reload.\n\nBREAKING CHANGE: cached entries no longer survive a reload",
+			want:  "Flush cache on config reload\n\nNote that the cache is now flushed on every reload.\n\nBREAKING CHANGE: cached entries no longer survive a reload",
+		},
+		{
+			name:  "note without a colon after a preamble",
+			input: "Here is the commit message:\n\nFlush cache on config reload\n\nNote that the cache is now flushed on every reload.",
+			want:  "Flush cache on config reload\n\nNote that the cache is now flushed on every reload.",
+		},
+		{
+			name:  "message queue in the body",
+			input: "Commit message:\nRetry failed deliveries\n\nThe message queue now retries failed deliveries twice.",
+			want:  "Retry failed deliveries\n\nThe message queue now retries failed deliveries twice.",
+		},
+		{
+			name:  "word starting with note",
+			input: "Here is the commit message:\n\nAdd notebook export\n\nNotebook cells can now be exported to HTML.",
+			want:  "Add notebook export\n\nNotebook cells can now be exported to HTML.",
+		},
+		{
+			name:  "commentary-like body of a plain answer",
+			input: "Document the release process\n\nLet me know pages link to the new guide.\n\n---\n\nRelease notes follow.",
+			want:  "Document the release process\n\nLet me know pages link to the new guide.\n\n---\n\nRelease notes follow.",
+		},
+		{
+			name:  "preamble and postamble",
+			input: "Here is a commit message for the staged changes:\n\nAdd health endpoint\n\nExpose /healthz for the load balancer.\n\nI hope this helps!",
+			want:  "Add health endpoint\n\nExpose /healthz for the load balancer.",
+		},
+
+		// Quotes and emphasis
+		{
+			name:  "double quoted subject",
+			input: `"Add health endpoint"`,
+			want:  "Add health endpoint",
+		},
+		{
+			name:  "typographic quotes",
+			input: "“Add health endpoint”",
+			want:  "Add health endpoint",
+		},
+		{
+			name:  "backtick quoted subject",
+			input: "`Add health endpoint`",
+			want:  "Add health endpoint",
+		},
+		{
+			name:  "quoted subject with body",
+			input: "\"Add health endpoint\"\n\nExpose /healthz.",
+			want:  "Add health endpoint\n\nExpose /healthz.",
+		},
+		{
+			name:  "quoted whole message",
+			input: "'Add health endpoint\n\nExpose /healthz.'",
+			want:  "Add health endpoint\n\nExpose /healthz.",
+		},
+		{
+			name:  "quotes inside the subject",
+			input: `"Fix" and "Add" prefixes in changelog`,
+			want:  `"Fix" and "Add" prefixes in changelog`,
+		},
+		{
+			name:  "bold subject",
+			input: "**Add health endpoint**\n\nExpose /healthz.",
+			want:  "Add health endpoint\n\nExpose /healthz.",
+		},
+		{
+			name:  "heading subject",
+			input: "# Add health endpoint\n\nExpose /healthz.",
+			want:  "Add health endpoint\n\nExpose /healthz.",
+		},
+		{
+			name:  "italic words in the body",
+			input: "Add health endpoint\n\nThe check is *not* authenticated.",
+			want:  "Add health endpoint\n\nThe check is not authenticated.",
+		},
+		{
+			name:  "windows line endings",
+			input: "Here is the commit message:\r\n\r\nAdd health endpoint\r\n\r\nExpose /healthz.",
+			want:  "Add health endpoint\n\nExpose /healthz.",
+		},
+
+		// Refusals and non-answers
+		{
+			name:      "apology",
+			input:     "I'm sorry, but I can't help with that.",
+			wantError: true,
+		},
+		{
+			name:      "typographic apology",
+			input:     "I’m unable to generate a commit message for this diff.",
+			wantError: true,
+		},
+		{
+			name:      "cannot",
+			input:     "I cannot determine the purpose of these changes.",
+			wantError: true,
+		},
+		{
+			name:      "as an AI",
+			input:     "As an AI language model, I don't have access to your repository.",
+			wantError: true,
+		},
+		{
+			name:      "unfortunately",
+			input:     "Unfortunately, the diff you provided is empty.",
+			wantError: true,
+		},
+		{
+			name:      "no changes",
+			input:     "There are no changes in the provided diff.",
+			wantError: true,
+		},
+		{
+			name:      "empty diff",
+			input:     "The diff appears to be empty, so there is nothing to describe.",
+			wantError: true,
+		},
+		{
+			name:      "request for the diff",
+			input:     "Could you please provide the diff you'd like me to summarize?",
+			wantError: true,
+		},
+		{
+			name:      "forgot the diff",
+			input:     "It seems you forgot to include the diff.",
+			wantError: true,
+		},
+		{
+			name:      "more information",
+			input:     "I need more context about these changes to write a good message.",
+			wantError: true,
+		},
+		{
+			name:      "refusal after a preamble",
+			input:     "Sure!\n\nI'm sorry, I can't see any code changes.",
+			wantError: true,
+		},
+		{
+			name:      "only a preamble",
+			input:     "Here is the commit message:",
+			wantError: true,
+		},
+		{
+			name:      "empty fence",
+			input:     "```\n```",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extract(tt.input)
+			if tt.wantError {
+				if !errors.Is(err, ErrNotAMessage) {
+					t.Errorf("Expected ErrNotAMessage, got %q, %v", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatCommitMessage_Refusal(t *testing.T) {
+	_, err := FormatCommitMessage("I'm sorry, but I can't help with that.")
+	if !errors.Is(err, ErrNotAMessage) {
+		t.Errorf("Expected ErrNotAMessage, got %v", err)
+	}
+	if err != nil && err.Error() != `the model did not write a commit message: "I'm sorry, but I can't help with that."` {
+		t.Errorf("Unexpected error message %q", err.Error())
+	}
+}
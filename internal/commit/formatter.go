@@ -2,6 +2,8 @@ package commit
 
 import (
 	"fmt"
+	"path"
+	"regexp"
 	"strings"
 	"unicode"
 )
@@ -11,12 +13,21 @@ const (
 	MaxSubjectLength = 72
 	// MaxBodyLineLength is the maximum recommended length for commit body lines
 	MaxBodyLineLength = 72
+	// BreakingChangeFooter is the conventional commit footer token for breaking changes
+	BreakingChangeFooter = "BREAKING CHANGE"
 )
 
+// typePrefixPattern matches a conventional commit type prefix such as "feat(api):" or "Fix!:"
+var typePrefixPattern = regexp.MustCompile(`^([A-Za-z]+)(\([^)]*\))?(!)?:`)
+
 // Message represents a structured commit message
 type Message struct {
-	Subject string
-	Body    string
+	Type     string // Conventional commit type such as "feat", empty when the subject has no prefix
+	Scope    string // Conventional commit scope, e.g. "api" in "feat(api): ..."
+	Subject  string // The full subject line including any type prefix
+	Body     string
+	Footers  []string // Trailer lines such as "BREAKING CHANGE: ..." or "Refs: #123"
+	Breaking bool     // Whether the change breaks compatibility
 }
 
 // Format formats a commit message according to GitHub conventions
@@ -29,9 +40,82 @@ func (m *Message) Format() string {
 	if m.Body != "" {
 		result += "\n\n" + m.Body
 	}
+	if len(m.Footers) > 0 {
+		result += "\n\n" + strings.Join(m.Footers, "\n")
+	}
 	return result
 }
 
+// AppendParagraph adds a paragraph to the end of the body
+func (m *Message) AppendParagraph(paragraph string) {
+	paragraph = wrapText(paragraph, MaxBodyLineLength)
+	if m.Body == "" {
+		m.Body = paragraph
+		return
+	}
+	m.Body += "\n\n" + paragraph
+}
+
+// FormattingMessage builds the message for a change that only touches whitespace
+func FormattingMessage(files []string) *Message {
+	if len(files) == 1 {
+		return &Message{Subject: "style: reformat " + path.Base(files[0])}
+	}
+
+	lines := make([]string, 0, len(files))
+	for _, file := range files {
+		lines = append(lines, "- "+file)
+	}
+	return &Message{
+		Subject: fmt.Sprintf("style: reformat %d files", len(files)),
+		Body:    "Whitespace and formatting changes only:\n\n" + strings.Join(lines, "\n"),
+	}
+}
+
+// FormattingNote describes formatting-only changes left out of the generated message
+func FormattingNote(files []string) string {
+	return fmt.Sprintf("Also includes formatting-only changes in %s.", strings.Join(files, ", "))
+}
+
+// MarkBreaking flags the message as a breaking change. It adds the conventional "!"
+// marker after the type prefix of the subject (when there is one) and a
+// "BREAKING CHANGE:" footer with the given description unless the model already wrote one.
+func (m *Message) MarkBreaking(description string) {
+	m.Breaking = true
+
+	if loc := typePrefixPattern.FindStringSubmatchIndex(m.Subject); loc != nil && loc[6] == -1 {
+		// Insert "!" right before the colon
+		colon := loc[1] - 1
+		m.Subject = m.Subject[:colon] + "!" + m.Subject[colon:]
+	}
+
+	if m.hasBreakingFooter() {
+		return
+	}
+	m.Footers = append(m.Footers, BreakingChangeFooter+": "+strings.TrimSpace(description))
+}
+
+// hasBreakingFooter reports whether the body or footers already describe a breaking change
+func (m *Message) hasBreakingFooter() bool {
+	for _, footer := range m.Footers {
+		if isBreakingFooter(footer) {
+			return true
+		}
+	}
+	for _, line := range strings.Split(m.Body, "\n") {
+		if isBreakingFooter(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBreakingFooter reports whether a line is a breaking change footer
+func isBreakingFooter(line string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, BreakingChangeFooter+":") || strings.HasPrefix(line, "BREAKING-CHANGE:")
+}
+
 // String returns the formatted commit message
 func (m *Message) String() string {
 	return m.Format()
@@ -43,8 +127,11 @@ func FormatCommitMessage(rawMessage string) (*Message, error) {
 		return nil, fmt.Errorf("empty commit message")
 	}
 
-	// Clean the raw message - remove code blocks and fix formatting
-	cleanedMessage := cleanMessage(rawMessage)
+	// Extract the message from the surrounding chatter and fix formatting
+	cleanedMessage, err := extract(rawMessage)
+	if err != nil {
+		return nil, err
+	}
 
 	lines := strings.Split(strings.TrimSpace(cleanedMessage), "\n")
 	if len(lines) == 0 {
@@ -82,10 +169,20 @@ func FormatCommitMessage(rawMessage string) (*Message, error) {
 		}
 	}
 
-	return &Message{
+	msg := &Message{
 		Subject: subject,
 		Body:    body,
-	}, nil
+	}
+	if match := typePrefixPattern.FindStringSubmatch(subject); match != nil {
+		msg.Type = strings.ToLower(match[1])
+		msg.Scope = strings.Trim(match[2], "()")
+		msg.Breaking = match[3] != ""
+	}
+	if msg.hasBreakingFooter() {
+		msg.Breaking = true
+	}
+
+	return msg, nil
 }
 
 // formatSubject formats the subject line according to conventions
@@ -99,8 +196,8 @@ func formatSubject(subject *string) string {
 	}
 
 	// Before converting Rune to subject, I should first check if the first letter is capitalized.
-	// If it is not, I should convert it to uppercase.
-	if len(*subject) > 0 && unicode.IsLower([]rune(*subject)[0]) {
+	// If it is not, I should convert it to uppercase. Conventional type prefixes stay lowercase.
+	if len(*subject) > 0 && unicode.IsLower([]rune(*subject)[0]) && !typePrefixPattern.MatchString(*subject) {
 		runes := []rune(*subject)
 		runes[0] = unicode.ToUpper(runes[0])
 		*subject = string(runes)
@@ -217,8 +314,8 @@ func ValidateMessage(msg *Message) error {
 		return fmt.Errorf("subject line should not end with a period")
 	}
 
-	// Check if subject starts with lowercase (should be capitalized)
-	if len(msg.Subject) > 0 && unicode.IsLower([]rune(msg.Subject)[0]) {
+	// Check if subject starts with lowercase (should be capitalized), unless it is a conventional type prefix
+	if len(msg.Subject) > 0 && unicode.IsLower([]rune(msg.Subject)[0]) && !typePrefixPattern.MatchString(msg.Subject) {
 		return fmt.Errorf("subject line should start with a capital letter")
 	}
 
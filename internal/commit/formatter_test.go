@@ -129,6 +129,11 @@ func TestMessage_Format(t *testing.T) {
 			message:  &Message{Subject: "Fix bug", Body: "First line\nSecond line"},
 			expected: "Fix bug\n\nFirst line\nSecond line",
 		},
+		{
+			name:     "subject with body and footers",
+			message:  &Message{Subject: "Fix bug", Body: "Details", Footers: []string{"Refs: #12", "BREAKING CHANGE: new config"}},
+			expected: "Fix bug\n\nDetails\n\nRefs: #12\nBREAKING CHANGE: new config",
+		},
 	}
 
 	for _, tt := range tests {
@@ -141,6 +146,114 @@ func TestMessage_Format(t *testing.T) {
 	}
 }
 
+func TestMessage_MarkBreaking(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  *Message
+		expected string
+	}{
+		{
+			name:     "conventional prefix",
+			message:  &Message{Subject: "feat: drop legacy client"},
+			expected: "feat!: drop legacy client\n\nBREAKING CHANGE: removed func NewClient",
+		},
+		{
+			name:     "conventional prefix with scope",
+			message:  &Message{Subject: "Refactor(llm): rename request type", Body: "Rename the request type."},
+			expected: "Refactor(llm)!: rename request type\n\nRename the request type.\n\nBREAKING CHANGE: removed func NewClient",
+		},
+		{
+			name:     "already marked",
+			message:  &Message{Subject: "feat!: drop legacy client"},
+			expected: "feat!: drop legacy client\n\nBREAKING CHANGE: removed func NewClient",
+		},
+		{
+			name:     "no prefix",
+			message:  &Message{Subject: "Drop legacy client"},
+			expected: "Drop legacy client\n\nBREAKING CHANGE: removed func NewClient",
+		},
+		{
+			name:     "footer already in body",
+			message:  &Message{Subject: "feat: drop legacy client", Body: "BREAKING CHANGE: NewClient is gone"},
+			expected: "feat!: drop legacy client\n\nBREAKING CHANGE: NewClient is gone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.message.MarkBreaking("removed func NewClient")
+			if !tt.message.Breaking {
+				t.Error("Expected message to be marked as breaking")
+			}
+			if result := tt.message.Format(); result != tt.expected {
+				t.Errorf("Format() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatCommitMessage_DetectsBreaking(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "bang marker", input: "feat(api)!: remove v1 endpoints", want: true},
+		{name: "footer", input: "feat: remove v1 endpoints\n\nBREAKING CHANGE: v1 is gone", want: true},
+		{name: "regular change", input: "feat: add v2 endpoints", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := FormatCommitMessage(tt.input)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.Breaking != tt.want {
+				t.Errorf("Breaking = %v, want %v", result.Breaking, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormattingMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		expected string
+	}{
+		{
+			name:     "single file",
+			files:    []string{"internal/commit/formatter.go"},
+			expected: "style: reformat formatter.go",
+		},
+		{
+			name:     "multiple files",
+			files:    []string{"a.go", "web/b.ts"},
+			expected: "style: reformat 2 files\n\nWhitespace and formatting changes only:\n\n- a.go\n- web/b.ts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := FormattingMessage(tt.files).Format(); result != tt.expected {
+				t.Errorf("Format() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMessage_AppendParagraph(t *testing.T) {
+	msg := &Message{Subject: "Fix bug"}
+	msg.AppendParagraph(FormattingNote([]string{"a.go"}))
+	msg.AppendParagraph("Second.")
+
+	expected := "Fix bug\n\nAlso includes formatting-only changes in a.go.\n\nSecond."
+	if result := msg.Format(); result != expected {
+		t.Errorf("Format() = %q, want %q", result, expected)
+	}
+}
+
 func TestWrapText(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -231,6 +344,10 @@ func TestValidateMessage(t *testing.T) {
 			message:   &Message{Subject: "add feature"},
 			wantError: "subject line should start with a capital letter",
 		},
+		{
+			name:    "lowercase conventional prefix",
+			message: &Message{Subject: "chore(deps): bump golang.org/x/text from 0.27.0 to 0.28.0"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -366,6 +483,11 @@ func TestFormatSubject(t *testing.T) {
 			input:    "",
 			expected: "",
 		},
+		{
+			name:     "conventional prefix stays lowercase",
+			input:    "test(commit): add wrap tests",
+			expected: "test(commit): add wrap tests",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,109 @@
+package commit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// structuredMessage is the JSON object returned by models in structured output mode
+type structuredMessage struct {
+	Type     string   `json:"type"`
+	Scope    string   `json:"scope"`
+	Subject  string   `json:"subject"`
+	Body     string   `json:"body"`
+	Breaking bool     `json:"breaking"`
+	Footers  []string `json:"footers"`
+}
+
+// ParseGenerated turns a generated response into a Message. JSON objects from structured
+// output mode are parsed field by field, anything else is formatted as a text message.
+func ParseGenerated(raw string) (*Message, error) {
+	if object, ok := jsonObject(raw); ok {
+		if msg, err := ParseJSON(object); err == nil {
+			return msg, nil
+		}
+	}
+	return FormatCommitMessage(raw)
+}
+
+// ParseJSON parses a structured commit message with the fields type, scope,
+// subject, body, breaking and footers
+func ParseJSON(raw string) (*Message, error) {
+	var structured structuredMessage
+	if err := json.Unmarshal([]byte(raw), &structured); err != nil {
+		return nil, fmt.Errorf("failed to parse structured commit message: %w", err)
+	}
+
+	description := strings.TrimSpace(structured.Subject)
+	if description == "" {
+		return nil, fmt.Errorf("empty subject line")
+	}
+
+	msg := &Message{
+		Type:     strings.ToLower(strings.TrimSpace(structured.Type)),
+		Scope:    strings.TrimSpace(structured.Scope),
+		Body:     formatBody(cleanMessage(structured.Body)),
+		Breaking: structured.Breaking,
+	}
+
+	// Models sometimes repeat the prefix in the subject even though it has its own field
+	if match := typePrefixPattern.FindStringSubmatch(description); match != nil {
+		if msg.Type == "" {
+			msg.Type = strings.ToLower(match[1])
+			msg.Scope = strings.Trim(match[2], "()")
+		}
+		msg.Breaking = msg.Breaking || match[3] != ""
+		description = strings.TrimSpace(description[len(match[0]):])
+	}
+
+	subject := description
+	if msg.Type != "" {
+		prefix := msg.Type
+		if msg.Scope != "" {
+			prefix += "(" + msg.Scope + ")"
+		}
+		if msg.Breaking {
+			prefix += "!"
+		}
+		subject = prefix + ": " + lowerFirst(description)
+	}
+	msg.Subject = formatSubject(&subject)
+
+	for _, footer := range structured.Footers {
+		if footer = strings.TrimSpace(footer); footer != "" {
+			msg.Footers = append(msg.Footers, footer)
+		}
+	}
+	if msg.hasBreakingFooter() {
+		msg.Breaking = true
+	}
+
+	return msg, nil
+}
+
+// jsonObject returns the JSON object in a response, without the code fence some models add around it
+func jsonObject(raw string) (string, bool) {
+	text := strings.TrimSpace(raw)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+		text = strings.TrimSpace(text)
+	}
+	if !strings.HasPrefix(text, "{") || !strings.HasSuffix(text, "}") || !json.Valid([]byte(text)) {
+		return "", false
+	}
+	return text, true
+}
+
+// lowerFirst lowercases the first letter of a conventional commit description,
+// leaving acronyms such as "API" alone
+func lowerFirst(s string) string {
+	runes := []rune(s)
+	if len(runes) > 1 && unicode.IsUpper(runes[0]) && !unicode.IsUpper(runes[1]) {
+		runes[0] = unicode.ToLower(runes[0])
+	}
+	return string(runes)
+}
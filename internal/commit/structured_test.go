@@ -0,0 +1,168 @@
+package commit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantType     string
+		wantScope    string
+		wantSubject  string
+		wantBody     string
+		wantFooters  []string
+		wantBreaking bool
+		wantError    string
+	}{
+		{
+			name:        "all fields",
+			input:       `{"type": "feat", "scope": "api", "subject": "Add pagination to list endpoints", "body": "Large accounts timed out when listing all projects.", "breaking": false, "footers": ["Refs: #42"]}`,
+			wantType:    "feat",
+			wantScope:   "api",
+			wantSubject: "feat(api): add pagination to list endpoints",
+			wantBody:    "Large accounts timed out when listing all projects.",
+			wantFooters: []string{"Refs: #42"},
+		},
+		{
+			name:        "empty scope and body",
+			input:       `{"type": "fix", "scope": "", "subject": "handle empty config file.", "body": "", "breaking": false, "footers": []}`,
+			wantType:    "fix",
+			wantSubject: "fix: handle empty config file",
+		},
+		{
+			name:         "breaking change",
+			input:        `{"type": "refactor", "scope": "llm", "subject": "Rename Generate to GenerateCommitMessage", "body": "", "breaking": true, "footers": ["BREAKING CHANGE: Generate was renamed"]}`,
+			wantType:     "refactor",
+			wantScope:    "llm",
+			wantSubject:  "refactor(llm)!: rename Generate to GenerateCommitMessage",
+			wantFooters:  []string{"BREAKING CHANGE: Generate was renamed"},
+			wantBreaking: true,
+		},
+		{
+			name:         "breaking footer without flag",
+			input:        `{"type": "feat", "subject": "Drop Go 1.20 support", "footers": ["BREAKING CHANGE: Go 1.21 is required"]}`,
+			wantType:     "feat",
+			wantSubject:  "feat: drop Go 1.20 support",
+			wantFooters:  []string{"BREAKING CHANGE: Go 1.21 is required"},
+			wantBreaking: true,
+		},
+		{
+			name:        "prefix repeated in the subject",
+			input:       `{"type": "docs", "scope": "", "subject": "docs: update README", "body": "", "breaking": false, "footers": []}`,
+			wantType:    "docs",
+			wantSubject: "docs: update README",
+		},
+		{
+			name:        "no type",
+			input:       `{"subject": "update dependencies"}`,
+			wantSubject: "Update dependencies",
+		},
+		{
+			name:        "acronyms keep their case",
+			input:       `{"type": "fix", "subject": "API keys leaking into logs"}`,
+			wantType:    "fix",
+			wantSubject: "fix: API keys leaking into logs",
+		},
+		{
+			name:        "long body is wrapped",
+			input:       `{"type": "perf", "subject": "Cache parsed diffs", "body": "Parsing the diff for every analysis step was the slowest part of generating a message for large changes."}`,
+			wantType:    "perf",
+			wantSubject: "perf: cache parsed diffs",
+			wantBody:    "Parsing the diff for every analysis step was the slowest part of\ngenerating a message for large changes.",
+		},
+		{
+			name:      "empty subject",
+			input:     `{"type": "fix", "subject": " "}`,
+			wantError: "empty subject line",
+		},
+		{
+			name:      "invalid JSON",
+			input:     `{"type": "fix"`,
+			wantError: "failed to parse structured commit message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseJSON(tt.input)
+
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("Expected error containing '%s', got %v", tt.wantError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if result.Type != tt.wantType || result.Scope != tt.wantScope {
+				t.Errorf("Type/Scope = '%s'/'%s', want '%s'/'%s'", result.Type, result.Scope, tt.wantType, tt.wantScope)
+			}
+			if result.Subject != tt.wantSubject {
+				t.Errorf("Subject = '%s', want '%s'", result.Subject, tt.wantSubject)
+			}
+			if result.Body != tt.wantBody {
+				t.Errorf("Body = '%s', want '%s'", result.Body, tt.wantBody)
+			}
+			if !reflect.DeepEqual(result.Footers, tt.wantFooters) {
+				t.Errorf("Footers = %q, want %q", result.Footers, tt.wantFooters)
+			}
+			if result.Breaking != tt.wantBreaking {
+				t.Errorf("Breaking = %v, want %v", result.Breaking, tt.wantBreaking)
+			}
+		})
+	}
+}
+
+func TestParseGenerated(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantSubject string
+		wantType    string
+	}{
+		{
+			name:        "JSON object",
+			input:       `{"type": "fix", "scope": "", "subject": "Close response bodies", "body": "", "breaking": false, "footers": []}`,
+			wantSubject: "fix: close response bodies",
+			wantType:    "fix",
+		},
+		{
+			name:        "JSON in a code fence",
+			input:       "```json\n{\"type\": \"test\", \"subject\": \"Cover retry headers\"}\n```",
+			wantSubject: "test: cover retry headers",
+			wantType:    "test",
+		},
+		{
+			name:        "text message",
+			input:       "feat(ui): show candidates\n\nList every message of the session.",
+			wantSubject: "feat(ui): show candidates",
+			wantType:    "feat",
+		},
+		{
+			name:        "JSON without a subject is treated as text",
+			input:       `{"message": "Fix typo"}`,
+			wantSubject: `{"message": "Fix typo"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseGenerated(tt.input)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.Subject != tt.wantSubject {
+				t.Errorf("Subject = '%s', want '%s'", result.Subject, tt.wantSubject)
+			}
+			if result.Type != tt.wantType {
+				t.Errorf("Type = '%s', want '%s'", result.Type, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/siddhartha/rune/internal/models"
+)
+
+const (
+	// DefaultAzureAPIVersion is the Azure OpenAI data plane API version used when none is configured
+	DefaultAzureAPIVersion = "2024-10-21"
+
+	// AzurePrefix selects an Azure deployment, e.g. "azure/gpt-4o"
+	AzurePrefix = "azure/"
+)
+
+// AzureConfig describes an Azure OpenAI resource
+type AzureConfig struct {
+	Endpoint    string            `json:"endpoint"`              // e.g. "https://my-resource.openai.azure.com"
+	APIVersion  string            `json:"api_version,omitempty"` // defaults to DefaultAzureAPIVersion
+	Deployments map[string]string `json:"deployments"`           // rune model name -> deployment name
+}
+
+// GetEndpoint returns the resource endpoint from config or AZURE_OPENAI_ENDPOINT
+func (a *AzureConfig) GetEndpoint() string {
+	if a != nil && a.Endpoint != "" {
+		return strings.TrimSuffix(a.Endpoint, "/")
+	}
+	return strings.TrimSuffix(os.Getenv("AZURE_OPENAI_ENDPOINT"), "/")
+}
+
+// GetAPIVersion returns the configured API version or the default
+func (a *AzureConfig) GetAPIVersion() string {
+	if a != nil && a.APIVersion != "" {
+		return a.APIVersion
+	}
+	return DefaultAzureAPIVersion
+}
+
+// Deployment returns the deployment name for a rune model name
+func (a *AzureConfig) Deployment(model string) (string, error) {
+	if a != nil {
+		if deployment, ok := a.Deployments[model]; ok && deployment != "" {
+			return deployment, nil
+		}
+	}
+	return "", fmt.Errorf("no Azure deployment configured for model %s", model)
+}
+
+// azureModel returns model info for a model served by an Azure deployment
+func (c *Config) azureModel(name string) (*models.ModelInfo, error) {
+	if name == "" {
+		return nil, fmt.Errorf("model not found: no Azure model configured")
+	}
+
+	deployment, err := c.Azure.Deployment(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &models.ModelInfo{
+		ID:          name,
+		ShortName:   AzurePrefix + name,
+		Name:        name + " (Azure)",
+		Provider:    ProviderAzure,
+		Company:     "Azure OpenAI",
+		Description: "Deployment " + deployment,
+	}
+	// Reuse registry metadata when the rune model name is a known model
+	if known, err := models.FindModel(name); err == nil {
+		info.Name = known.Name + " (Azure)"
+		info.ContextSize = known.ContextSize
+	}
+	return info, nil
+}
+
+// setupAzure asks for the Azure OpenAI resource and one deployment.
+// It returns the Azure settings and the rune model name mapped to the deployment.
+func setupAzure(reader *bufio.Reader) (*AzureConfig, string, error) {
+	ask := func(prompt string) string {
+		fmt.Print(prompt)
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(answer)
+	}
+
+	endpoint := ask("\nAzure OpenAI endpoint (e.g. https://my-resource.openai.azure.com): ")
+	if endpoint == "" {
+		return nil, "", fmt.Errorf("Azure OpenAI endpoint cannot be empty")
+	}
+
+	deployment := ask("Deployment name: ")
+	if deployment == "" {
+		return nil, "", fmt.Errorf("deployment name cannot be empty")
+	}
+
+	model := ask(fmt.Sprintf("Model name to use with --model azure/<name> (press Enter for %s): ", deployment))
+	if model == "" {
+		model = deployment
+	}
+
+	apiVersion := ask(fmt.Sprintf("API version (press Enter for %s): ", DefaultAzureAPIVersion))
+
+	return &AzureConfig{
+		Endpoint:    endpoint,
+		APIVersion:  apiVersion,
+		Deployments: map[string]string{model: deployment},
+	}, model, nil
+}
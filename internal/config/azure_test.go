@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestConfig_FindModel_Azure(t *testing.T) {
+	cfg := &Config{
+		Azure: &AzureConfig{
+			Endpoint: "https://res.openai.azure.com",
+			Deployments: map[string]string{
+				"gpt-4o": "prod-gpt4o",
+				"dv3":    "deepseek-v3-eu",
+			},
+		},
+	}
+
+	model, err := cfg.FindModel("azure/gpt-4o")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if model.ID != "gpt-4o" || model.Provider != ProviderAzure || model.Description != "Deployment prod-gpt4o" {
+		t.Errorf("Unexpected model: %+v", model)
+	}
+
+	// Registry models keep their metadata when served from Azure
+	model, err = cfg.FindModel("azure/dv3")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if model.Name != "DeepSeek V3 (Azure)" || model.ContextSize != 163840 {
+		t.Errorf("Expected registry metadata, got %+v", model)
+	}
+
+	if _, err := cfg.FindModel("azure/gpt-35"); err == nil {
+		t.Error("Expected error for an unmapped model")
+	}
+}
+
+func TestAzureConfig_Defaults(t *testing.T) {
+	t.Setenv("AZURE_OPENAI_ENDPOINT", "https://env.openai.azure.com/")
+
+	var unset *AzureConfig
+	if got := unset.GetEndpoint(); got != "https://env.openai.azure.com" {
+		t.Errorf("Expected endpoint from environment, got %s", got)
+	}
+	if got := unset.GetAPIVersion(); got != DefaultAzureAPIVersion {
+		t.Errorf("Expected default API version, got %s", got)
+	}
+
+	azure := &AzureConfig{Endpoint: "https://cfg.openai.azure.com", APIVersion: "2024-06-01"}
+	if azure.GetEndpoint() != "https://cfg.openai.azure.com" || azure.GetAPIVersion() != "2024-06-01" {
+		t.Errorf("Expected configured values, got %s %s", azure.GetEndpoint(), azure.GetAPIVersion())
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/siddhartha/rune/internal/aws"
+)
+
+// BedrockConfig describes how to reach AWS Bedrock. Credentials are never stored
+// by rune, they come from the AWS environment variables or shared config files.
+type BedrockConfig struct {
+	Region   string `json:"region,omitempty"`   // defaults to AWS_REGION or the profile's region
+	Profile  string `json:"profile,omitempty"`  // shared config profile, defaults to AWS_PROFILE or "default"
+	Endpoint string `json:"endpoint,omitempty"` // optional runtime endpoint override, e.g. a VPC endpoint
+}
+
+// GetProfile returns the configured AWS profile, or "" to use the AWS defaults
+func (b *BedrockConfig) GetProfile() string {
+	if b == nil {
+		return ""
+	}
+	return b.Profile
+}
+
+// GetRegion returns the configured region, the AWS environment's region or us-east-1
+func (b *BedrockConfig) GetRegion() string {
+	if b != nil && b.Region != "" {
+		return b.Region
+	}
+	if region := aws.LoadRegion(b.GetProfile()); region != "" {
+		return region
+	}
+	return "us-east-1"
+}
+
+// GetEndpoint returns the runtime endpoint override, if any
+func (b *BedrockConfig) GetEndpoint() string {
+	if b == nil {
+		return ""
+	}
+	return strings.TrimSuffix(b.Endpoint, "/")
+}
+
+// setupBedrock asks for the AWS region and profile. Empty answers keep the AWS defaults.
+func setupBedrock(reader *bufio.Reader) *BedrockConfig {
+	ask := func(prompt string) string {
+		fmt.Print(prompt)
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(answer)
+	}
+
+	fmt.Println("\nRune signs Bedrock requests with your AWS credentials (environment variables or ~/.aws).")
+	bedrock := &BedrockConfig{
+		Region:  ask(fmt.Sprintf("AWS region (press Enter for %s): ", (*BedrockConfig)(nil).GetRegion())),
+		Profile: ask("AWS profile (press Enter for the default credentials): "),
+	}
+
+	if _, err := aws.LoadCredentials(bedrock.Profile); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+	return bedrock
+}
@@ -2,12 +2,14 @@ package config
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/siddhartha/rune/internal/models"
 	"github.com/zalando/go-keyring"
@@ -15,17 +17,78 @@ import (
 
 // Config represents the application configuration
 type Config struct {
-	Provider       string `json:"provider"` // "novita" or "gemini"
+	Provider       string `json:"provider"` // see the Provider constants
 	Model          string `json:"model"`
 	StagedOnly     bool   `json:"staged_only"`               // true for staged only, false for all changes
 	AutoStageAll   bool   `json:"auto_stage_all"`            // if true, automatically stage all changes when staged_only=false
-	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // configurable timeout, defaults to 60
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // time allowed for each generation, defaults to 60
+	// if true, fall back to the offline heuristic generator when the provider fails
+	HeuristicFallback bool `json:"heuristic_fallback,omitempty"`
+	// if true, models that support it answer with JSON instead of text, which is not streamed
+	StructuredOutput bool `json:"structured_output,omitempty"`
+	// if true, leave whitespace-only hunks out of the prompt for mixed diffs
+	ExcludeWhitespaceHunks bool `json:"exclude_whitespace_hunks,omitempty"`
+	// if false, skip checking exported Go APIs for breaking changes, defaults to true
+	DetectBreakingChanges *bool `json:"detect_breaking_changes,omitempty"`
+	// Ollama server URL, defaults to OLLAMA_HOST or http://localhost:11434
+	OllamaHost string `json:"ollama_host,omitempty"`
+	// name of the endpoint used by the openai-compatible provider
+	Endpoint string `json:"endpoint,omitempty"`
+	// named OpenAI-compatible APIs, selected with --model <name>/<model>
+	Endpoints map[string]*Endpoint `json:"endpoints,omitempty"`
+	// Azure OpenAI resource used by the azure provider
+	Azure *AzureConfig `json:"azure,omitempty"`
+	// AWS settings used by the bedrock provider
+	Bedrock *BedrockConfig `json:"bedrock,omitempty"`
+	// Vertex AI settings, routes the gemini provider through Google Cloud
+	Vertex *VertexConfig `json:"vertex,omitempty"`
+	// models tried in order when the selected model fails, e.g. ["dv3", "g2", "heuristic"]
+	FallbackModels []string `json:"fallback_models,omitempty"`
+	// monthly spending limit in US dollars estimated from the usage ledger, 0 for no limit
+	MonthlyBudget float64 `json:"monthly_budget,omitempty"`
+	// what happens once the budget is used up: "warn" (default) or "block" paid models
+	BudgetAction string `json:"budget_action,omitempty"`
+	// estimated cost of a single generation in US dollars above which rune asks before sending, 0 to never ask
+	ConfirmCostAbove float64 `json:"confirm_cost_above,omitempty"`
+	// prompt size in tokens above which the diff is summarized in parts first, defaults to 100000, -1 to never
+	SummarizeAboveTokens int `json:"summarize_above_tokens,omitempty"`
+	// sampling and output settings per model, keyed by any name accepted by --model
+	ModelOptions map[string]*GenerationOptions `json:"model_options,omitempty"`
+}
+
+// Endpoint describes an API that implements OpenAI's chat completions endpoint,
+// e.g. OpenAI itself, LM Studio, vLLM, llama.cpp server, LocalAI or an internal gateway
+type Endpoint struct {
+	BaseURL    string            `json:"base_url"`              // e.g. "https://api.openai.com/v1"
+	Model      string            `json:"model,omitempty"`       // default model ID for this endpoint
+	AuthStyle  string            `json:"auth_style,omitempty"`  // "bearer" (default), "api-key" or "none"
+	AuthHeader string            `json:"auth_header,omitempty"` // header for the "api-key" style, defaults to "api-key"
+	APIKeyEnv  string            `json:"api_key_env,omitempty"` // read the key from this variable instead of the keyring
+	Headers    map[string]string `json:"headers,omitempty"`     // extra headers sent with every request
+
+	// StructuredOutput requests JSON schema constrained output, for APIs that support response_format
+	StructuredOutput bool `json:"structured_output,omitempty"`
 }
 
 // Provider constants
 const (
+	ProviderNovita     = "novita"
 	ProviderGemini     = "gemini"
 	ProviderOpenRouter = "openrouter"
+	ProviderAnthropic  = "anthropic"
+	ProviderAzure      = "azure"
+	ProviderBedrock    = "bedrock"
+	ProviderHeuristic  = "heuristic"
+	ProviderOllama     = "ollama"
+	// ProviderOpenAICompatible uses one of the named Endpoints
+	ProviderOpenAICompatible = "openai-compatible"
+
+	// DefaultOllamaHost is where a local Ollama server listens by default
+	DefaultOllamaHost = "http://localhost:11434"
+
+	// Budget actions
+	BudgetActionWarn  = "warn"
+	BudgetActionBlock = "block"
 
 	// File permissions
 	configDirPerm  = 0755
@@ -34,12 +97,46 @@ const (
 
 // Default models for each provider
 var DefaultModels = map[string]string{
+	ProviderNovita:     "qwen/qwen2.5-7b-instruct",
 	ProviderGemini:     "gemini-2.0-flash-exp",
 	ProviderOpenRouter: "deepseek/deepseek-chat",
+	ProviderAnthropic:  "claude-3-5-haiku-20241022",
+	ProviderBedrock:    "anthropic.claude-3-5-haiku-20241022-v1:0",
+	ProviderHeuristic:  "heuristic",
+	ProviderOllama:     "llama3.2",
 }
 
-// getConfigPath returns the path to the configuration file
-func getConfigPath() (string, error) {
+// RequiresAPIKey reports whether a provider needs an API key.
+// OpenAI-compatible endpoints manage their keys per endpoint and Bedrock uses AWS credentials.
+func RequiresAPIKey(provider string) bool {
+	switch provider {
+	case ProviderHeuristic, ProviderOllama, ProviderOpenAICompatible, ProviderBedrock:
+		return false
+	default:
+		return true
+	}
+}
+
+// GetOllamaHost returns the Ollama server URL from config, the OLLAMA_HOST
+// environment variable or the default, in that order
+func (c *Config) GetOllamaHost() string {
+	host := c.OllamaHost
+	if host == "" {
+		host = os.Getenv("OLLAMA_HOST")
+	}
+	if host == "" {
+		return DefaultOllamaHost
+	}
+
+	// OLLAMA_HOST is often set without a scheme, e.g. "0.0.0.0:11434"
+	if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
+		host = "http://" + host
+	}
+	return strings.TrimSuffix(host, "/")
+}
+
+// Dir returns the directory holding the configuration and the usage ledger, creating it if needed
+func Dir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
@@ -50,6 +147,15 @@ func getConfigPath() (string, error) {
 		return "", fmt.Errorf("failed to create config directory: %w", err)
 	}
 
+	return configDir, nil
+}
+
+// getConfigPath returns the path to the configuration file
+func getConfigPath() (string, error) {
+	configDir, err := Dir()
+	if err != nil {
+		return "", err
+	}
 	return filepath.Join(configDir, "config.json"), nil
 }
 
@@ -108,7 +214,15 @@ func InteractiveSetup() (*Config, error) {
 	fmt.Println("Choose your AI provider:")
 	fmt.Println("1. Google Gemini")
 	fmt.Println("2. OpenRouter (Multiple models) - https://openrouter.ai/")
-	fmt.Print("\nEnter your choice (1 or 2): ")
+	fmt.Println("3. Offline heuristic (no API key or network needed)")
+	fmt.Println("4. Ollama (local models, nothing leaves your machine) - https://ollama.com/")
+	fmt.Println("5. OpenAI-compatible API (OpenAI, LM Studio, vLLM, llama.cpp, LocalAI, gateways)")
+	fmt.Println("6. Anthropic (Claude models) - https://console.anthropic.com/")
+	fmt.Println("7. Azure OpenAI (deployments in your Azure tenant)")
+	fmt.Println("8. Novita.ai (Qwen, DeepSeek and Llama models) - https://novita.ai/")
+	fmt.Println("9. AWS Bedrock (uses your AWS credentials)")
+	fmt.Println("10. Google Vertex AI (Gemini with a Google Cloud service account)")
+	fmt.Print("\nEnter your choice (1-10): ")
 
 	choice, err := reader.ReadString('\n')
 	if err != nil {
@@ -120,6 +234,13 @@ func InteractiveSetup() (*Config, error) {
 	var model string
 	var apiKeyPrompt string
 	var setupURL string
+	var ollamaHost string
+	var endpointName string
+	var endpoint *Endpoint
+	var endpointKey string
+	var azure *AzureConfig
+	var bedrock *BedrockConfig
+	var vertex *VertexConfig
 
 	switch choice {
 	case "1":
@@ -132,21 +253,66 @@ func InteractiveSetup() (*Config, error) {
 		model = setupOpenRouterModel(reader)
 		apiKeyPrompt = "Please enter your OpenRouter API key"
 		setupURL = "Get your API key at: https://openrouter.ai/keys"
+	case "3":
+		provider = ProviderHeuristic
+		model = DefaultModels[ProviderHeuristic]
+	case "4":
+		provider = ProviderOllama
+		ollamaHost, model = setupOllama(reader)
+	case "5":
+		provider = ProviderOpenAICompatible
+		endpointName, endpoint, endpointKey, err = setupEndpoint(reader)
+		if err != nil {
+			return nil, err
+		}
+		model = endpoint.Model
+	case "6":
+		provider = ProviderAnthropic
+		model = setupRegistryModel(reader, ProviderAnthropic, "Anthropic")
+		apiKeyPrompt = "Please enter your Anthropic API key"
+		setupURL = "Get your API key at: https://console.anthropic.com/settings/keys"
+	case "7":
+		provider = ProviderAzure
+		azure, model, err = setupAzure(reader)
+		if err != nil {
+			return nil, err
+		}
+		apiKeyPrompt = "Please enter your Azure OpenAI API key"
+		setupURL = "Find your key in the Azure portal under your Azure OpenAI resource > Keys and Endpoint"
+	case "8":
+		provider = ProviderNovita
+		model = setupRegistryModel(reader, ProviderNovita, "Novita.ai")
+		apiKeyPrompt = "Please enter your Novita.ai API key"
+		setupURL = "Get your API key at: https://novita.ai/settings/key-management"
+	case "9":
+		provider = ProviderBedrock
+		bedrock = setupBedrock(reader)
+		model = setupRegistryModel(reader, ProviderBedrock, "Bedrock")
+	case "10":
+		provider = ProviderGemini
+		vertex, err = setupVertex(reader)
+		if err != nil {
+			return nil, err
+		}
+		model = setupRegistryModel(reader, ProviderGemini, "Gemini")
 	default:
 		return nil, fmt.Errorf("invalid choice: %s", choice)
 	}
 
-	fmt.Printf("\n%s\n", setupURL)
-	fmt.Printf("%s: ", apiKeyPrompt)
+	var apiKey string
+	if RequiresAPIKey(provider) && vertex == nil {
+		fmt.Printf("\n%s\n", setupURL)
+		fmt.Printf("%s: ", apiKeyPrompt)
 
-	apiKey, err := reader.ReadString('\n')
-	if err != nil {
-		return nil, fmt.Errorf("failed to read API key: %w", err)
-	}
-	apiKey = strings.TrimSpace(apiKey)
+		apiKey, err = reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API key: %w", err)
+		}
+		apiKey = strings.TrimSpace(apiKey)
 
-	if apiKey == "" {
-		return nil, fmt.Errorf("API key cannot be empty")
+		if apiKey == "" {
+			return nil, fmt.Errorf("API key cannot be empty")
+		}
 	}
 
 	// Ask about commit scope preference (mutually exclusive)
@@ -180,11 +346,26 @@ func InteractiveSetup() (*Config, error) {
 		Model:        model,
 		StagedOnly:   stagedOnly,
 		AutoStageAll: autoStageAll,
+		OllamaHost:   ollamaHost,
+		Azure:        azure,
+		Bedrock:      bedrock,
+		Vertex:       vertex,
+	}
+	if endpoint != nil {
+		config.Endpoint = endpointName
+		config.Endpoints = map[string]*Endpoint{endpointName: endpoint}
 	}
 
 	// Store API key securely
-	if err := config.SetAPIKey(apiKey); err != nil {
-		return nil, fmt.Errorf("failed to store API key securely: %w", err)
+	if apiKey != "" {
+		if err := config.SetAPIKey(apiKey); err != nil {
+			return nil, fmt.Errorf("failed to store API key securely: %w", err)
+		}
+	}
+	if endpointKey != "" {
+		if err := config.SetEndpointAPIKey(endpointName, endpointKey); err != nil {
+			return nil, fmt.Errorf("failed to store API key securely: %w", err)
+		}
 	}
 
 	if err := config.Save(); err != nil {
@@ -203,6 +384,10 @@ func IsConfigured() bool {
 		return false
 	}
 
+	if !config.NeedsAPIKey(config.Provider) {
+		return true
+	}
+
 	// Check if API key exists in secure storage
 	_, err = config.GetAPIKey()
 	return err == nil
@@ -211,10 +396,16 @@ func IsConfigured() bool {
 // GetEnvVarName returns the appropriate environment variable name for the provider
 func (c *Config) GetEnvVarName() string {
 	switch c.Provider {
+	case ProviderNovita:
+		return "NOVITA_API_KEY"
 	case ProviderGemini:
 		return "GEMINI_API_KEY"
 	case ProviderOpenRouter:
 		return "OPENROUTER_API_KEY"
+	case ProviderAnthropic:
+		return "ANTHROPIC_API_KEY"
+	case ProviderAzure:
+		return "AZURE_OPENAI_API_KEY"
 	default:
 		return ""
 	}
@@ -276,15 +467,30 @@ func (c *Config) SetEnvVar() error {
 func (c *Config) ResolveModel(modelInput string) (*models.ModelInfo, error) {
 	if modelInput == "" {
 		// Use configured model
+		if c.Provider == ProviderOpenAICompatible {
+			return c.endpointModel(c.Endpoint, c.Model)
+		}
+		if c.Provider == ProviderAzure {
+			return c.azureModel(c.Model)
+		}
 		if c.Model == "" {
 			// Get default for current provider
 			return models.GetDefaultModel(c.Provider)
 		}
-		return models.FindModel(c.Model)
+		model, err := models.FindModel(c.Model)
+		if err != nil && c.Provider == ProviderOllama {
+			// Any installed Ollama model can be configured, not just registry entries
+			return models.OllamaModel(c.Model), nil
+		}
+		if err != nil && c.Provider == ProviderBedrock {
+			// Any Bedrock model or inference profile ID can be configured
+			return models.BedrockModel(c.Model), nil
+		}
+		return model, err
 	}
 
 	// User specified a model
-	model, err := models.FindModel(modelInput)
+	model, err := c.FindModel(modelInput)
 	if err != nil {
 		return nil, err
 	}
@@ -300,19 +506,27 @@ func (c *Config) ResolveModel(modelInput string) (*models.ModelInfo, error) {
 
 // SetDefaultModel sets the default model in config
 func (c *Config) SetDefaultModel(modelInput string) error {
-	model, err := models.FindModel(modelInput)
+	model, err := c.FindModel(modelInput)
 	if err != nil {
 		return err
 	}
 
 	c.Model = model.ID
 	c.Provider = model.Provider
+	if model.Endpoint != "" {
+		c.Endpoint = model.Endpoint
+	}
 
 	return c.Save()
 }
 
 // EnsureAPIKeyForProvider ensures API key exists for the given provider
 func (c *Config) EnsureAPIKeyForProvider(provider string) error {
+	if !c.NeedsAPIKey(provider) {
+		c.Provider = provider
+		return nil
+	}
+
 	// Temporarily switch provider to check API key
 	originalProvider := c.Provider
 	c.Provider = provider
@@ -343,10 +557,16 @@ func (c *Config) promptForAPIKey(provider string) (string, error) {
 
 	var setupURL string
 	switch provider {
+	case ProviderNovita:
+		setupURL = "Get your API key at: https://novita.ai/settings/key-management"
 	case ProviderGemini:
 		setupURL = "Get your API key at: https://makersuite.google.com/app/apikey"
 	case ProviderOpenRouter:
 		setupURL = "Get your API key at: https://openrouter.ai/keys"
+	case ProviderAnthropic:
+		setupURL = "Get your API key at: https://console.anthropic.com/settings/keys"
+	case ProviderAzure:
+		setupURL = "Find your key in the Azure portal under your Azure OpenAI resource > Keys and Endpoint"
 	default:
 		return "", fmt.Errorf("unknown provider: %s", provider)
 	}
@@ -395,6 +615,75 @@ func setupOpenRouterModel(reader *bufio.Reader) string {
 	return DefaultModels[ProviderOpenRouter]
 }
 
+// setupRegistryModel allows user to select from the registry models of a provider
+func setupRegistryModel(reader *bufio.Reader, provider, displayName string) string {
+	fmt.Printf("\nAvailable %s models:\n", displayName)
+
+	providerModels := models.GetModelsByProvider(provider)
+	for i, model := range providerModels {
+		fmt.Printf("%d. %s (%s) - %dk context - %s\n",
+			i+1, model.Name, model.Company, model.ContextSize/1000, model.Description)
+	}
+
+	fmt.Printf("\nEnter your choice (1-%d): ", len(providerModels))
+	modelChoice, err := reader.ReadString('\n')
+	if err != nil {
+		return DefaultModels[provider]
+	}
+
+	if choice := parseInt(strings.TrimSpace(modelChoice)); choice > 0 && choice <= len(providerModels) {
+		return providerModels[choice-1].ID
+	}
+
+	fmt.Printf("Invalid choice, using default: %s\n", DefaultModels[provider])
+	return DefaultModels[provider]
+}
+
+// setupOllama asks for the Ollama server and lets the user pick an installed model.
+// It returns an empty host when the default should be used.
+func setupOllama(reader *bufio.Reader) (host, model string) {
+	fmt.Printf("\nOllama server URL (press Enter for %s): ", (&Config{}).GetOllamaHost())
+	host, err := reader.ReadString('\n')
+	if err != nil {
+		host = ""
+	}
+	host = strings.TrimSpace(host)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	installed, err := models.FetchOllamaModels(ctx, (&Config{OllamaHost: host}).GetOllamaHost())
+	if err != nil || len(installed) == 0 {
+		if err != nil {
+			fmt.Printf("Could not list installed models: %v\n", err)
+		}
+		fmt.Printf("Model name (press Enter for %s): ", DefaultModels[ProviderOllama])
+		model, err := reader.ReadString('\n')
+		if err != nil || strings.TrimSpace(model) == "" {
+			return host, DefaultModels[ProviderOllama]
+		}
+		return host, strings.TrimSpace(model)
+	}
+
+	fmt.Println("\nInstalled Ollama models:")
+	for i, m := range installed {
+		fmt.Printf("%d. %s - %s\n", i+1, m.Name, m.Description)
+	}
+
+	fmt.Printf("\nEnter your choice (1-%d): ", len(installed))
+	modelChoice, err := reader.ReadString('\n')
+	if err != nil {
+		return host, installed[0].ID
+	}
+
+	if choice := parseInt(strings.TrimSpace(modelChoice)); choice > 0 && choice <= len(installed) {
+		return host, installed[choice-1].ID
+	}
+
+	fmt.Printf("Invalid choice, using %s\n", installed[0].ID)
+	return host, installed[0].ID
+}
+
 // parseInt safely parses an integer string
 func parseInt(s string) int {
 	if s == "" {
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/siddhartha/rune/internal/models"
+	"github.com/zalando/go-keyring"
+)
+
+// Auth styles understood by OpenAI-compatible endpoints
+const (
+	AuthStyleBearer = "bearer"  // Authorization: Bearer <key> (OpenAI, OpenRouter, vLLM, ...)
+	AuthStyleAPIKey = "api-key" // <header>: <key>, the header defaults to "api-key"
+	AuthStyleNone   = "none"    // No authentication (LM Studio, llama.cpp server, ...)
+)
+
+// GetEndpoint returns the named OpenAI-compatible endpoint
+func (c *Config) GetEndpoint(name string) (*Endpoint, error) {
+	if name == "" {
+		return nil, fmt.Errorf("no endpoint selected for the %s provider", ProviderOpenAICompatible)
+	}
+	endpoint, ok := c.Endpoints[name]
+	if !ok || endpoint == nil {
+		return nil, fmt.Errorf("endpoint not configured: %s", name)
+	}
+	return endpoint, nil
+}
+
+// EndpointNames returns the configured endpoint names in sorted order
+func (c *Config) EndpointNames() []string {
+	names := make([]string, 0, len(c.Endpoints))
+	for name := range c.Endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// endpointKeyringUser is the keyring account holding an endpoint's API key
+func endpointKeyringUser(name string) string {
+	return ProviderOpenAICompatible + "/" + name
+}
+
+// GetEndpointAPIKey returns the API key for a named endpoint from its
+// environment variable or secure storage. Endpoints without auth return "".
+func (c *Config) GetEndpointAPIKey(name string) (string, error) {
+	endpoint, err := c.GetEndpoint(name)
+	if err != nil {
+		return "", err
+	}
+	if endpoint.AuthStyle == AuthStyleNone {
+		return "", nil
+	}
+
+	if endpoint.APIKeyEnv != "" {
+		apiKey := os.Getenv(endpoint.APIKeyEnv)
+		if apiKey == "" {
+			return "", fmt.Errorf("%s environment variable is required for endpoint %s", endpoint.APIKeyEnv, name)
+		}
+		return apiKey, nil
+	}
+
+	apiKey, err := keyring.Get("rune-cli", endpointKeyringUser(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve API key from secure storage for endpoint %s: %w", name, err)
+	}
+	return apiKey, nil
+}
+
+// SetEndpointAPIKey stores the API key for a named endpoint in secure storage
+func (c *Config) SetEndpointAPIKey(name, apiKey string) error {
+	if err := keyring.Set("rune-cli", endpointKeyringUser(name), apiKey); err != nil {
+		return fmt.Errorf("failed to store API key in secure storage: %w", err)
+	}
+	return nil
+}
+
+// FindModel finds a model in the registry, an Azure deployment or a configured endpoint.
+// Azure models are written as "azure/<name>" and endpoint models as "<endpoint>/<model>",
+// or just "<endpoint>" for the endpoint's default model.
+func (c *Config) FindModel(query string) (*models.ModelInfo, error) {
+	model, err := models.FindModel(query)
+	if err == nil {
+		return model, nil
+	}
+
+	query = strings.TrimSpace(query)
+	if name, ok := strings.CutPrefix(query, AzurePrefix); ok && c.Azure != nil {
+		return c.azureModel(name)
+	}
+
+	name, modelID, _ := strings.Cut(query, "/")
+	if _, ok := c.Endpoints[name]; ok {
+		return c.endpointModel(name, modelID)
+	}
+
+	return nil, err
+}
+
+// endpointModel returns model info for a model served by a named endpoint
+func (c *Config) endpointModel(name, modelID string) (*models.ModelInfo, error) {
+	endpoint, err := c.GetEndpoint(name)
+	if err != nil {
+		return nil, err
+	}
+	if modelID == "" {
+		modelID = endpoint.Model
+	}
+	if modelID == "" {
+		return nil, fmt.Errorf("model not found: endpoint %s has no default model", name)
+	}
+
+	return &models.ModelInfo{
+		ID:          modelID,
+		ShortName:   name + "/" + modelID,
+		Name:        modelID,
+		Provider:    ProviderOpenAICompatible,
+		Company:     name,
+		Description: "OpenAI-compatible endpoint at " + endpoint.BaseURL,
+		Endpoint:    name,
+
+		StructuredOutput: endpoint.StructuredOutput,
+	}, nil
+}
+
+// setupEndpoint asks for the details of an OpenAI-compatible endpoint
+func setupEndpoint(reader *bufio.Reader) (name string, endpoint *Endpoint, apiKey string, err error) {
+	ask := func(prompt, fallback string) string {
+		fmt.Print(prompt)
+		answer, err := reader.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if err != nil || answer == "" {
+			return fallback
+		}
+		return answer
+	}
+
+	name = ask("\nEndpoint name (press Enter for openai): ", "openai")
+	if strings.Contains(name, "/") {
+		return "", nil, "", fmt.Errorf("endpoint name cannot contain '/': %s", name)
+	}
+
+	endpoint = &Endpoint{
+		BaseURL: ask("Base URL (press Enter for https://api.openai.com/v1): ", "https://api.openai.com/v1"),
+	}
+	endpoint.Model = ask("Model ID (press Enter for gpt-4o-mini): ", "gpt-4o-mini")
+
+	fmt.Println("\nHow does the endpoint authenticate?")
+	fmt.Println("1. Authorization: Bearer <key> (OpenAI, vLLM, most gateways)")
+	fmt.Println("2. api-key: <key> header")
+	fmt.Println("3. No authentication (LM Studio, llama.cpp server, LocalAI)")
+	switch ask("Enter your choice (1-3): ", "1") {
+	case "1":
+		endpoint.AuthStyle = AuthStyleBearer
+	case "2":
+		endpoint.AuthStyle = AuthStyleAPIKey
+	case "3":
+		endpoint.AuthStyle = AuthStyleNone
+		return name, endpoint, "", nil
+	default:
+		return "", nil, "", fmt.Errorf("invalid choice")
+	}
+
+	apiKey = ask(fmt.Sprintf("Please enter the API key for %s: ", name), "")
+	if apiKey == "" {
+		return "", nil, "", fmt.Errorf("API key cannot be empty")
+	}
+	return name, endpoint, apiKey, nil
+}
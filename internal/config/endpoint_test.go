@@ -0,0 +1,97 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestConfig_FindModel_Endpoints(t *testing.T) {
+	cfg := &Config{
+		Endpoints: map[string]*Endpoint{
+			"openai": {BaseURL: "https://api.openai.com/v1", Model: "gpt-4o-mini"},
+			"vllm":   {BaseURL: "http://gpu-box:8000/v1"},
+		},
+	}
+
+	tests := []struct {
+		query        string
+		wantID       string
+		wantProvider string
+		wantEndpoint string
+		wantErr      bool
+	}{
+		{query: "openai/gpt-4o", wantID: "gpt-4o", wantProvider: ProviderOpenAICompatible, wantEndpoint: "openai"},
+		{query: "openai", wantID: "gpt-4o-mini", wantProvider: ProviderOpenAICompatible, wantEndpoint: "openai"},
+		{query: "vllm/meta-llama/Llama-3.1-8B-Instruct", wantID: "meta-llama/Llama-3.1-8B-Instruct", wantProvider: ProviderOpenAICompatible, wantEndpoint: "vllm"},
+		{query: "vllm", wantErr: true}, // no default model
+		{query: "g2", wantID: "gemini-2.0-flash-exp", wantProvider: ProviderGemini},
+		{query: "unknown/model", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			model, err := cfg.FindModel(tt.query)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", model)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if model.ID != tt.wantID || model.Provider != tt.wantProvider || model.Endpoint != tt.wantEndpoint {
+				t.Errorf("FindModel(%q) = %+v", tt.query, model)
+			}
+		})
+	}
+}
+
+func TestConfig_ResolveModel_Endpoint(t *testing.T) {
+	cfg := &Config{
+		Provider: ProviderOpenAICompatible,
+		Endpoint: "openai",
+		Endpoints: map[string]*Endpoint{
+			"openai": {BaseURL: "https://api.openai.com/v1", Model: "gpt-4o-mini"},
+		},
+	}
+
+	model, err := cfg.ResolveModel("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if model.ID != "gpt-4o-mini" || model.Endpoint != "openai" {
+		t.Errorf("Expected endpoint default model, got %+v", model)
+	}
+
+	cfg.Model = "gpt-4o"
+	model, err = cfg.ResolveModel("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if model.ID != "gpt-4o" {
+		t.Errorf("Expected configured model, got %+v", model)
+	}
+}
+
+func TestConfig_GetEndpointAPIKey_Env(t *testing.T) {
+	cfg := &Config{
+		Endpoints: map[string]*Endpoint{
+			"gateway": {BaseURL: "https://llm.internal/v1", APIKeyEnv: "RUNE_TEST_GATEWAY_KEY"},
+			"local":   {BaseURL: "http://localhost:8080/v1", AuthStyle: AuthStyleNone},
+		},
+	}
+
+	t.Setenv("RUNE_TEST_GATEWAY_KEY", "")
+	if _, err := cfg.GetEndpointAPIKey("gateway"); err == nil {
+		t.Error("Expected error when the key variable is empty")
+	}
+
+	t.Setenv("RUNE_TEST_GATEWAY_KEY", "secret")
+	if key, err := cfg.GetEndpointAPIKey("gateway"); err != nil || key != "secret" {
+		t.Errorf("Expected key from environment, got %q, %v", key, err)
+	}
+
+	if key, err := cfg.GetEndpointAPIKey("local"); err != nil || key != "" {
+		t.Errorf("Expected no key for unauthenticated endpoint, got %q, %v", key, err)
+	}
+}
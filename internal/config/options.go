@@ -0,0 +1,61 @@
+package config
+
+import (
+	"sort"
+	"time"
+
+	"github.com/siddhartha/rune/internal/models"
+)
+
+const (
+	// DefaultTimeoutSeconds bounds each generation when timeout_seconds is not set
+	DefaultTimeoutSeconds = 60
+	// DefaultOllamaTimeoutSeconds gives local Ollama models more time, they may first load into memory
+	DefaultOllamaTimeoutSeconds = 120
+)
+
+// GenerationOptions are the sampling and output settings used for a model.
+// Unset fields keep the defaults of rune.
+type GenerationOptions struct {
+	Temperature  *float64 `json:"temperature,omitempty"`
+	TopP         *float64 `json:"top_p,omitempty"`
+	MaxTokens    int      `json:"max_tokens,omitempty"`
+	Seed         *int     `json:"seed,omitempty"`
+	Stop         []string `json:"stop,omitempty"`
+	SystemPrompt string   `json:"system_prompt,omitempty"` // replaces the default system prompt
+}
+
+// GetTimeout returns the time allowed for a generation, including retries
+func (c *Config) GetTimeout() time.Duration {
+	if c.TimeoutSeconds > 0 {
+		return time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	if c.Provider == ProviderOllama {
+		return DefaultOllamaTimeoutSeconds * time.Second
+	}
+	return DefaultTimeoutSeconds * time.Second
+}
+
+// BreakingChangeDetection reports whether exported Go APIs are checked for breaking changes
+func (c *Config) BreakingChangeDetection() bool {
+	return c.DetectBreakingChanges == nil || *c.DetectBreakingChanges
+}
+
+// OptionsFor returns the generation options configured for a model, or nil. Entries of
+// model_options are keyed by any name accepted by --model; the first matching name in
+// sorted order wins.
+func (c *Config) OptionsFor(model *models.ModelInfo) *GenerationOptions {
+	names := make([]string, 0, len(c.ModelOptions))
+	for name := range c.ModelOptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		found, err := c.FindModel(name)
+		if err == nil && found.ID == model.ID && found.Provider == model.Provider {
+			return c.ModelOptions[name]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/siddhartha/rune/internal/models"
+)
+
+func TestConfig_OptionsFor(t *testing.T) {
+	cfg := &Config{
+		Endpoints: map[string]*Endpoint{
+			"vllm": {BaseURL: "http://gpu-box:8000/v1", Model: "llama"},
+		},
+		ModelOptions: map[string]*GenerationOptions{
+			"g2":   {MaxTokens: 100},
+			"vllm": {MaxTokens: 200},
+		},
+	}
+
+	tests := []struct {
+		query         string
+		wantMaxTokens int
+	}{
+		{query: "gemini-2.0-flash-exp", wantMaxTokens: 100}, // full ID of the short name g2
+		{query: "vllm/llama", wantMaxTokens: 200},
+		{query: "vllm/other", wantMaxTokens: 0},
+		{query: "gemini-1.5-pro", wantMaxTokens: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			model, err := cfg.FindModel(tt.query)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			options := cfg.OptionsFor(model)
+			got := 0
+			if options != nil {
+				got = options.MaxTokens
+			}
+			if got != tt.wantMaxTokens {
+				t.Errorf("Expected max tokens %d, got %d", tt.wantMaxTokens, got)
+			}
+		})
+	}
+
+	if options := (&Config{}).OptionsFor(&models.ModelInfo{ID: "m"}); options != nil {
+		t.Errorf("Expected no options without model_options, got %+v", options)
+	}
+}
+
+func TestConfig_GetTimeout(t *testing.T) {
+	if got := (&Config{}).GetTimeout(); got != DefaultTimeoutSeconds*time.Second {
+		t.Errorf("Expected the default timeout, got %v", got)
+	}
+	if got := (&Config{TimeoutSeconds: 90}).GetTimeout(); got != 90*time.Second {
+		t.Errorf("Expected 90s, got %v", got)
+	}
+	if got := (&Config{Provider: ProviderOllama}).GetTimeout(); got != DefaultOllamaTimeoutSeconds*time.Second {
+		t.Errorf("Expected the Ollama default timeout, got %v", got)
+	}
+	if got := (&Config{Provider: ProviderOllama, TimeoutSeconds: 30}).GetTimeout(); got != 30*time.Second {
+		t.Errorf("Expected 30s, got %v", got)
+	}
+}
+
+func TestConfig_BreakingChangeDetection(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name   string
+		detect *bool
+		want   bool
+	}{
+		{name: "default", want: true},
+		{name: "enabled", detect: &enabled, want: true},
+		{name: "disabled", detect: &disabled, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (&Config{DetectBreakingChanges: tt.detect}).BreakingChangeDetection(); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/siddhartha/rune/internal/gcp"
+)
+
+// DefaultVertexLocation is used when neither the config nor GOOGLE_CLOUD_LOCATION sets a location
+const DefaultVertexLocation = "us-central1"
+
+// VertexConfig switches the Gemini provider to Vertex AI. Requests are authenticated
+// with a service-account key instead of a Gemini API key.
+type VertexConfig struct {
+	Project         string `json:"project,omitempty"`          // defaults to GOOGLE_CLOUD_PROJECT or the key's project
+	Location        string `json:"location,omitempty"`         // e.g. "us-central1" or "global"
+	CredentialsFile string `json:"credentials_file,omitempty"` // defaults to GOOGLE_APPLICATION_CREDENTIALS
+}
+
+// Enabled reports whether Gemini requests should go through Vertex AI
+func (v *VertexConfig) Enabled() bool {
+	return v != nil
+}
+
+// GetProject returns the configured project or GOOGLE_CLOUD_PROJECT
+func (v *VertexConfig) GetProject() string {
+	if v != nil && v.Project != "" {
+		return v.Project
+	}
+	return os.Getenv("GOOGLE_CLOUD_PROJECT")
+}
+
+// GetLocation returns the configured location, GOOGLE_CLOUD_LOCATION or us-central1
+func (v *VertexConfig) GetLocation() string {
+	if v != nil && v.Location != "" {
+		return v.Location
+	}
+	if location := os.Getenv("GOOGLE_CLOUD_LOCATION"); location != "" {
+		return location
+	}
+	return DefaultVertexLocation
+}
+
+// GetCredentialsFile returns the service-account key path, expanding a leading "~/"
+func (v *VertexConfig) GetCredentialsFile() string {
+	path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if v != nil && v.CredentialsFile != "" {
+		path = v.CredentialsFile
+	}
+
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + path[1:]
+		}
+	}
+	return path
+}
+
+// NeedsAPIKey reports whether the provider needs a stored API key with this configuration.
+// Gemini through Vertex AI authenticates with a service account instead.
+func (c *Config) NeedsAPIKey(provider string) bool {
+	if provider == ProviderGemini && c.Vertex.Enabled() {
+		return false
+	}
+	return RequiresAPIKey(provider)
+}
+
+// setupVertex asks for the service-account key, project and location
+func setupVertex(reader *bufio.Reader) (*VertexConfig, error) {
+	ask := func(prompt string) string {
+		fmt.Print(prompt)
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(answer)
+	}
+
+	vertex := &VertexConfig{}
+	defaultKey := vertex.GetCredentialsFile()
+	if defaultKey != "" {
+		vertex.CredentialsFile = ask(fmt.Sprintf("Service account key file (press Enter for %s): ", defaultKey))
+	} else {
+		vertex.CredentialsFile = ask("Service account key file (JSON): ")
+	}
+
+	account, err := gcp.LoadServiceAccount(vertex.GetCredentialsFile())
+	if err != nil {
+		return nil, err
+	}
+
+	project := vertex.GetProject()
+	if project == "" {
+		project = account.ProjectID
+	}
+	vertex.Project = ask(fmt.Sprintf("Google Cloud project (press Enter for %s): ", project))
+	if vertex.Project == "" {
+		vertex.Project = project
+	}
+	vertex.Location = ask(fmt.Sprintf("Vertex AI location (press Enter for %s): ", vertex.GetLocation()))
+
+	return vertex, nil
+}
@@ -0,0 +1,37 @@
+package config
+
+import "testing"
+
+func TestVertexConfig(t *testing.T) {
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "env-project")
+	t.Setenv("GOOGLE_CLOUD_LOCATION", "")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "/keys/env.json")
+
+	var unset *VertexConfig
+	if unset.Enabled() {
+		t.Error("Expected nil Vertex config to be disabled")
+	}
+	if unset.GetProject() != "env-project" || unset.GetLocation() != DefaultVertexLocation || unset.GetCredentialsFile() != "/keys/env.json" {
+		t.Errorf("Unexpected defaults: %s %s %s", unset.GetProject(), unset.GetLocation(), unset.GetCredentialsFile())
+	}
+
+	vertex := &VertexConfig{Project: "p", Location: "global", CredentialsFile: "/keys/rune.json"}
+	if vertex.GetProject() != "p" || vertex.GetLocation() != "global" || vertex.GetCredentialsFile() != "/keys/rune.json" {
+		t.Errorf("Unexpected values: %s %s %s", vertex.GetProject(), vertex.GetLocation(), vertex.GetCredentialsFile())
+	}
+}
+
+func TestNeedsAPIKey(t *testing.T) {
+	cfg := &Config{Provider: ProviderGemini}
+	if !cfg.NeedsAPIKey(ProviderGemini) {
+		t.Error("Expected Gemini to need an API key")
+	}
+
+	cfg.Vertex = &VertexConfig{Project: "p"}
+	if cfg.NeedsAPIKey(ProviderGemini) {
+		t.Error("Expected Gemini on Vertex AI not to need an API key")
+	}
+	if !cfg.NeedsAPIKey(ProviderOpenRouter) {
+		t.Error("Expected OpenRouter to still need an API key")
+	}
+}
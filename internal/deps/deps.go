@@ -0,0 +1,245 @@
+package deps
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/siddhartha/rune/internal/commit"
+	"github.com/siddhartha/rune/internal/git"
+)
+
+// Change describes a single dependency update
+type Change struct {
+	Ecosystem Ecosystem
+	Name      string
+	From      string // Empty when the dependency was added
+	To        string // Empty when the dependency was removed
+	Dev       bool   // Development-only dependency
+}
+
+// Verb returns the action performed on the dependency
+func (c Change) Verb() string {
+	switch {
+	case c.From == "":
+		return "add"
+	case c.To == "":
+		return "remove"
+	default:
+		return "bump"
+	}
+}
+
+// String describes the change, e.g. "bump golang.org/x/text from 0.27.0 to 0.28.0"
+func (c Change) String() string {
+	switch c.Verb() {
+	case "add":
+		return fmt.Sprintf("add %s %s", c.Name, displayVersion(c.To))
+	case "remove":
+		return fmt.Sprintf("remove %s %s", c.Name, displayVersion(c.From))
+	default:
+		return fmt.Sprintf("bump %s from %s to %s", c.Name, displayVersion(c.From), displayVersion(c.To))
+	}
+}
+
+// Update is a diff that only touches dependency manifests and lockfiles
+type Update struct {
+	Changes []Change
+}
+
+var (
+	cargoLockNamePattern    = regexp.MustCompile(`^name = "([^"]+)"$`)
+	cargoLockVersionPattern = regexp.MustCompile(`^version = "([^"]+)"$`)
+)
+
+// Detect returns the dependency update described by the diff, or nil if the diff
+// touches anything other than dependency files or no version change could be parsed.
+func Detect(files []*git.FileDiff) *Update {
+	if len(files) == 0 {
+		return nil
+	}
+
+	var changes []Change
+	var cargoLock *git.FileDiff
+
+	for _, file := range files {
+		ecosystem, kind := classify(file.Path())
+		switch kind {
+		case kindNone:
+			return nil
+		case kindLockfile:
+			if ecosystem == EcosystemCargo {
+				cargoLock = file
+			}
+			continue
+		}
+
+		fileChanges, ok := parseManifest(ecosystem, file)
+		if !ok {
+			return nil
+		}
+		changes = append(changes, fileChanges...)
+	}
+
+	// "cargo update" only touches the lockfile
+	if len(changes) == 0 && cargoLock != nil {
+		changes = parseCargoLock(cargoLock)
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return &Update{Changes: changes}
+}
+
+// parseManifest pairs removed and added dependency lines of a manifest.
+// It returns ok=false if any changed line is not a dependency declaration.
+func parseManifest(ecosystem Ecosystem, file *git.FileDiff) ([]Change, bool) {
+	parse := parserFor(ecosystem)
+	removed := make(map[string]*entry)
+	added := make(map[string]*entry)
+	var order []string
+
+	for _, hunk := range file.Hunks {
+		tracker := sectionTracker{ecosystem: ecosystem}
+		for _, line := range hunk.Lines {
+			if line == "" || line[0] == '\\' {
+				continue
+			}
+			prefix, content := line[0], line[1:]
+			if prefix == ' ' {
+				tracker.observe(content)
+				continue
+			}
+
+			e, ok := parse(content, tracker.section)
+			if !ok {
+				return nil, false
+			}
+			tracker.observe(content)
+			if e == nil {
+				continue
+			}
+
+			target := added
+			if prefix == '-' {
+				target = removed
+			}
+			if _, seen := removed[e.name]; !seen {
+				if _, seen := added[e.name]; !seen {
+					order = append(order, e.name)
+				}
+			}
+			target[e.name] = e
+		}
+	}
+
+	var changes []Change
+	for _, name := range order {
+		from, to := removed[name], added[name]
+		change := Change{Ecosystem: ecosystem, Name: name}
+		if from != nil {
+			change.From = from.version
+			change.Dev = from.dev
+		}
+		if to != nil {
+			change.To = to.version
+			change.Dev = to.dev
+		}
+		if change.From == change.To {
+			continue // only formatting or comments changed
+		}
+		changes = append(changes, change)
+	}
+	return changes, true
+}
+
+// parseCargoLock extracts version changes from a Cargo.lock diff, using the
+// preceding name line of each [[package]] entry
+func parseCargoLock(file *git.FileDiff) []Change {
+	var changes []Change
+	for _, hunk := range file.Hunks {
+		var name, from string
+		for _, line := range hunk.Lines {
+			if line == "" {
+				continue
+			}
+			prefix, content := line[0], line[1:]
+			if m := cargoLockNamePattern.FindStringSubmatch(content); m != nil {
+				name, from = m[1], ""
+				continue
+			}
+			m := cargoLockVersionPattern.FindStringSubmatch(content)
+			if m == nil || name == "" {
+				continue
+			}
+			switch prefix {
+			case '-':
+				from = m[1]
+			case '+':
+				if from != "" && from != m[1] {
+					changes = append(changes, Change{Ecosystem: EcosystemCargo, Name: name, From: from, To: m[1]})
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// Message builds the deterministic commit message for the update
+func (u *Update) Message() *commit.Message {
+	scope := "deps"
+	allDev := true
+	for _, change := range u.Changes {
+		allDev = allDev && change.Dev
+	}
+	if allDev {
+		scope = "deps-dev"
+	}
+
+	if len(u.Changes) == 1 {
+		return &commit.Message{Subject: fmt.Sprintf("chore(%s): %s", scope, u.Changes[0])}
+	}
+
+	verb := "bump"
+	for _, change := range u.Changes {
+		if change.Verb() != "bump" {
+			verb = "update"
+			break
+		}
+	}
+
+	return &commit.Message{
+		Subject: fmt.Sprintf("chore(%s): %s %d dependencies", scope, verb, len(u.Changes)),
+		Body:    u.groupedBody(),
+	}
+}
+
+// groupedBody lists the changes, grouped by ecosystem when more than one is involved
+func (u *Update) groupedBody() string {
+	groups := make(map[Ecosystem][]string)
+	var ecosystems []Ecosystem
+	for _, change := range u.Changes {
+		if _, ok := groups[change.Ecosystem]; !ok {
+			ecosystems = append(ecosystems, change.Ecosystem)
+		}
+		groups[change.Ecosystem] = append(groups[change.Ecosystem], "- "+change.String())
+	}
+
+	if len(ecosystems) == 1 {
+		return strings.Join(groups[ecosystems[0]], "\n")
+	}
+
+	sort.Slice(ecosystems, func(i, j int) bool { return ecosystems[i] < ecosystems[j] })
+	var sections []string
+	for _, ecosystem := range ecosystems {
+		sections = append(sections, string(ecosystem)+":\n"+strings.Join(groups[ecosystem], "\n"))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// displayVersion strips range and tag prefixes that carry no information for the reader
+func displayVersion(version string) string {
+	return strings.TrimLeft(version, "v^~=")
+}
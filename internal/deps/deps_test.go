@@ -0,0 +1,211 @@
+package deps
+
+import (
+	"testing"
+
+	"github.com/siddhartha/rune/internal/git"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name        string
+		diff        string
+		wantNil     bool
+		wantMessage string
+	}{
+		{
+			name: "go module bump",
+			diff: `diff --git a/go.mod b/go.mod
+index 1111111..2222222 100644
+--- a/go.mod
++++ b/go.mod
+@@ -5,7 +5,7 @@ go 1.24.3
+ require (
+ 	github.com/spf13/cobra v1.9.1
+ 	github.com/zalando/go-keyring v0.2.6
+-	golang.org/x/text v0.27.0
++	golang.org/x/text v0.28.0
+ )
+diff --git a/go.sum b/go.sum
+index 3333333..4444444 100644
+--- a/go.sum
++++ b/go.sum
+@@ -1,2 +1,2 @@
+-golang.org/x/text v0.27.0 h1:aaa=
+-golang.org/x/text v0.27.0/go.mod h1:bbb=
++golang.org/x/text v0.28.0 h1:ccc=
++golang.org/x/text v0.28.0/go.mod h1:ddd=`,
+			wantMessage: "chore(deps): bump golang.org/x/text from 0.27.0 to 0.28.0",
+		},
+		{
+			name: "grouped go bumps",
+			diff: `diff --git a/go.mod b/go.mod
+--- a/go.mod
++++ b/go.mod
+@@ -5,7 +5,7 @@
+ require (
+-	github.com/spf13/cobra v1.8.0
++	github.com/spf13/cobra v1.9.1
+-	golang.org/x/sys v0.25.0 // indirect
++	golang.org/x/sys v0.26.0 // indirect
+ )`,
+			wantMessage: "chore(deps): bump 2 dependencies\n\n- bump github.com/spf13/cobra from 1.8.0 to 1.9.1\n- bump golang.org/x/sys from 0.25.0 to 0.26.0",
+		},
+		{
+			name: "go directive change is not a dependency update",
+			diff: `diff --git a/go.mod b/go.mod
+--- a/go.mod
++++ b/go.mod
+@@ -1,3 +1,3 @@
+ module example.com/app
+
+-go 1.21
++go 1.22`,
+			wantNil: true,
+		},
+		{
+			name: "npm dev dependency with lockfile",
+			diff: `diff --git a/package.json b/package.json
+--- a/package.json
++++ b/package.json
+@@ -10,6 +10,6 @@
+   "devDependencies": {
+     "eslint": "^8.0.0",
+-    "typescript": "^5.3.3"
++    "typescript": "^5.4.2"
+   }
+diff --git a/package-lock.json b/package-lock.json
+--- a/package-lock.json
++++ b/package-lock.json
+@@ -1 +1 @@
+-{"lockfileVersion": 2}
++{"lockfileVersion": 3}`,
+			wantMessage: "chore(deps-dev): bump typescript from 5.3.3 to 5.4.2",
+		},
+		{
+			name: "npm script change is not a dependency update",
+			diff: `diff --git a/package.json b/package.json
+--- a/package.json
++++ b/package.json
+@@ -3,3 +3,3 @@
+   "scripts": {
+-    "build": "tsc"
++    "build": "tsc -p ."
+   },`,
+			wantNil: true,
+		},
+		{
+			name: "cargo manifest and added crate",
+			diff: `diff --git a/Cargo.toml b/Cargo.toml
+--- a/Cargo.toml
++++ b/Cargo.toml
+@@ -6,3 +6,4 @@
+ [dependencies]
+-serde = { version = "1.0.190", features = ["derive"] }
++serde = { version = "1.0.197", features = ["derive"] }
++anyhow = "1.0"`,
+			wantMessage: "chore(deps): update 2 dependencies\n\n- bump serde from 1.0.190 to 1.0.197\n- add anyhow 1.0",
+		},
+		{
+			name: "cargo package version is not a dependency",
+			diff: `diff --git a/Cargo.toml b/Cargo.toml
+--- a/Cargo.toml
++++ b/Cargo.toml
+@@ -1,3 +1,3 @@
+ [package]
+ name = "app"
+-version = "0.1.0"
++version = "0.2.0"`,
+			wantNil: true,
+		},
+		{
+			name: "cargo lock only",
+			diff: `diff --git a/Cargo.lock b/Cargo.lock
+--- a/Cargo.lock
++++ b/Cargo.lock
+@@ -20,7 +20,7 @@
+ [[package]]
+ name = "itoa"
+-version = "1.0.10"
++version = "1.0.11"
+ source = "registry+https://github.com/rust-lang/crates.io-index"
+-checksum = "aaa"
++checksum = "bbb"`,
+			wantMessage: "chore(deps): bump itoa from 1.0.10 to 1.0.11",
+		},
+		{
+			name: "requirements file",
+			diff: `diff --git a/requirements-dev.txt b/requirements-dev.txt
+--- a/requirements-dev.txt
++++ b/requirements-dev.txt
+@@ -1,2 +1,2 @@
+-Requests==2.31.0
++requests==2.32.3
+ pytest>=7.0`,
+			wantMessage: "chore(deps): bump requests from 2.31.0 to 2.32.3",
+		},
+		{
+			name: "mixed ecosystems",
+			diff: `diff --git a/go.mod b/go.mod
+--- a/go.mod
++++ b/go.mod
+@@ -1,1 +1,1 @@
+-require golang.org/x/text v0.27.0
++require golang.org/x/text v0.28.0
+diff --git a/web/package.json b/web/package.json
+--- a/web/package.json
++++ b/web/package.json
+@@ -1,3 +1,3 @@
+   "dependencies": {
+-    "react": "^18.2.0"
++    "react": "^18.3.1"
+   }`,
+			wantMessage: "chore(deps): bump 2 dependencies\n\nGo modules:\n- bump golang.org/x/text from 0.27.0 to 0.28.0\n\nnpm:\n- bump react from 18.2.0 to 18.3.1",
+		},
+		{
+			name: "source file alongside manifest",
+			diff: `diff --git a/go.mod b/go.mod
+--- a/go.mod
++++ b/go.mod
+@@ -1,1 +1,1 @@
+-require golang.org/x/text v0.27.0
++require golang.org/x/text v0.28.0
+diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,1 +1,1 @@
+-package main
++package main // app`,
+			wantNil: true,
+		},
+		{
+			name: "lockfile only without versions",
+			diff: `diff --git a/go.sum b/go.sum
+--- a/go.sum
++++ b/go.sum
+@@ -1,1 +0,0 @@
+-golang.org/x/text v0.27.0 h1:aaa=`,
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := Detect(git.ParseDiff(tt.diff))
+
+			if tt.wantNil {
+				if update != nil {
+					t.Errorf("Expected no dependency update, got %+v", update.Changes)
+				}
+				return
+			}
+
+			if update == nil {
+				t.Fatal("Expected a dependency update, got nil")
+			}
+			if got := update.Message().Format(); got != tt.wantMessage {
+				t.Errorf("Message() = %q, want %q", got, tt.wantMessage)
+			}
+		})
+	}
+}
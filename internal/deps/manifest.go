@@ -0,0 +1,206 @@
+package deps
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Ecosystem identifies a package manager
+type Ecosystem string
+
+const (
+	EcosystemGo    Ecosystem = "Go modules"
+	EcosystemNPM   Ecosystem = "npm"
+	EcosystemCargo Ecosystem = "Cargo"
+	EcosystemPip   Ecosystem = "pip"
+)
+
+// fileKind tells whether a file declares dependencies or pins them
+type fileKind int
+
+const (
+	kindNone fileKind = iota
+	kindManifest
+	kindLockfile
+)
+
+// entry is a single dependency declaration parsed from a diff line
+type entry struct {
+	name    string
+	version string
+	dev     bool
+}
+
+// lineParser parses one manifest line. It returns ok=false for lines that are
+// neither a dependency nor harmless structure, which means the change is more
+// than a dependency update.
+type lineParser func(line string, section string) (e *entry, ok bool)
+
+var (
+	goRequirePattern     = regexp.MustCompile(`^\s*(?:require\s+)?(\S+)\s+(v\S+)\s*(//.*)?$`)
+	jsonPairPattern      = regexp.MustCompile(`^\s*"([^"]+)"\s*:\s*"([^"]*)"\s*,?\s*$`)
+	jsonSectionPattern   = regexp.MustCompile(`^\s*"([^"]+)"\s*:\s*\{`)
+	tomlStringPattern    = regexp.MustCompile(`^\s*([A-Za-z0-9_-]+)\s*=\s*"([^"]+)"\s*(#.*)?$`)
+	tomlInlinePattern    = regexp.MustCompile(`^\s*([A-Za-z0-9_-]+)\s*=\s*\{.*\bversion\s*=\s*"([^"]+)".*\}\s*(#.*)?$`)
+	tomlSectionPattern   = regexp.MustCompile(`^\s*\[+([^\]]+)\]+\s*$`)
+	requirementPattern   = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9_.\-]*(?:\[[^\]]*\])?)\s*(==|>=|<=|~=|!=|>|<)\s*([^\s;#,]+)`)
+	npmVersionLikePrefix = regexp.MustCompile(`^(\^|~|>=?|<=?|=|\*|v?\d|workspace:|npm:|latest$)`)
+)
+
+// npmDependencySections are the package.json objects that list dependencies
+var npmDependencySections = map[string]bool{
+	"dependencies":         true,
+	"devDependencies":      true,
+	"peerDependencies":     true,
+	"optionalDependencies": true,
+}
+
+// npmReservedKeys are top-level package.json fields whose values can look like versions
+var npmReservedKeys = map[string]bool{
+	"name": true, "version": true, "description": true, "main": true, "module": true,
+	"types": true, "license": true, "private": true, "type": true, "packageManager": true,
+}
+
+// cargoReservedKeys are Cargo.toml [package] fields that look like dependencies
+var cargoReservedKeys = map[string]bool{
+	"name": true, "version": true, "edition": true, "rust-version": true, "license": true,
+	"description": true, "readme": true, "repository": true, "homepage": true, "resolver": true,
+}
+
+// classify returns the ecosystem and kind of a file, or kindNone if it is not a dependency file
+func classify(file string) (Ecosystem, fileKind) {
+	base := path.Base(file)
+	switch {
+	case base == "go.mod":
+		return EcosystemGo, kindManifest
+	case base == "go.sum":
+		return EcosystemGo, kindLockfile
+	case base == "package.json":
+		return EcosystemNPM, kindManifest
+	case base == "package-lock.json", base == "npm-shrinkwrap.json", base == "yarn.lock", base == "pnpm-lock.yaml":
+		return EcosystemNPM, kindLockfile
+	case base == "Cargo.toml":
+		return EcosystemCargo, kindManifest
+	case base == "Cargo.lock":
+		return EcosystemCargo, kindLockfile
+	case strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt"):
+		return EcosystemPip, kindManifest
+	default:
+		return "", kindNone
+	}
+}
+
+// parserFor returns the manifest line parser for an ecosystem
+func parserFor(ecosystem Ecosystem) lineParser {
+	switch ecosystem {
+	case EcosystemGo:
+		return parseGoModLine
+	case EcosystemNPM:
+		return parsePackageJSONLine
+	case EcosystemCargo:
+		return parseCargoTomlLine
+	case EcosystemPip:
+		return parseRequirementsLine
+	default:
+		return nil
+	}
+}
+
+// parseGoModLine parses a require line of go.mod
+func parseGoModLine(line, _ string) (*entry, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || trimmed == ")" || trimmed == "require (" || strings.HasPrefix(trimmed, "//") {
+		return nil, true
+	}
+	m := goRequirePattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil, false
+	}
+	return &entry{name: m[1], version: m[2]}, true
+}
+
+// parsePackageJSONLine parses a dependency line of package.json
+func parsePackageJSONLine(line, section string) (*entry, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.Trim(trimmed, "{},") == "" {
+		return nil, true
+	}
+	if m := jsonSectionPattern.FindStringSubmatch(line); m != nil && npmDependencySections[m[1]] {
+		return nil, true
+	}
+
+	m := jsonPairPattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil, false
+	}
+
+	name, version := m[1], m[2]
+	if npmDependencySections[section] {
+		return &entry{name: name, version: version, dev: section == "devDependencies"}, true
+	}
+	// Outside a known section (hunk context too short): only accept version-like values
+	if section == "" && !npmReservedKeys[name] && npmVersionLikePrefix.MatchString(version) {
+		return &entry{name: name, version: version}, true
+	}
+	return nil, false
+}
+
+// parseCargoTomlLine parses a dependency line of Cargo.toml
+func parseCargoTomlLine(line, section string) (*entry, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return nil, true
+	}
+	if section != "" && !strings.Contains(section, "dependencies") {
+		return nil, false
+	}
+
+	m := tomlInlinePattern.FindStringSubmatch(line)
+	if m == nil {
+		m = tomlStringPattern.FindStringSubmatch(line)
+	}
+	if m == nil || cargoReservedKeys[m[1]] {
+		return nil, false
+	}
+	return &entry{name: m[1], version: m[2], dev: strings.Contains(section, "dev-dependencies")}, true
+}
+
+// parseRequirementsLine parses a line of a pip requirements file
+func parseRequirementsLine(line, _ string) (*entry, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return nil, true
+	}
+	m := requirementPattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil, false
+	}
+	version := m[3]
+	if m[2] != "==" {
+		version = m[2] + version
+	}
+	return &entry{name: strings.ToLower(m[1]), version: version}, true
+}
+
+// sectionTracker follows which manifest section a hunk line belongs to
+type sectionTracker struct {
+	ecosystem Ecosystem
+	section   string
+}
+
+// observe updates the current section from any hunk line, including context lines
+func (t *sectionTracker) observe(content string) {
+	switch t.ecosystem {
+	case EcosystemNPM:
+		if m := jsonSectionPattern.FindStringSubmatch(content); m != nil {
+			t.section = m[1]
+		} else if strings.HasPrefix(strings.TrimSpace(content), "}") {
+			t.section = "<closed>"
+		}
+	case EcosystemCargo:
+		if m := tomlSectionPattern.FindStringSubmatch(content); m != nil {
+			t.section = m[1]
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package gcp authenticates with Google Cloud using service-account keys.
+// It implements the OAuth 2.0 JWT bearer flow with the standard library only:
+// a JWT is signed locally with the account's private key and exchanged for an access token.
+package gcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTokenURL is Google's OAuth 2.0 token endpoint
+	DefaultTokenURL = "https://oauth2.googleapis.com/token"
+	// CloudPlatformScope grants access to Google Cloud APIs such as Vertex AI
+	CloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	jwtBearerGrantType = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	tokenLifetime      = time.Hour
+	// expiryMargin refreshes tokens shortly before they expire
+	expiryMargin = time.Minute
+)
+
+// ServiceAccount holds the fields rune needs from a service-account JSON key file
+type ServiceAccount struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// LoadServiceAccount reads a service-account JSON key file
+func LoadServiceAccount(path string) (*ServiceAccount, error) {
+	if path == "" {
+		return nil, fmt.Errorf("failed to load Google service account: no key file configured (set GOOGLE_APPLICATION_CREDENTIALS)")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Google service account: %w", err)
+	}
+
+	account, err := ParseServiceAccount(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Google service account from %s: %w", path, err)
+	}
+	return account, nil
+}
+
+// ParseServiceAccount parses a service-account JSON key
+func ParseServiceAccount(data []byte) (*ServiceAccount, error) {
+	var account ServiceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("invalid key file: %w", err)
+	}
+	if account.Type != "service_account" {
+		return nil, fmt.Errorf("expected a service_account key, got type %q", account.Type)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("key file is missing client_email or private_key")
+	}
+	return &account, nil
+}
+
+// TokenSource issues access tokens for a service account and caches them until shortly before expiry
+type TokenSource struct {
+	// TokenURL is the OAuth 2.0 token endpoint. It defaults to the key file's token_uri.
+	TokenURL string
+	// Scope is the space-separated list of OAuth scopes to request
+	Scope string
+
+	account    *ServiceAccount
+	key        *rsa.PrivateKey
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewTokenSource creates a TokenSource for the cloud-platform scope
+func NewTokenSource(account *ServiceAccount) (*TokenSource, error) {
+	key, err := parsePrivateKey(account.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse service account private key: %w", err)
+	}
+
+	tokenURL := account.TokenURI
+	if tokenURL == "" {
+		tokenURL = DefaultTokenURL
+	}
+
+	return &TokenSource{
+		TokenURL: tokenURL,
+		Scope:    CloudPlatformScope,
+		account:  account,
+		key:      key,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		now: time.Now,
+	}, nil
+}
+
+// Token returns a valid access token, fetching a new one when the cached token is about to expire
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Before(s.expiry.Add(-expiryMargin)) {
+		return s.token, nil
+	}
+
+	assertion, err := s.assertion()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", jwtBearerGrantType)
+	form.Set("assertion", assertion)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch Google access token: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close response body: %v\n", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		if token.Error != "" {
+			return "", fmt.Errorf("failed to fetch Google access token: %s: %s", token.Error, token.ErrorDescription)
+		}
+		return "", fmt.Errorf("failed to fetch Google access token: status %d: %s", resp.StatusCode, string(body))
+	}
+
+	s.token = token.AccessToken
+	s.expiry = s.now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return s.token, nil
+}
+
+// assertion builds the RS256-signed JWT that is exchanged for an access token
+func (s *TokenSource) assertion() (string, error) {
+	now := s.now()
+
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	if s.account.PrivateKeyID != "" {
+		header["kid"] = s.account.PrivateKeyID
+	}
+	claims := map[string]interface{}{
+		"iss":   s.account.ClientEmail,
+		"scope": s.Scope,
+		"aud":   s.TokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(tokenLifetime).Unix(),
+	}
+
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWT header: %w", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWT claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey decodes a PEM encoded PKCS#8 or PKCS#1 RSA private key
+func parsePrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,196 @@
+package gcp
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testServiceAccount returns a service account with a freshly generated key
+func testServiceAccount(t *testing.T) (*ServiceAccount, *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	return &ServiceAccount{
+		Type:         "service_account",
+		ProjectID:    "my-project",
+		PrivateKeyID: "key-1",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail:  "rune@my-project.iam.gserviceaccount.com",
+	}, key
+}
+
+func TestTokenSource_Token(t *testing.T) {
+	account, key := testServiceAccount(t)
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("Failed to parse form: %v", err)
+		}
+		if r.Form.Get("grant_type") != jwtBearerGrantType {
+			t.Errorf("Unexpected grant_type %q", r.Form.Get("grant_type"))
+		}
+
+		// Verify the JWT signature and claims
+		parts := strings.Split(r.Form.Get("assertion"), ".")
+		if len(parts) != 3 {
+			t.Fatalf("Expected a three part JWT, got %d parts", len(parts))
+		}
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil {
+			t.Fatalf("Failed to decode signature: %v", err)
+		}
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			t.Errorf("Invalid JWT signature: %v", err)
+		}
+
+		claimsJSON, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var claims map[string]interface{}
+		if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+			t.Fatalf("Failed to decode claims: %v", err)
+		}
+		if claims["iss"] != account.ClientEmail || claims["scope"] != CloudPlatformScope || claims["aud"] != "http://"+r.Host+"/token" {
+			t.Errorf("Unexpected claims: %v", claims)
+		}
+		if claims["exp"].(float64)-claims["iat"].(float64) != 3600 {
+			t.Errorf("Expected a one hour assertion, got %v", claims)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(`{"access_token": "ya29.test", "expires_in": 3599, "token_type": "Bearer"}`)); err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	source, err := NewTokenSource(account)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if source.TokenURL != DefaultTokenURL {
+		t.Errorf("Expected default token URL, got %s", source.TokenURL)
+	}
+	source.TokenURL = server.URL + "/token"
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	source.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if token != "ya29.test" {
+			t.Errorf("Expected token ya29.test, got %s", token)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected the token to be cached, got %d requests", requests)
+	}
+
+	// Refresh shortly before expiry
+	now = now.Add(59 * time.Minute)
+	if _, err := source.Token(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected a refresh, got %d requests", requests)
+	}
+}
+
+func TestTokenSource_TokenError(t *testing.T) {
+	account, _ := testServiceAccount(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		if _, err := w.Write([]byte(`{"error": "invalid_grant", "error_description": "Invalid JWT Signature."}`)); err != nil {
+			t.Errorf("Failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	source, err := NewTokenSource(account)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	source.TokenURL = server.URL
+
+	_, err = source.Token(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant: Invalid JWT Signature.") {
+		t.Errorf("Expected invalid_grant error, got %v", err)
+	}
+}
+
+func TestLoadServiceAccount(t *testing.T) {
+	account, _ := testServiceAccount(t)
+	account.TokenURI = "https://oauth2.example.com/token"
+	data, _ := json.Marshal(account)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "key.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	loaded, err := LoadServiceAccount(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if loaded.ProjectID != "my-project" || loaded.ClientEmail != account.ClientEmail {
+		t.Errorf("Unexpected account: %+v", loaded)
+	}
+
+	source, err := NewTokenSource(loaded)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if source.TokenURL != account.TokenURI {
+		t.Errorf("Expected token_uri from key file, got %s", source.TokenURL)
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "not json", data: "nope"},
+		{name: "user credentials", data: `{"type": "authorized_user", "client_id": "x"}`},
+		{name: "missing key", data: `{"type": "service_account", "client_email": "a@b"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseServiceAccount([]byte(tt.data)); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+
+	if _, err := LoadServiceAccount(""); err == nil || !strings.Contains(err.Error(), "GOOGLE_APPLICATION_CREDENTIALS") {
+		t.Errorf("Expected hint about GOOGLE_APPLICATION_CREDENTIALS, got %v", err)
+	}
+}
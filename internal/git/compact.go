@@ -0,0 +1,170 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// generatedFiles are lock files whose content says little about a change
+var generatedFiles = map[string]bool{
+	"go.sum":              true,
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"yarn.lock":           true,
+	"pnpm-lock.yaml":      true,
+	"Cargo.lock":          true,
+	"poetry.lock":         true,
+	"Gemfile.lock":        true,
+	"composer.lock":       true,
+}
+
+// CompactDiff shrinks a diff towards maxBytes while keeping every file in it. It drops context
+// lines, replaces the content of lock and minified files with a summary, and finally truncates
+// the hunks of the largest files. File headers are always kept, so the result can still exceed
+// maxBytes for diffs touching very many files.
+func CompactDiff(diff string, maxBytes int) string {
+	files := ParseDiff(diff)
+	if len(files) == 0 {
+		return diff
+	}
+
+	rendered := make([]string, len(files))
+	total := 0
+	for i, file := range files {
+		rendered[i] = compactFile(file).String()
+		total += len(rendered[i])
+	}
+	if total <= maxBytes {
+		return strings.Join(rendered, "")
+	}
+
+	// Share the budget so that small files stay complete and large ones get the rest equally
+	order := make([]int, len(files))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return len(rendered[order[a]]) < len(rendered[order[b]])
+	})
+
+	budget := maxBytes
+	for n, i := range order {
+		share := budget / (len(order) - n)
+		if len(rendered[i]) > share {
+			rendered[i] = truncateFile(compactFile(files[i]), share)
+		}
+		budget -= len(rendered[i])
+	}
+	return strings.Join(rendered, "")
+}
+
+// compactFile returns the file without context lines, or only its header and a summary
+// for generated files
+func compactFile(file *FileDiff) *FileDiff {
+	compacted := *file
+	compacted.Hunks = nil
+
+	// Generated files already summarized by an earlier pass have no lines left to count
+	if added, removed := file.Stats(); isGeneratedFile(file.Path()) && added+removed > 0 {
+		compacted.Hunks = []Hunk{{
+			Header: fmt.Sprintf("@@ generated file: %d lines added, %d removed, content omitted @@", added, removed),
+		}}
+		return &compacted
+	}
+
+	for _, hunk := range file.Hunks {
+		kept := Hunk{Header: hunk.Header}
+		for _, line := range hunk.Lines {
+			if strings.HasPrefix(line, " ") {
+				continue
+			}
+			kept.Lines = append(kept.Lines, line)
+		}
+		compacted.Hunks = append(compacted.Hunks, kept)
+	}
+	return &compacted
+}
+
+// truncateFile renders a file with as many hunk lines as fit in maxBytes,
+// followed by a note with the number of omitted lines
+func truncateFile(file *FileDiff, maxBytes int) string {
+	// Leave room for the note, which is never longer than with all lines omitted
+	total := 0
+	for _, hunk := range file.Hunks {
+		total += len(hunk.Lines)
+	}
+	maxBytes -= len(omittedNote(total))
+
+	var b strings.Builder
+	for _, line := range file.Header {
+		b.WriteString(line + "\n")
+	}
+
+	omitted := 0
+	for _, hunk := range file.Hunks {
+		if omitted > 0 || b.Len()+len(hunk.Header)+1 > maxBytes {
+			omitted += len(hunk.Lines)
+			continue
+		}
+		b.WriteString(hunk.Header + "\n")
+		for i, line := range hunk.Lines {
+			if b.Len()+len(line)+1 > maxBytes {
+				omitted += len(hunk.Lines) - i
+				break
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	if omitted > 0 {
+		b.WriteString(omittedNote(omitted))
+	}
+	return b.String()
+}
+
+// omittedNote tells the model how many lines of a file were left out
+func omittedNote(lines int) string {
+	return fmt.Sprintf("... (%d more lines omitted)\n", lines)
+}
+
+// isGeneratedFile reports whether a path is a lock file or minified asset
+func isGeneratedFile(file string) bool {
+	base := path.Base(file)
+	return generatedFiles[base] || strings.HasSuffix(base, ".min.js") || strings.HasSuffix(base, ".min.css")
+}
+
+// DiffPart is a group of file diffs small enough to be described on its own
+type DiffPart struct {
+	Paths []string
+	Diff  string
+}
+
+// SplitDiff splits a diff into parts of at most maxBytes. Files are ordered by path so that
+// files of the same directory end up in the same part where they fit; a file larger than
+// maxBytes becomes a part of its own and is compacted.
+func SplitDiff(diff string, maxBytes int) []DiffPart {
+	files := ParseDiff(diff)
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Path() < files[j].Path()
+	})
+
+	var parts []DiffPart
+	var current DiffPart
+	for _, file := range files {
+		text := file.String()
+		if len(text) > maxBytes {
+			text = CompactDiff(text, maxBytes)
+		}
+		if current.Diff != "" && len(current.Diff)+len(text) > maxBytes {
+			parts = append(parts, current)
+			current = DiffPart{}
+		}
+		current.Paths = append(current.Paths, file.Path())
+		current.Diff += text
+	}
+	if current.Diff != "" {
+		parts = append(parts, current)
+	}
+	return parts
+}
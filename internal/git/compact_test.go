@@ -0,0 +1,122 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const compactableDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,5 +1,5 @@
+ package main
+
+-func old() {}
++func renamed() {}
+
+diff --git a/go.sum b/go.sum
+index 3333333..4444444 100644
+--- a/go.sum
++++ b/go.sum
+@@ -1,2 +1,3 @@
+ example.com/a v1.0.0 h1:aaa=
+-example.com/b v1.0.0 h1:bbb=
++example.com/b v1.1.0 h1:ccc=
++example.com/c v1.0.0 h1:ddd=
+`
+
+func TestCompactDiff_DropsContextAndSummarizesLockFiles(t *testing.T) {
+	compacted := CompactDiff(compactableDiff, 1000)
+
+	tests := []struct {
+		name     string
+		text     string
+		expected bool
+	}{
+		{"keeps file headers", "diff --git a/main.go b/main.go", true},
+		{"keeps hunk headers", "@@ -1,5 +1,5 @@", true},
+		{"keeps changed lines", "+func renamed() {}", true},
+		{"drops context lines", " package main", false},
+		{"keeps lock file header", "diff --git a/go.sum b/go.sum", true},
+		{"summarizes lock file", "@@ generated file: 2 lines added, 1 removed, content omitted @@", true},
+		{"drops lock file content", "example.com/b v1.1.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if strings.Contains(compacted, tt.text) != tt.expected {
+				t.Errorf("Expected contains(%q) = %v in:\n%s", tt.text, tt.expected, compacted)
+			}
+		})
+	}
+
+	// A second pass keeps the lock file summary
+	if again := CompactDiff(compacted, 1000); again != compacted {
+		t.Errorf("Expected compacting twice to be stable, got:\n%s", again)
+	}
+}
+
+func TestCompactDiff_TruncatesLargestFiles(t *testing.T) {
+	var lines []string
+	for i := 0; i < 1000; i++ {
+		lines = append(lines, fmt.Sprintf("+generated line %d", i))
+	}
+	large := "diff --git a/big.go b/big.go\n--- a/big.go\n+++ b/big.go\n@@ -0,0 +1,1000 @@\n" + strings.Join(lines, "\n") + "\n"
+	small := "diff --git a/small.go b/small.go\n--- a/small.go\n+++ b/small.go\n@@ -1 +1 @@\n-a\n+b\n"
+
+	compacted := CompactDiff(large+small, 2000)
+
+	if len(compacted) > 2000 {
+		t.Errorf("Expected at most 2000 bytes, got %d", len(compacted))
+	}
+	if !strings.Contains(compacted, small) {
+		t.Errorf("Expected the small file to stay complete:\n%s", compacted)
+	}
+	if !strings.Contains(compacted, "+generated line 0\n") {
+		t.Error("Expected the start of the large file to be kept")
+	}
+	if !strings.Contains(compacted, "more lines omitted)") {
+		t.Errorf("Expected a note about omitted lines:\n%s", compacted)
+	}
+	if strings.Contains(compacted, "+generated line 999") {
+		t.Error("Expected the end of the large file to be truncated")
+	}
+}
+
+func TestCompactDiff_FitsUnchanged(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-a\n+b\n"
+
+	if compacted := CompactDiff(diff, 10_000); compacted != diff {
+		t.Errorf("Expected a diff without context to stay the same, got:\n%s", compacted)
+	}
+}
+
+func TestSplitDiff(t *testing.T) {
+	file := func(path string, lines int) string {
+		text := fmt.Sprintf("diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n@@ -0,0 +1,%d @@\n", path, path, path, path, lines)
+		for i := 0; i < lines; i++ {
+			text += fmt.Sprintf("+line %d\n", i)
+		}
+		return text
+	}
+	diff := file("web/app.js", 3) + file("api/b.go", 3) + file("api/a.go", 3) + file("big/data.go", 200)
+
+	parts := SplitDiff(diff, 300)
+
+	var paths [][]string
+	for _, part := range parts {
+		paths = append(paths, part.Paths)
+		if len(part.Diff) > 300 {
+			t.Errorf("Expected parts of at most 300 bytes, got %d for %v", len(part.Diff), part.Paths)
+		}
+	}
+	expected := [][]string{{"api/a.go", "api/b.go"}, {"big/data.go"}, {"web/app.js"}}
+	if fmt.Sprint(paths) != fmt.Sprint(expected) {
+		t.Errorf("Expected parts %v, got %v", expected, paths)
+	}
+	if !strings.Contains(parts[1].Diff, "more lines omitted") {
+		t.Errorf("Expected the oversized file to be compacted:\n%s", parts[1].Diff)
+	}
+}
@@ -3,6 +3,7 @@ package git
 import (
 	"fmt"
 	"os/exec"
+	"path"
 	"strings"
 	"sync"
 )
@@ -21,15 +22,7 @@ func WithGitLock(fn func() error) error {
 // If staged is true, it returns the staged changes (--cached).
 // If staged is false, it returns all changes including unstaged.
 func ExtractDiff(staged bool) (string, error) {
-	var cmd *exec.Cmd
-
-	if staged {
-		// Get only staged changes
-		cmd = exec.Command("git", "diff", "--cached")
-	} else {
-		// Get all changes (staged + unstaged) relative to HEAD
-		cmd = exec.Command("git", "diff", "HEAD")
-	}
+	cmd := exec.Command("git", diffArgs(staged)...)
 
 	output, err := cmd.Output()
 	if err != nil {
@@ -44,6 +37,28 @@ func ExtractDiff(staged bool) (string, error) {
 	return diff, nil
 }
 
+// ExtractDiffIgnoringWhitespace returns the same diff as ExtractDiff but ignoring
+// whitespace and blank line changes. Unlike ExtractDiff it returns an empty string
+// rather than an error when nothing but whitespace changed.
+func ExtractDiffIgnoringWhitespace(staged bool) (string, error) {
+	args := append(diffArgs(staged), "-w", "--ignore-blank-lines")
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to execute git diff: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// diffArgs returns the git arguments selecting staged or all changes
+func diffArgs(staged bool) []string {
+	if staged {
+		// Get only staged changes
+		return []string{"diff", "--cached"}
+	}
+	// Get all changes (staged + unstaged) relative to HEAD
+	return []string{"diff", "HEAD"}
+}
+
 // ListStagedFiles returns a slice of file paths that are currently staged for commit.
 func ListStagedFiles() ([]string, error) {
 	cmd := exec.Command("git", "diff", "--cached", "--name-only")
@@ -125,3 +140,69 @@ func AtomicStageAll() (*AtomicStageResult, error) {
 
 	return &result, nil
 }
+
+// ChangedFiles returns the paths touched by the diff that ExtractDiff would produce
+// for the same staged setting.
+func ChangedFiles(staged bool) ([]string, error) {
+	var cmd *exec.Cmd
+
+	if staged {
+		cmd = exec.Command("git", "diff", "--cached", "--name-only", "-z")
+	} else {
+		cmd = exec.Command("git", "diff", "HEAD", "--name-only", "-z")
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+	return splitNul(output), nil
+}
+
+// ListFilesAt returns the files directly inside dir at the given revision.
+// An empty revision lists the files in the index.
+func ListFilesAt(rev, dir string) ([]string, error) {
+	var cmd *exec.Cmd
+
+	if rev == "" {
+		cmd = exec.Command("git", "ls-files", "-z", "--", dir+"/")
+	} else {
+		cmd = exec.Command("git", "ls-tree", "-r", "--name-only", "-z", rev, "--", dir+"/")
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, file := range splitNul(output) {
+		if path.Dir(file) == dir {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// splitNul splits the NUL-terminated paths printed by git with -z, which keeps
+// spaces and other special characters in paths intact
+func splitNul(output []byte) []string {
+	var paths []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			paths = append(paths, file)
+		}
+	}
+	return paths
+}
+
+// ShowFile returns the content of a file at the given revision.
+// An empty revision reads the staged version from the index.
+func ShowFile(rev, file string) ([]byte, error) {
+	cmd := exec.Command("git", "show", rev+":"+file)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %q: %w", file, rev, err)
+	}
+	return output, nil
+}
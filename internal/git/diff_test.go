@@ -135,6 +135,34 @@ func main() {
 			t.Errorf("Expected diff to contain the added line")
 		}
 	})
+
+	t.Run("ChangedFiles keeps spaces in paths", func(t *testing.T) {
+		if err := os.MkdirAll("my pkg", 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join("my pkg", "a file.go"), []byte("package pkg\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := exec.Command("git", "add", "my pkg").Run(); err != nil {
+			t.Fatalf("Failed to add file: %v", err)
+		}
+
+		files, err := ChangedFiles(true)
+		if err != nil {
+			t.Fatalf("ChangedFiles(true) returned error: %v", err)
+		}
+		if len(files) != 1 || files[0] != "my pkg/a file.go" {
+			t.Errorf("Expected [my pkg/a file.go], got %q", files)
+		}
+
+		listed, err := ListFilesAt("", "my pkg")
+		if err != nil {
+			t.Fatalf("ListFilesAt returned error: %v", err)
+		}
+		if len(listed) != 1 || listed[0] != "my pkg/a file.go" {
+			t.Errorf("Expected [my pkg/a file.go], got %q", listed)
+		}
+	})
 }
 
 func TestExtractDiffWithSampleData(t *testing.T) {
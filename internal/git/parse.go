@@ -0,0 +1,152 @@
+package git
+
+import (
+	"strings"
+)
+
+// FileStatus describes what happened to a file in a diff
+type FileStatus string
+
+const (
+	StatusAdded    FileStatus = "added"
+	StatusDeleted  FileStatus = "deleted"
+	StatusModified FileStatus = "modified"
+	StatusRenamed  FileStatus = "renamed"
+)
+
+// FileDiff represents the changes to a single file in a unified diff
+type FileDiff struct {
+	OldPath string
+	NewPath string
+	Status  FileStatus
+	Binary  bool
+	Header  []string // Lines from "diff --git" up to the first hunk
+	Hunks   []Hunk
+}
+
+// Hunk represents a single "@@" section of a file diff
+type Hunk struct {
+	Header string   // The "@@ -a,b +c,d @@" line
+	Lines  []string // Diff lines including their ' ', '+' or '-' prefix
+}
+
+// Path returns the path that best identifies the file: the new path, or the old one if deleted
+func (f *FileDiff) Path() string {
+	if f.Status == StatusDeleted {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// AddedLines returns the content of lines added by the diff, without the '+' prefix
+func (f *FileDiff) AddedLines() []string {
+	return f.linesWithPrefix('+')
+}
+
+// RemovedLines returns the content of lines removed by the diff, without the '-' prefix
+func (f *FileDiff) RemovedLines() []string {
+	return f.linesWithPrefix('-')
+}
+
+// Stats returns the number of added and removed lines
+func (f *FileDiff) Stats() (added, removed int) {
+	return len(f.AddedLines()), len(f.RemovedLines())
+}
+
+// String renders the file diff back to unified diff text
+func (f *FileDiff) String() string {
+	var b strings.Builder
+	for _, line := range f.Header {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	for _, hunk := range f.Hunks {
+		b.WriteString(hunk.Header)
+		b.WriteString("\n")
+		for _, line := range hunk.Lines {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// linesWithPrefix collects hunk lines starting with prefix
+func (f *FileDiff) linesWithPrefix(prefix byte) []string {
+	var lines []string
+	for _, hunk := range f.Hunks {
+		for _, line := range hunk.Lines {
+			if len(line) > 0 && line[0] == prefix {
+				lines = append(lines, line[1:])
+			}
+		}
+	}
+	return lines
+}
+
+// ParseDiff splits the output of git diff into per-file structures
+func ParseDiff(diff string) []*FileDiff {
+	var files []*FileDiff
+	var current *FileDiff
+	var hunk *Hunk
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current = &FileDiff{Status: StatusModified, Header: []string{line}}
+			current.OldPath, current.NewPath = parseGitHeaderPaths(line)
+			files = append(files, current)
+			hunk = nil
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			current.Hunks = append(current.Hunks, Hunk{Header: line})
+			hunk = &current.Hunks[len(current.Hunks)-1]
+		case hunk != nil:
+			if line == "" {
+				continue // trailing newline of the diff output
+			}
+			hunk.Lines = append(hunk.Lines, line)
+		default:
+			current.Header = append(current.Header, line)
+			parseHeaderLine(current, line)
+		}
+	}
+
+	return files
+}
+
+// parseHeaderLine updates file metadata from an extended header line
+func parseHeaderLine(f *FileDiff, line string) {
+	switch {
+	case strings.HasPrefix(line, "new file mode"):
+		f.Status = StatusAdded
+	case strings.HasPrefix(line, "deleted file mode"):
+		f.Status = StatusDeleted
+	case strings.HasPrefix(line, "rename from "):
+		f.Status = StatusRenamed
+		f.OldPath = strings.TrimPrefix(line, "rename from ")
+	case strings.HasPrefix(line, "rename to "):
+		f.Status = StatusRenamed
+		f.NewPath = strings.TrimPrefix(line, "rename to ")
+	case strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch":
+		f.Binary = true
+	case strings.HasPrefix(line, "--- "):
+		if p := strings.TrimPrefix(line, "--- "); p != "/dev/null" {
+			f.OldPath = strings.TrimPrefix(p, "a/")
+		}
+	case strings.HasPrefix(line, "+++ "):
+		if p := strings.TrimPrefix(line, "+++ "); p != "/dev/null" {
+			f.NewPath = strings.TrimPrefix(p, "b/")
+		}
+	}
+}
+
+// parseGitHeaderPaths extracts the paths from a "diff --git a/x b/y" line
+func parseGitHeaderPaths(line string) (oldPath, newPath string) {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.Index(rest, " b/"); i >= 0 {
+		return strings.TrimPrefix(rest[:i], "a/"), rest[i+len(" b/"):]
+	}
+	return rest, rest
+}
@@ -0,0 +1,93 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDiff(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("..", "..", "testdata", "sample.diff"))
+	if err != nil {
+		t.Fatalf("Failed to read sample diff file: %v", err)
+	}
+
+	files := ParseDiff(string(content))
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+
+	main := files[0]
+	if main.Path() != "main.go" || main.Status != StatusModified {
+		t.Errorf("Unexpected first file: path=%s status=%s", main.Path(), main.Status)
+	}
+	added, removed := main.Stats()
+	if added != 4 || removed != 1 {
+		t.Errorf("Stats() = (%d, %d), want (4, 1)", added, removed)
+	}
+
+	readme := files[1]
+	if readme.Path() != "README.md" || readme.Status != StatusAdded {
+		t.Errorf("Unexpected second file: path=%s status=%s", readme.Path(), readme.Status)
+	}
+	if readme.OldPath != "README.md" {
+		t.Errorf("Expected old path to fall back to the git header, got %q", readme.OldPath)
+	}
+
+	// Rendering must round-trip
+	var rendered strings.Builder
+	for _, file := range files {
+		rendered.WriteString(file.String())
+	}
+	if strings.TrimSpace(rendered.String()) != strings.TrimSpace(string(content)) {
+		t.Errorf("String() did not round-trip:\n%s", rendered.String())
+	}
+}
+
+func TestParseDiff_ExtendedHeaders(t *testing.T) {
+	diff := `diff --git a/old.go b/new.go
+similarity index 90%
+rename from old.go
+rename to new.go
+index 1111111..2222222 100644
+--- a/old.go
++++ b/new.go
+@@ -1,3 +1,3 @@
+ package main
+-var x = 1
++var x = 2
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+index 3333333..0000000
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+diff --git a/logo.png b/logo.png
+index 4444444..5555555 100644
+Binary files a/logo.png and b/logo.png differ`
+
+	files := ParseDiff(diff)
+	if len(files) != 3 {
+		t.Fatalf("Expected 3 files, got %d", len(files))
+	}
+
+	if files[0].Status != StatusRenamed || files[0].OldPath != "old.go" || files[0].NewPath != "new.go" {
+		t.Errorf("Unexpected rename: %+v", files[0])
+	}
+	if got := files[0].AddedLines(); len(got) != 1 || got[0] != "var x = 2" {
+		t.Errorf("AddedLines() = %v", got)
+	}
+
+	if files[1].Status != StatusDeleted || files[1].Path() != "gone.txt" {
+		t.Errorf("Unexpected deletion: %+v", files[1])
+	}
+	if got := files[1].RemovedLines(); len(got) != 1 || got[0] != "bye" {
+		t.Errorf("RemovedLines() = %v", got)
+	}
+
+	if !files[2].Binary || len(files[2].Hunks) != 0 {
+		t.Errorf("Expected binary file without hunks: %+v", files[2])
+	}
+}
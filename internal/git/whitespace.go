@@ -0,0 +1,128 @@
+package git
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WhitespaceAnalysis describes which parts of a diff only change whitespace
+type WhitespaceAnalysis struct {
+	OnlyWhitespace bool     // The whole diff is whitespace or blank line changes
+	Files          []string // Files containing whitespace-only hunks
+	ExcludedHunks  int      // Number of whitespace-only hunks
+	FilteredDiff   string   // The diff without whitespace-only hunks
+}
+
+// HasWhitespaceHunks reports whether any hunk only changes whitespace
+func (w *WhitespaceAnalysis) HasWhitespaceHunks() bool {
+	return w.ExcludedHunks > 0
+}
+
+// AnalyzeWhitespace compares the diff against git's whitespace-insensitive diff
+// to find formatting-only changes. staged must match the value used to produce diff.
+func AnalyzeWhitespace(staged bool, diff string) (*WhitespaceAnalysis, error) {
+	ignoringWhitespace, err := ExtractDiffIgnoringWhitespace(staged)
+	if err != nil {
+		return nil, err
+	}
+	return analyzeWhitespace(diff, ignoringWhitespace), nil
+}
+
+// analyzeWhitespace splits diff into formatting-only and substantive hunks. Each hunk
+// is checked on its own because git's whitespace-insensitive diff also ignores
+// whitespace inside string literals; the whole diff is only formatting when both agree.
+func analyzeWhitespace(diff, ignoringWhitespace string) *WhitespaceAnalysis {
+	files := ParseDiff(diff)
+	analysis := &WhitespaceAnalysis{}
+
+	var filtered strings.Builder
+	for _, file := range files {
+		// Binary, mode-only, added, deleted and renamed files are never formatting-only
+		if file.Binary || len(file.Hunks) == 0 || file.Status != StatusModified {
+			filtered.WriteString(file.String())
+			continue
+		}
+
+		kept := *file
+		kept.Hunks = nil
+		for _, hunk := range file.Hunks {
+			if isWhitespaceOnlyHunk(hunk) {
+				analysis.ExcludedHunks++
+				continue
+			}
+			kept.Hunks = append(kept.Hunks, hunk)
+		}
+
+		if len(kept.Hunks) < len(file.Hunks) {
+			analysis.Files = append(analysis.Files, file.Path())
+		}
+		if len(kept.Hunks) > 0 {
+			filtered.WriteString(kept.String())
+		}
+	}
+
+	analysis.FilteredDiff = strings.TrimSpace(filtered.String())
+	analysis.OnlyWhitespace = strings.TrimSpace(ignoringWhitespace) == "" && len(files) > 0 && analysis.FilteredDiff == ""
+	return analysis
+}
+
+// isWhitespaceOnlyHunk reports whether the removed and added lines of a hunk are
+// identical once whitespace between tokens and blank lines are ignored
+func isWhitespaceOnlyHunk(hunk Hunk) bool {
+	var removed, added []string
+	for _, line := range hunk.Lines {
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case '-':
+			removed = append(removed, line[1:])
+		case '+':
+			added = append(added, line[1:])
+		}
+	}
+
+	// Comparing whole sides tolerates lines being split or merged by a formatter
+	return stripWhitespace(strings.Join(removed, "\n")) == stripWhitespace(strings.Join(added, "\n"))
+}
+
+// stripWhitespace removes the whitespace of text outside string, rune and raw string
+// literals. Quoted and rune literals end at the end of a line, backquoted ones may span
+// lines. An apostrophe after a letter or digit, as in "don't", does not start a literal.
+func stripWhitespace(text string) string {
+	var b strings.Builder
+	var quote, prev rune
+	escaped := false
+	for _, r := range text {
+		switch {
+		case quote == '`':
+			b.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+		case quote != 0:
+			switch {
+			case r == '\n':
+				quote, escaped = 0, false
+				prev = r
+				continue
+			case escaped:
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case r == quote:
+				quote = 0
+			}
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			// Whitespace between tokens is formatting
+		default:
+			b.WriteRune(r)
+			if r == '"' || r == '`' || r == '\'' && !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+				quote = r
+			}
+		}
+		prev = r
+	}
+	return b.String()
+}
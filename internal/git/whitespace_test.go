@@ -0,0 +1,187 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+const mixedDiff = `diff --git a/fmt.go b/fmt.go
+index 1111111..2222222 100644
+--- a/fmt.go
++++ b/fmt.go
+@@ -1,3 +1,3 @@
+ package main
+-func  main() {
++func main() {
+ }
+diff --git a/logic.go b/logic.go
+index 3333333..4444444 100644
+--- a/logic.go
++++ b/logic.go
+@@ -1,4 +1,4 @@
+ package main
+-var x=1
++var x = 1
+
+@@ -10,3 +10,3 @@
+ func run() {
+-	return 1
++	return 2
+ }`
+
+func TestAnalyzeWhitespace_Mixed(t *testing.T) {
+	// git diff -w keeps only the substantive hunk of logic.go
+	ignoringWhitespace := `diff --git a/logic.go b/logic.go
+index 3333333..4444444 100644
+--- a/logic.go
++++ b/logic.go
+@@ -10,3 +10,3 @@
+ func run() {
+-	return 1
++	return 2
+ }`
+
+	analysis := analyzeWhitespace(mixedDiff, ignoringWhitespace)
+
+	if analysis.OnlyWhitespace {
+		t.Error("Expected mixed diff not to be whitespace-only")
+	}
+	if analysis.ExcludedHunks != 2 {
+		t.Errorf("ExcludedHunks = %d, want 2", analysis.ExcludedHunks)
+	}
+	if strings.Join(analysis.Files, ",") != "fmt.go,logic.go" {
+		t.Errorf("Files = %v, want [fmt.go logic.go]", analysis.Files)
+	}
+	if strings.Contains(analysis.FilteredDiff, "fmt.go") {
+		t.Errorf("Expected fmt.go to be dropped from filtered diff:\n%s", analysis.FilteredDiff)
+	}
+	if strings.Contains(analysis.FilteredDiff, "var x") {
+		t.Errorf("Expected whitespace hunk to be dropped from filtered diff:\n%s", analysis.FilteredDiff)
+	}
+	if !strings.Contains(analysis.FilteredDiff, "+\treturn 2") {
+		t.Errorf("Expected substantive hunk to be kept:\n%s", analysis.FilteredDiff)
+	}
+}
+
+func TestAnalyzeWhitespace_OnlyWhitespace(t *testing.T) {
+	diff := `diff --git a/fmt.go b/fmt.go
+--- a/fmt.go
++++ b/fmt.go
+@@ -1,3 +1,4 @@
+ package main
+-func  main() {
++
++func main() {
+ }`
+
+	analysis := analyzeWhitespace(diff, "")
+	if !analysis.OnlyWhitespace {
+		t.Error("Expected diff to be whitespace-only")
+	}
+	if analysis.FilteredDiff != "" {
+		t.Errorf("Expected empty filtered diff, got:\n%s", analysis.FilteredDiff)
+	}
+}
+
+func TestIsWhitespaceOnlyHunk(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  bool
+	}{
+		{name: "indentation", lines: []string{"-  foo()", "+\tfoo()"}, want: true},
+		{name: "blank lines", lines: []string{" a", "+", "+   "}, want: true},
+		{name: "line wrapped by formatter", lines: []string{"-call(a, b)", "+call(", "+\ta,", "+\tb)"}, want: true},
+		{name: "content change", lines: []string{"-foo()", "+bar()"}, want: false},
+		{name: "space inside string literal", lines: []string{`-"a b"`, `+"ab"`}, want: false},
+		{name: "space inside rune literal", lines: []string{`-r := ' '`, `+r := ''`}, want: false},
+		{name: "space inside raw string", lines: []string{"-q := `SELECT a,", "-  b`", "+q := `SELECT a, b`"}, want: false},
+		{name: "escaped quote in string literal", lines: []string{`-s := "say \"hi  there\""`, `+s := "say \"hi there\""`}, want: false},
+		{name: "spacing around string literal", lines: []string{`-fmt.Println( "a b" )`, `+fmt.Println("a b")`}, want: true},
+		{name: "apostrophe in prose", lines: []string{"-Don't  use   this", "+Don't use this"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isWhitespaceOnlyHunk(Hunk{Lines: tt.lines}); got != tt.want {
+				t.Errorf("isWhitespaceOnlyHunk() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractDiffIgnoringWhitespace(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalDir, _ := os.Getwd()
+	defer func() {
+		if err := os.Chdir(originalDir); err != nil {
+			t.Logf("Failed to restore working dir: %v", err)
+		}
+	}()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+	} {
+		if err := exec.Command("git", args...).Run(); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+
+	if err := os.WriteFile("main.go", []byte("package main\n\nfunc main() {\n}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write main.go: %v", err)
+	}
+	if err := exec.Command("git", "add", "main.go").Run(); err != nil {
+		t.Fatalf("Failed to add main.go: %v", err)
+	}
+	if err := exec.Command("git", "commit", "-m", "Initial commit").Run(); err != nil {
+		t.Fatalf("Failed to commit main.go: %v", err)
+	}
+
+	if err := os.WriteFile("main.go", []byte("package main\n\n\nfunc  main()  {\n}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write main.go: %v", err)
+	}
+	if err := exec.Command("git", "add", "main.go").Run(); err != nil {
+		t.Fatalf("Failed to add main.go: %v", err)
+	}
+
+	diff, err := ExtractDiff(true)
+	if err != nil {
+		t.Fatalf("ExtractDiff(true) returned error: %v", err)
+	}
+
+	analysis, err := AnalyzeWhitespace(true, diff)
+	if err != nil {
+		t.Fatalf("AnalyzeWhitespace() returned error: %v", err)
+	}
+	if !analysis.OnlyWhitespace {
+		t.Errorf("Expected whitespace-only change, got %+v", analysis)
+	}
+}
+
+func TestAnalyzeWhitespace_StringLiteral(t *testing.T) {
+	// git diff -w ignores whitespace inside the literal as well
+	diff := `diff --git a/greet.go b/greet.go
+--- a/greet.go
++++ b/greet.go
+@@ -1,3 +1,3 @@
+ package main
+-const greeting = "hello world"
++const greeting = "helloworld"
+ `
+
+	analysis := analyzeWhitespace(diff, "")
+	if analysis.OnlyWhitespace {
+		t.Error("Expected a changed string literal not to be whitespace-only")
+	}
+	if analysis.ExcludedHunks != 0 || !strings.Contains(analysis.FilteredDiff, `+const greeting = "helloworld"`) {
+		t.Errorf("Expected the hunk to be kept, got %d excluded:\n%s", analysis.ExcludedHunks, analysis.FilteredDiff)
+	}
+}
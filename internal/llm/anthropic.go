@@ -0,0 +1,262 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// Anthropic Messages API endpoint
+	anthropicAPIURL       = "https://api.anthropic.com/v1/messages"
+	anthropicVersion      = "2023-06-01"
+	defaultAnthropicModel = "claude-3-5-haiku-20241022"
+	anthropicTimeout      = 60 * time.Second
+)
+
+// AnthropicClient implements the LLMClient interface for Anthropic's Claude models
+type AnthropicClient struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	structured bool // return the commit message as JSON through a forced tool call
+	options    Options
+	httpClient *http.Client
+}
+
+// AnthropicRequest represents the request structure for Anthropic's Messages API
+type AnthropicRequest struct {
+	Model         string               `json:"model"`
+	System        string               `json:"system,omitempty"`
+	Messages      []AnthropicMessage   `json:"messages"`
+	MaxTokens     int                  `json:"max_tokens"`
+	Temperature   *float64             `json:"temperature,omitempty"`
+	TopP          *float64             `json:"top_p,omitempty"`
+	StopSequences []string             `json:"stop_sequences,omitempty"`
+	Tools         []AnthropicTool      `json:"tools,omitempty"`
+	ToolChoice    *AnthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+// AnthropicTool describes a tool the model can call, used here to get structured output
+type AnthropicTool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	InputSchema *JSONSchema `json:"input_schema"`
+}
+
+// AnthropicToolChoice controls whether and which tool the model must call
+type AnthropicToolChoice struct {
+	Type string `json:"type"` // "auto", "any" or "tool"
+	Name string `json:"name,omitempty"`
+}
+
+// AnthropicMessage represents a single conversation turn in the Messages API
+type AnthropicMessage struct {
+	Role    string                  `json:"role"` // "user" or "assistant"
+	Content []AnthropicContentBlock `json:"content"`
+}
+
+// AnthropicContentBlock represents a content block in the Messages API
+type AnthropicContentBlock struct {
+	Type  string          `json:"type"` // "text", "thinking", "tool_use", ...
+	Text  string          `json:"text,omitempty"`
+	Name  string          `json:"name,omitempty"`  // tool name of a tool_use block
+	Input json.RawMessage `json:"input,omitempty"` // tool arguments of a tool_use block
+}
+
+// AnthropicResponse represents the response structure from the Messages API
+type AnthropicResponse struct {
+	ID         string                  `json:"id"`
+	Model      string                  `json:"model"`
+	Content    []AnthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// AnthropicErrorResponse represents an error returned by the Messages API
+type AnthropicErrorResponse struct {
+	Type  string `json:"type"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewAnthropicClient creates a new AnthropicClient with the API key from environment
+func NewAnthropicClient(model string) (*AnthropicClient, error) {
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable is required")
+	}
+
+	return NewAnthropicClientWithConfig(apiKey, "", model), nil
+}
+
+// NewAnthropicClientWithConfig creates a new AnthropicClient with custom configuration
+func NewAnthropicClientWithConfig(apiKey, baseURL, model string) *AnthropicClient {
+	if baseURL == "" {
+		baseURL = anthropicAPIURL
+	}
+	if model == "" {
+		model = defaultAnthropicModel
+	}
+
+	return &AnthropicClient{
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		model:      model,
+		httpClient: newHTTPClient(anthropicTimeout),
+	}
+}
+
+// GenerateCommitMessage generates a commit message based on the provided request
+func (c *AnthropicClient) GenerateCommitMessage(ctx context.Context, request *Request) (string, error) {
+	structured := wantsJSON(c.structured, request)
+	prompt := BuildCommitPrompt(request)
+	if structured {
+		prompt = BuildStructuredCommitPrompt(request)
+	}
+
+	options := c.options.Merge(request.Options)
+	reqBody := AnthropicRequest{
+		Model:         c.model,
+		System:        options.systemPrompt(defaultSystemPrompt),
+		Messages:      anthropicMessages(conversation(prompt, request)),
+		MaxTokens:     options.maxTokens(),
+		StopSequences: options.Stop,
+	}
+	// Newer models reject temperature and top_p together, so top_p is only sent on its own
+	if options.TopP != nil && options.Temperature == nil {
+		reqBody.TopP = options.TopP
+	} else {
+		temperature := options.temperature()
+		reqBody.Temperature = &temperature
+	}
+	if structured {
+		// Forcing a call of the commit_message tool makes the model fill in its input schema
+		reqBody.Tools = []AnthropicTool{{
+			Name:        commitMessageSchemaName,
+			Description: "Record the commit message for the diff",
+			InputSchema: CommitMessageSchema(),
+		}}
+		reqBody.ToolChoice = &AnthropicToolChoice{Type: "tool", Name: commitMessageSchemaName}
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close response body: %v\n", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr AnthropicErrorResponse
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Message != "" {
+			return "", &APIError{Provider: "Anthropic", StatusCode: resp.StatusCode, Body: apiErr.Error.Type + ": " + apiErr.Error.Message}
+		}
+		return "", &APIError{Provider: "Anthropic", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var response AnthropicResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	recordUsage(ctx, Usage{
+		Provider:         c.providerName(),
+		Model:            c.model,
+		PromptTokens:     response.Usage.InputTokens,
+		CompletionTokens: response.Usage.OutputTokens,
+		Latency:          time.Since(start),
+	})
+
+	// Only text blocks carry the answer, thinking and tool blocks are skipped
+	var text strings.Builder
+	for _, block := range response.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if err := checkFinish("Anthropic", response.StopReason, strings.TrimSpace(text.String()), reqBody.MaxTokens); err != nil {
+		return "", err
+	}
+
+	// In structured mode the answer is the input of the commit_message tool call
+	for _, block := range response.Content {
+		if structured && block.Type == "tool_use" && block.Name == commitMessageSchemaName && len(block.Input) > 0 {
+			return string(block.Input), nil
+		}
+	}
+
+	commitMsg := strings.TrimSpace(text.String())
+	if commitMsg == "" {
+		return "", fmt.Errorf("empty commit message received")
+	}
+
+	return commitMsg, nil
+}
+
+// setOptions sets the defaults of every request
+func (c *AnthropicClient) setOptions(options Options) {
+	c.options = options
+}
+
+// setTimeout bounds each request, including its retries
+func (c *AnthropicClient) setTimeout(timeout time.Duration) {
+	c.httpClient.Timeout = timeout
+}
+
+// providerName returns the name reported in Usage
+func (c *AnthropicClient) providerName() string {
+	return "Anthropic"
+}
+
+// setStructuredOutput switches between JSON and free text commit messages
+func (c *AnthropicClient) setStructuredOutput(enabled bool) {
+	c.structured = enabled
+}
+
+// structuredOutput reports whether commit messages are requested as JSON
+func (c *AnthropicClient) structuredOutput() bool {
+	return c.structured
+}
+
+// anthropicMessages converts conversation turns to Messages API messages
+func anthropicMessages(turns []Message) []AnthropicMessage {
+	messages := make([]AnthropicMessage, len(turns))
+	for i, turn := range turns {
+		messages[i] = AnthropicMessage{Role: turn.Role, Content: []AnthropicContentBlock{{Type: "text", Text: turn.Content}}}
+	}
+	return messages
+}
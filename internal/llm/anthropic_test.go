@@ -0,0 +1,226 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnthropicClient_GenerateCommitMessage(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   string
+		expectedMsg    string
+		expectedError  string
+	}{
+		{
+			name:           "successful response",
+			responseStatus: http.StatusOK,
+			responseBody: `{
+				"id": "msg_01",
+				"type": "message",
+				"role": "assistant",
+				"model": "claude-3-5-haiku-20241022",
+				"content": [{"type": "text", "text": "Add Hello world print statement"}],
+				"stop_reason": "end_turn",
+				"usage": {"input_tokens": 120, "output_tokens": 9}
+			}`,
+			expectedMsg: "Add Hello world print statement",
+		},
+		{
+			name:           "multiple content blocks",
+			responseStatus: http.StatusOK,
+			responseBody: `{
+				"content": [
+					{"type": "thinking", "thinking": "The diff adds a print"},
+					{"type": "text", "text": "Add greeting\n\n"},
+					{"type": "text", "text": "Print hello on startup."}
+				],
+				"stop_reason": "end_turn"
+			}`,
+			expectedMsg: "Add greeting\n\nPrint hello on startup.",
+		},
+		{
+			name:           "API error response",
+			responseStatus: http.StatusUnauthorized,
+			responseBody:   `{"type": "error", "error": {"type": "authentication_error", "message": "invalid x-api-key"}}`,
+			expectedError:  "Anthropic API request failed with status 401: authentication_error: invalid x-api-key",
+		},
+		{
+			name:           "overloaded without JSON body",
+			responseStatus: 529,
+			responseBody:   `Overloaded`,
+			expectedError:  "Anthropic API request failed with status 529: Overloaded",
+		},
+		{
+			name:           "truncated response",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"content": [{"type": "text", "text": "Add greeting and"}], "stop_reason": "max_tokens"}`,
+			expectedError:  "commit message was truncated",
+		},
+		{
+			name:           "refusal",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"content": [], "stop_reason": "refusal"}`,
+			expectedError:  "the model declined to generate a commit message",
+		},
+		{
+			name:           "invalid JSON response",
+			responseStatus: http.StatusOK,
+			responseBody:   `invalid json`,
+			expectedError:  "failed to parse response",
+		},
+		{
+			name:           "empty commit message",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"content": [{"type": "text", "text": "  "}], "stop_reason": "end_turn"}`,
+			expectedError:  "empty commit message received",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "POST" {
+					t.Errorf("Expected POST request, got %s", r.Method)
+				}
+				if r.Header.Get("x-api-key") != "test-api-key" {
+					t.Errorf("Expected x-api-key header, got '%s'", r.Header.Get("x-api-key"))
+				}
+				if r.Header.Get("anthropic-version") != anthropicVersion {
+					t.Errorf("Expected anthropic-version %s, got '%s'", anthropicVersion, r.Header.Get("anthropic-version"))
+				}
+				if r.Header.Get("Authorization") != "" {
+					t.Errorf("Expected no Authorization header, got %s", r.Header.Get("Authorization"))
+				}
+
+				var req AnthropicRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("Failed to decode request: %v", err)
+				}
+				if req.System == "" {
+					t.Error("Expected system prompt in the system field")
+				}
+				if req.MaxTokens == 0 {
+					t.Error("Expected max_tokens to be set")
+				}
+				if len(req.Messages) != 1 || req.Messages[0].Role != "user" || len(req.Messages[0].Content) != 1 ||
+					!strings.Contains(req.Messages[0].Content[0].Text, "some diff") {
+					t.Errorf("Expected a single user text block with the diff, got %+v", req.Messages)
+				}
+
+				w.WriteHeader(tt.responseStatus)
+				if _, err := w.Write([]byte(tt.responseBody)); err != nil {
+					t.Errorf("Failed to write response body: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			client := NewAnthropicClientWithConfig("test-api-key", server.URL, "claude-3-5-haiku-20241022")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, err := client.GenerateCommitMessage(ctx, &Request{Diff: "some diff"})
+
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Errorf("Expected error containing '%s', got nil", tt.expectedError)
+				} else if !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("Expected error containing '%s', got '%s'", tt.expectedError, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if result != tt.expectedMsg {
+				t.Errorf("Expected message '%s', got '%s'", tt.expectedMsg, result)
+			}
+		})
+	}
+}
+
+func TestNewAnthropicClient(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	if _, err := NewAnthropicClient(""); err == nil || !strings.Contains(err.Error(), "ANTHROPIC_API_KEY environment variable is required") {
+		t.Errorf("Expected error about missing API key, got: %v", err)
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	client, err := NewAnthropicClient("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.model != defaultAnthropicModel || client.baseURL != anthropicAPIURL {
+		t.Errorf("Expected defaults, got model=%s url=%s", client.model, client.baseURL)
+	}
+}
+
+func TestAnthropicClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"type": "error", "error": {"type": "authentication_error", "message": "invalid x-api-key"}}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClientWithConfig("test-api-key", server.URL, "claude-3-5-haiku-20241022")
+	_, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: "some diff"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an APIError, got %v", err)
+	}
+	if apiErr.Provider != "Anthropic" || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Body != "authentication_error: invalid x-api-key" {
+		t.Errorf("Unexpected APIError %+v", apiErr)
+	}
+}
+
+func TestAnthropicClient_Sampling(t *testing.T) {
+	temperature, topP := 0.7, 0.9
+	tests := []struct {
+		name    string
+		options Options
+		want    []string
+		wantNot []string
+	}{
+		{name: "defaults", want: []string{`"temperature":0.3`}, wantNot: []string{`"top_p"`}},
+		{name: "temperature", options: Options{Temperature: &temperature}, want: []string{`"temperature":0.7`}, wantNot: []string{`"top_p"`}},
+		{name: "top_p only", options: Options{TopP: &topP}, want: []string{`"top_p":0.9`}, wantNot: []string{`"temperature"`}},
+		{name: "both", options: Options{Temperature: &temperature, TopP: &topP}, want: []string{`"temperature":0.7`}, wantNot: []string{`"top_p"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				_, _ = w.Write([]byte(`{"content": [{"type": "text", "text": "Add x"}], "stop_reason": "end_turn"}`))
+			}))
+			defer server.Close()
+
+			client := NewAnthropicClientWithConfig("test-api-key", server.URL, "claude-sonnet-4-5")
+			if _, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: "diff", Options: tt.options}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for _, expected := range tt.want {
+				if !strings.Contains(string(body), expected) {
+					t.Errorf("Expected %s in %s", expected, body)
+				}
+			}
+			for _, unexpected := range tt.wantNot {
+				if strings.Contains(string(body), unexpected) {
+					t.Errorf("Expected no %s in %s", unexpected, body)
+				}
+			}
+		})
+	}
+}
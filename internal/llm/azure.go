@@ -0,0 +1,131 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/siddhartha/rune/internal/config"
+)
+
+// AzureClient implements the LLMClient interface for Azure OpenAI deployments
+type AzureClient struct {
+	*OpenAICompatibleClient
+	deployment string
+}
+
+// azureErrorResponse represents an error returned by Azure OpenAI
+type azureErrorResponse struct {
+	Error struct {
+		Code       string `json:"code"`
+		Message    string `json:"message"`
+		InnerError struct {
+			Code string `json:"code"`
+		} `json:"innererror"`
+	} `json:"error"`
+}
+
+// NewAzureClient creates a new AzureClient with the API key from environment
+func NewAzureClient(endpoint, apiVersion, deployment string) (*AzureClient, error) {
+	apiKey := os.Getenv("AZURE_OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("AZURE_OPENAI_API_KEY environment variable is required")
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("Azure OpenAI endpoint is required")
+	}
+	if deployment == "" {
+		return nil, fmt.Errorf("Azure OpenAI deployment is required")
+	}
+
+	return NewAzureClientWithConfig(apiKey, endpoint, apiVersion, deployment), nil
+}
+
+// NewAzureClientWithConfig creates a new AzureClient with custom configuration
+func NewAzureClientWithConfig(apiKey, endpoint, apiVersion, deployment string) *AzureClient {
+	if apiVersion == "" {
+		apiVersion = config.DefaultAzureAPIVersion
+	}
+
+	return &AzureClient{
+		OpenAICompatibleClient: newOpenAICompatibleClient(OpenAICompatibleConfig{
+			Name:      "Azure OpenAI",
+			BaseURL:   azureChatCompletionsURL(endpoint, apiVersion, deployment),
+			APIKey:    apiKey,
+			AuthStyle: config.AuthStyleAPIKey,
+			// Azure routes by deployment and ignores the model field
+			Model: deployment,
+		}),
+		deployment: deployment,
+	}
+}
+
+// azureChatCompletionsURL builds the deployment-based chat completions URL
+func azureChatCompletionsURL(endpoint, apiVersion, deployment string) string {
+	return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+		strings.TrimSuffix(endpoint, "/"), url.PathEscape(deployment), url.QueryEscape(apiVersion))
+}
+
+// GenerateCommitMessage generates a commit message based on the provided request
+func (c *AzureClient) GenerateCommitMessage(ctx context.Context, request *Request) (string, error) {
+	message, err := c.OpenAICompatibleClient.GenerateCommitMessage(ctx, request)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			return "", c.translateError(apiErr)
+		}
+		return "", err
+	}
+	return message, nil
+}
+
+// StreamCommitMessage generates a commit message, passing text to onChunk as it is generated
+func (c *AzureClient) StreamCommitMessage(ctx context.Context, request *Request, onChunk StreamHandler) (string, error) {
+	message, err := c.OpenAICompatibleClient.StreamCommitMessage(ctx, request, onChunk)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			return "", c.translateError(apiErr)
+		}
+		return "", err
+	}
+	return message, nil
+}
+
+// GenerateCandidates asks the deployment for n alternative commit messages in one request
+func (c *AzureClient) GenerateCandidates(ctx context.Context, request *Request, n int) ([]string, error) {
+	candidates, err := c.OpenAICompatibleClient.GenerateCandidates(ctx, request, n)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			return nil, c.translateError(apiErr)
+		}
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// translateError turns Azure-specific error bodies into errors the UI can explain
+func (c *AzureClient) translateError(apiErr *APIError) error {
+	var body azureErrorResponse
+	if json.Unmarshal([]byte(apiErr.Body), &body) != nil || body.Error.Message == "" {
+		return apiErr
+	}
+
+	code := strings.ToLower(body.Error.Code)
+	switch {
+	case code == "content_filter" || body.Error.InnerError.Code == "ResponsibleAIPolicyViolation":
+		return fmt.Errorf("Azure OpenAI content filter blocked the request: %s: %w", body.Error.Message, apiErr)
+	case apiErr.StatusCode == http.StatusTooManyRequests || code == "429" || code == "insufficient_quota" || code == "ratelimitreached":
+		return fmt.Errorf("Azure OpenAI quota exceeded for deployment %s: %s: %w", c.deployment, body.Error.Message, apiErr)
+	case code == "deploymentnotfound":
+		return fmt.Errorf("Azure OpenAI deployment not found: %s: %w", c.deployment, apiErr)
+	default:
+		return apiErr
+	}
+}
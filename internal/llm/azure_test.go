@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAzureClient_GenerateCommitMessage(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   string
+		expectedMsg    string
+		expectedError  string
+	}{
+		{
+			name:           "successful response",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"choices": [{"message": {"role": "assistant", "content": "Add Hello world print statement"}, "finish_reason": "stop"}]}`,
+			expectedMsg:    "Add Hello world print statement",
+		},
+		{
+			name:           "content filter on the prompt",
+			responseStatus: http.StatusBadRequest,
+			responseBody: `{"error": {"code": "content_filter", "message": "The response was filtered due to the prompt triggering Azure OpenAI's content management policy.",
+				"innererror": {"code": "ResponsibleAIPolicyViolation"}}}`,
+			expectedError: "Azure OpenAI content filter blocked the request",
+		},
+		{
+			name:           "content filter on the completion",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"choices": [{"message": {"role": "assistant", "content": ""}, "finish_reason": "content_filter"}]}`,
+			expectedError:  "Azure OpenAI response was blocked by the content filter",
+		},
+		{
+			name:           "rate limit",
+			responseStatus: http.StatusTooManyRequests,
+			responseBody:   `{"error": {"code": "429", "message": "Requests to the ChatCompletions_Create Operation have exceeded token rate limit of your current pricing tier."}}`,
+			expectedError:  "Azure OpenAI quota exceeded for deployment prod-gpt4o",
+		},
+		{
+			name:           "deployment not found",
+			responseStatus: http.StatusNotFound,
+			responseBody:   `{"error": {"code": "DeploymentNotFound", "message": "The API deployment for this resource does not exist."}}`,
+			expectedError:  "Azure OpenAI deployment not found: prod-gpt4o",
+		},
+		{
+			name:           "other errors are passed through",
+			responseStatus: http.StatusUnauthorized,
+			responseBody:   `{"error": {"code": "401", "message": "Access denied due to invalid subscription key."}}`,
+			expectedError:  "Azure OpenAI API request failed with status 401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/openai/deployments/prod-gpt4o/chat/completions" {
+					t.Errorf("Expected deployment path, got %s", r.URL.Path)
+				}
+				if r.URL.Query().Get("api-version") != "2024-06-01" {
+					t.Errorf("Expected api-version 2024-06-01, got %s", r.URL.Query().Get("api-version"))
+				}
+				if r.Header.Get("api-key") != "test-api-key" {
+					t.Errorf("Expected api-key header, got '%s'", r.Header.Get("api-key"))
+				}
+				if r.Header.Get("Authorization") != "" {
+					t.Errorf("Expected no Authorization header, got %s", r.Header.Get("Authorization"))
+				}
+
+				w.WriteHeader(tt.responseStatus)
+				if _, err := w.Write([]byte(tt.responseBody)); err != nil {
+					t.Errorf("Failed to write response body: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			client := NewAzureClientWithConfig("test-api-key", server.URL+"/", "2024-06-01", "prod-gpt4o")
+
+			result, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: "some diff"})
+
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Errorf("Expected error containing '%s', got nil", tt.expectedError)
+				} else if !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("Expected error containing '%s', got '%s'", tt.expectedError, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if result != tt.expectedMsg {
+				t.Errorf("Expected message '%s', got '%s'", tt.expectedMsg, result)
+			}
+		})
+	}
+}
+
+func TestAzureClient_KeepsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error": {"code": "429", "message": "Rate limit is exceeded."}}`))
+	}))
+	defer server.Close()
+
+	client := NewAzureClientWithConfig("key", server.URL, "", "dep")
+	_, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: "diff"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected wrapped APIError with status 429, got %v", err)
+	}
+}
+
+func TestNewAzureClient(t *testing.T) {
+	t.Setenv("AZURE_OPENAI_API_KEY", "")
+	if _, err := NewAzureClient("https://res.openai.azure.com", "", "dep"); err == nil {
+		t.Error("Expected error when AZURE_OPENAI_API_KEY is not set")
+	}
+
+	t.Setenv("AZURE_OPENAI_API_KEY", "key")
+	if _, err := NewAzureClient("", "", "dep"); err == nil {
+		t.Error("Expected error without endpoint")
+	}
+
+	client, err := NewAzureClient("https://res.openai.azure.com/", "", "my dep")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := "https://res.openai.azure.com/openai/deployments/my%20dep/chat/completions?api-version=2024-10-21"
+	if client.baseURL != want {
+		t.Errorf("Expected URL %s, got %s", want, client.baseURL)
+	}
+}
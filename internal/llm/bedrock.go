@@ -0,0 +1,222 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/siddhartha/rune/internal/aws"
+)
+
+const (
+	defaultBedrockModel = "anthropic.claude-3-5-haiku-20241022-v1:0"
+	bedrockService      = "bedrock"
+	bedrockTimeout      = 60 * time.Second
+)
+
+// BedrockClient implements the LLMClient interface for AWS Bedrock's Converse API
+type BedrockClient struct {
+	endpoint    string // e.g. https://bedrock-runtime.us-east-1.amazonaws.com
+	region      string
+	model       string // Bedrock model ID or inference profile ID
+	credentials aws.Credentials
+	options     Options
+	httpClient  *http.Client
+	now         func() time.Time
+}
+
+// BedrockConverseRequest represents the request structure for the Converse API
+type BedrockConverseRequest struct {
+	Messages        []BedrockMessage        `json:"messages"`
+	System          []BedrockContentBlock   `json:"system,omitempty"`
+	InferenceConfig *BedrockInferenceConfig `json:"inferenceConfig,omitempty"`
+}
+
+// BedrockMessage represents a single conversation turn in the Converse API
+type BedrockMessage struct {
+	Role    string                `json:"role"` // "user" or "assistant"
+	Content []BedrockContentBlock `json:"content"`
+}
+
+// BedrockContentBlock represents a content block in the Converse API
+type BedrockContentBlock struct {
+	Text string `json:"text,omitempty"`
+}
+
+// BedrockInferenceConfig represents inference parameters for the Converse API
+type BedrockInferenceConfig struct {
+	MaxTokens     int      `json:"maxTokens,omitempty"`
+	Temperature   float64  `json:"temperature"`
+	TopP          *float64 `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+// BedrockConverseResponse represents the response structure from the Converse API
+type BedrockConverseResponse struct {
+	Output struct {
+		Message BedrockMessage `json:"message"`
+	} `json:"output"`
+	StopReason string `json:"stopReason"`
+	Usage      struct {
+		InputTokens  int `json:"inputTokens"`
+		OutputTokens int `json:"outputTokens"`
+		TotalTokens  int `json:"totalTokens"`
+	} `json:"usage"`
+}
+
+// NewBedrockClient creates a new BedrockClient for the regional Bedrock runtime endpoint
+func NewBedrockClient(region, model string, credentials aws.Credentials) (*BedrockClient, error) {
+	if region == "" {
+		return nil, fmt.Errorf("AWS region is required for Bedrock")
+	}
+	return NewBedrockClientWithConfig(fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", region), region, model, credentials), nil
+}
+
+// NewBedrockClientWithConfig creates a new BedrockClient with a custom endpoint, e.g. a VPC endpoint
+func NewBedrockClientWithConfig(endpoint, region, model string, credentials aws.Credentials) *BedrockClient {
+	if model == "" {
+		model = defaultBedrockModel
+	}
+
+	return &BedrockClient{
+		endpoint:    strings.TrimSuffix(endpoint, "/"),
+		region:      region,
+		model:       model,
+		credentials: credentials,
+		httpClient:  newHTTPClient(bedrockTimeout),
+		now:         time.Now,
+	}
+}
+
+// GenerateCommitMessage generates a commit message based on the provided request
+func (c *BedrockClient) GenerateCommitMessage(ctx context.Context, request *Request) (string, error) {
+	prompt := BuildCommitPrompt(request)
+
+	options := c.options.Merge(request.Options)
+	reqBody := BedrockConverseRequest{
+		Messages: bedrockMessages(conversation(prompt, request)),
+		System: []BedrockContentBlock{
+			{Text: options.systemPrompt(defaultSystemPrompt)},
+		},
+		InferenceConfig: &BedrockInferenceConfig{
+			MaxTokens:     options.maxTokens(),
+			Temperature:   options.temperature(),
+			TopP:          options.TopP,
+			StopSequences: options.Stop,
+		},
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Model IDs contain ':' which the AWS SDKs always percent-encode in the path
+	modelPath := strings.ReplaceAll(url.PathEscape(c.model), ":", "%3A")
+	converseURL := c.endpoint + "/model/" + modelPath + "/converse"
+	req, err := http.NewRequestWithContext(ctx, "POST", converseURL, bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if err := aws.SignRequest(req, jsonBody, c.credentials, c.region, bedrockService, c.now()); err != nil {
+		return "", fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close response body: %v\n", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", bedrockError(resp, body)
+	}
+
+	var response BedrockConverseResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	recordUsage(ctx, Usage{
+		Provider:         c.providerName(),
+		Model:            c.model,
+		PromptTokens:     response.Usage.InputTokens,
+		CompletionTokens: response.Usage.OutputTokens,
+		Latency:          time.Since(start),
+	})
+
+	var text strings.Builder
+	for _, block := range response.Output.Message.Content {
+		text.WriteString(block.Text)
+	}
+
+	commitMsg := strings.TrimSpace(text.String())
+	if err := checkFinish("Bedrock", response.StopReason, commitMsg, reqBody.InferenceConfig.MaxTokens); err != nil {
+		return "", err
+	}
+	if commitMsg == "" {
+		return "", fmt.Errorf("empty commit message received")
+	}
+
+	return commitMsg, nil
+}
+
+// setOptions sets the defaults of every request
+func (c *BedrockClient) setOptions(options Options) {
+	c.options = options
+}
+
+// setTimeout bounds each request, including its retries
+func (c *BedrockClient) setTimeout(timeout time.Duration) {
+	c.httpClient.Timeout = timeout
+}
+
+// providerName returns the name reported in Usage
+func (c *BedrockClient) providerName() string {
+	return "Bedrock"
+}
+
+// bedrockError builds an APIError from an AWS JSON error response.
+// The error type comes from the x-amzn-ErrorType header, e.g. "AccessDeniedException:http://..."
+func bedrockError(resp *http.Response, body []byte) error {
+	var awsErr struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &awsErr) != nil || awsErr.Message == "" {
+		return &APIError{Provider: "Bedrock", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	errorType, _, _ := strings.Cut(resp.Header.Get("X-Amzn-ErrorType"), ":")
+	if errorType != "" {
+		awsErr.Message = errorType + ": " + awsErr.Message
+	}
+	return &APIError{Provider: "Bedrock", StatusCode: resp.StatusCode, Body: awsErr.Message}
+}
+
+// bedrockMessages converts conversation turns to Converse API messages
+func bedrockMessages(turns []Message) []BedrockMessage {
+	messages := make([]BedrockMessage, len(turns))
+	for i, turn := range turns {
+		messages[i] = BedrockMessage{Role: turn.Role, Content: []BedrockContentBlock{{Text: turn.Content}}}
+	}
+	return messages
+}
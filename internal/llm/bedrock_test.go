@@ -0,0 +1,152 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/siddhartha/rune/internal/aws"
+)
+
+func TestBedrockClient_GenerateCommitMessage(t *testing.T) {
+	credentials := aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "token"}
+	signedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		responseStatus int
+		errorType      string
+		responseBody   string
+		expectedMsg    string
+		expectedError  string
+	}{
+		{
+			name:           "successful response",
+			responseStatus: http.StatusOK,
+			responseBody: `{
+				"output": {"message": {"role": "assistant", "content": [{"text": "Add Hello world print statement"}]}},
+				"stopReason": "end_turn",
+				"usage": {"inputTokens": 120, "outputTokens": 9, "totalTokens": 129}
+			}`,
+			expectedMsg: "Add Hello world print statement",
+		},
+		{
+			name:           "access denied",
+			responseStatus: http.StatusForbidden,
+			errorType:      "AccessDeniedException:http://internal.amazon.com/coral/com.amazon.bedrock/",
+			responseBody:   `{"message": "You don't have access to the model with the specified model ID."}`,
+			expectedError:  "Bedrock API request failed with status 403: AccessDeniedException: You don't have access",
+		},
+		{
+			name:           "throttled",
+			responseStatus: http.StatusTooManyRequests,
+			errorType:      "ThrottlingException",
+			responseBody:   `{"message": "Too many requests, please wait before trying again."}`,
+			expectedError:  "status 429: ThrottlingException",
+		},
+		{
+			name:           "guardrail",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"output": {"message": {"role": "assistant", "content": [{"text": "Sorry"}]}}, "stopReason": "guardrail_intervened"}`,
+			expectedError:  "blocked by the content filter",
+		},
+		{
+			name:           "truncated",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"output": {"message": {"role": "assistant", "content": [{"text": "Add"}]}}, "stopReason": "max_tokens"}`,
+			expectedError:  "commit message was truncated",
+		},
+		{
+			name:           "empty commit message",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"output": {"message": {"role": "assistant", "content": []}}, "stopReason": "end_turn"}`,
+			expectedError:  "empty commit message received",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.EscapedPath() != "/model/anthropic.claude-3-5-haiku-20241022-v1%3A0/converse" {
+					t.Errorf("Unexpected path %s", r.URL.EscapedPath())
+				}
+
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatalf("Failed to read body: %v", err)
+				}
+
+				// Re-sign the received request and compare signatures
+				verify, _ := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+				verify.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+				verify.Header.Set("Accept", r.Header.Get("Accept"))
+				if err := aws.SignRequest(verify, body, credentials, "us-east-1", "bedrock", signedAt); err != nil {
+					t.Fatalf("Failed to sign verification request: %v", err)
+				}
+				if r.Header.Get("Authorization") != verify.Header.Get("Authorization") {
+					t.Errorf("Signature mismatch:\n got %s\nwant %s", r.Header.Get("Authorization"), verify.Header.Get("Authorization"))
+				}
+				if r.Header.Get("X-Amz-Security-Token") != "token" {
+					t.Error("Expected session token header")
+				}
+
+				var req BedrockConverseRequest
+				if err := json.Unmarshal(body, &req); err != nil {
+					t.Errorf("Failed to decode request: %v", err)
+				}
+				if len(req.System) != 1 || len(req.Messages) != 1 || !strings.Contains(req.Messages[0].Content[0].Text, "some diff") {
+					t.Errorf("Unexpected request: %+v", req)
+				}
+
+				if tt.errorType != "" {
+					w.Header().Set("X-Amzn-ErrorType", tt.errorType)
+				}
+				w.WriteHeader(tt.responseStatus)
+				if _, err := w.Write([]byte(tt.responseBody)); err != nil {
+					t.Errorf("Failed to write response body: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			client := NewBedrockClientWithConfig(server.URL, "us-east-1", "anthropic.claude-3-5-haiku-20241022-v1:0", credentials)
+			client.now = func() time.Time { return signedAt }
+
+			result, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: "some diff"})
+
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Errorf("Expected error containing '%s', got nil", tt.expectedError)
+				} else if !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("Expected error containing '%s', got '%s'", tt.expectedError, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if result != tt.expectedMsg {
+				t.Errorf("Expected message '%s', got '%s'", tt.expectedMsg, result)
+			}
+		})
+	}
+}
+
+func TestNewBedrockClient(t *testing.T) {
+	if _, err := NewBedrockClient("", "", aws.Credentials{}); err == nil {
+		t.Error("Expected error without region")
+	}
+
+	client, err := NewBedrockClient("eu-central-1", "", aws.Credentials{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.endpoint != "https://bedrock-runtime.eu-central-1.amazonaws.com" || client.model != defaultBedrockModel {
+		t.Errorf("Unexpected client: endpoint=%s model=%s", client.endpoint, client.model)
+	}
+}
@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// MaxCandidates bounds the number of alternatives requested per generation
+const MaxCandidates = 8
+
+// CandidateClient is implemented by clients that can return several alternative
+// commit messages from a single request
+type CandidateClient interface {
+	LLMClient
+	// GenerateCandidates returns up to n alternative commit messages. Providers may return fewer.
+	GenerateCandidates(ctx context.Context, req *Request, n int) ([]string, error)
+}
+
+// GenerateCandidates returns n alternative commit messages. Clients that support it are asked
+// for all of them in one request; missing alternatives are generated with parallel requests
+// when that request returned fewer or was rejected. Other failures are returned, so that
+// parallel requests do not multiply a rate limit or outage.
+// Duplicates are dropped, so fewer than n messages may be returned. When every answer was cut
+// off by the output limit, they are generated again with a larger limit.
+func GenerateCandidates(ctx context.Context, client LLMClient, request *Request, n int) ([]string, error) {
+	if chain, ok := client.(*FallbackClient); ok {
+		// Every model of the chain retries its own truncated answers
+		return chain.GenerateCandidates(ctx, request, n)
+	}
+	return retryTruncated(ctx, request, func(request *Request) ([]string, error) {
+		return generateCandidates(ctx, client, request, n)
+	})
+}
+
+// generateCandidates requests n alternative commit messages once
+func generateCandidates(ctx context.Context, client LLMClient, request *Request, n int) ([]string, error) {
+	if n < 1 {
+		n = 1
+	}
+
+	var candidates []string
+	var firstErr error
+	if multi, ok := client.(CandidateClient); ok && n > 1 {
+		candidates, firstErr = multi.GenerateCandidates(ctx, request, n)
+		if firstErr != nil && !rejectsCandidates(firstErr) {
+			return nil, firstErr
+		}
+	}
+
+	if missing := n - len(candidates); missing > 0 {
+		results := make([]string, missing)
+		errs := make([]error, missing)
+
+		var wg sync.WaitGroup
+		for i := 0; i < missing; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], errs[i] = client.GenerateCommitMessage(ctx, request)
+			}(i)
+		}
+		wg.Wait()
+
+		for i, result := range results {
+			if errs[i] != nil {
+				if firstErr == nil {
+					firstErr = errs[i]
+				}
+				continue
+			}
+			candidates = append(candidates, result)
+		}
+	}
+
+	candidates = uniqueCandidates(candidates)
+	if len(candidates) == 0 {
+		if firstErr == nil {
+			firstErr = fmt.Errorf("empty commit message received")
+		}
+		return nil, firstErr
+	}
+	return candidates, nil
+}
+
+// rejectsCandidates reports whether a provider rejected a request for several alternatives,
+// e.g. because it does not support the n parameter. Single requests may still succeed.
+func rejectsCandidates(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusUnprocessableEntity)
+}
+
+// uniqueCandidates drops empty and repeated messages, keeping the first occurrence
+func uniqueCandidates(candidates []string) []string {
+	seen := make(map[string]bool, len(candidates))
+	unique := candidates[:0]
+	for _, candidate := range candidates {
+		key := strings.TrimSpace(candidate)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, candidate)
+	}
+	return unique
+}
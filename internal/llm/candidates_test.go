@@ -0,0 +1,191 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOpenAICompatibleClient_GenerateCandidates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil || req.N != 3 {
+			t.Errorf("Expected n=3, got %s", body)
+		}
+		_, _ = w.Write([]byte(`{"choices": [
+			{"index": 0, "message": {"role": "assistant", "content": "Add candidate support"}, "finish_reason": "stop"},
+			{"index": 1, "message": {"role": "assistant", "content": "Generate several messages"}, "finish_reason": "stop"},
+			{"index": 2, "message": {"role": "assistant", "content": "Add candidate support"}, "finish_reason": "stop"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m"})
+
+	result, err := client.GenerateCandidates(context.Background(), &Request{Diff: "some diff"}, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{"Add candidate support", "Generate several messages", "Add candidate support"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestGeminiClient_GenerateCandidates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GeminiRequest
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil || req.GenerationConfig.CandidateCount != 2 {
+			t.Errorf("Expected candidateCount=2, got %s", body)
+		}
+		_, _ = w.Write([]byte(`{"candidates": [
+			{"content": {"parts": [{"text": "Fix parser "}, {"text": "crash"}], "role": "model"}, "finishReason": "STOP"},
+			{"content": {"parts": [{"text": "Handle empty input in parser"}], "role": "model"}, "finishReason": "STOP"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewGeminiClientWithConfig("test-key", server.URL, "gemini-2.0-flash")
+
+	result, err := client.GenerateCandidates(context.Background(), &Request{Diff: "some diff"}, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []string{"Fix parser crash", "Handle empty input in parser"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestGenerateCandidates(t *testing.T) {
+	t.Run("tops up with parallel requests", func(t *testing.T) {
+		// The server ignores n and answers every request with a different message
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			count := atomic.AddInt32(&requests, 1)
+			fmt.Fprintf(w, `{"choices": [{"message": {"role": "assistant", "content": "Message %d"}, "finish_reason": "stop"}]}`, count)
+		}))
+		defer server.Close()
+
+		client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m"})
+
+		result, err := GenerateCandidates(context.Background(), client, &Request{Diff: "some diff"}, 3)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(result) != 3 {
+			t.Errorf("Expected 3 candidates, got %q", result)
+		}
+		if requests != 3 {
+			t.Errorf("Expected 3 requests, got %d", requests)
+		}
+	})
+
+	t.Run("unsupported n falls back to parallel requests", func(t *testing.T) {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			count := atomic.AddInt32(&requests, 1)
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), `"n":`) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error": {"message": "n is not supported"}}`))
+				return
+			}
+			fmt.Fprintf(w, `{"choices": [{"message": {"role": "assistant", "content": "Message %d"}, "finish_reason": "stop"}]}`, count)
+		}))
+		defer server.Close()
+
+		client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m"})
+
+		result, err := GenerateCandidates(context.Background(), client, &Request{Diff: "some diff"}, 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(result) != 2 {
+			t.Errorf("Expected 2 candidates, got %q", result)
+		}
+	})
+
+	t.Run("failed request is not repeated in parallel", func(t *testing.T) {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error": {"message": "rate limited"}}`))
+		}))
+		defer server.Close()
+
+		client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m"})
+
+		_, err := GenerateCandidates(context.Background(), client, &Request{Diff: "some diff"}, 4)
+		if err == nil || !strings.Contains(err.Error(), "status 429") {
+			t.Errorf("Expected the 429 error, got %v", err)
+		}
+		if got := atomic.LoadInt32(&requests); got != int32(retryPolicy.MaxAttempts) {
+			t.Errorf("Expected only the retries of the single request (%d), got %d requests", retryPolicy.MaxAttempts, got)
+		}
+	})
+
+	t.Run("duplicates are dropped", func(t *testing.T) {
+		// The heuristic generator is deterministic, so every request returns the same message
+		diff := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new\n"
+
+		result, err := GenerateCandidates(context.Background(), NewHeuristicClient(), &Request{Diff: diff}, 3)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(result) != 1 {
+			t.Errorf("Expected a single candidate, got %q", result)
+		}
+	})
+
+	t.Run("all requests fail", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": "Invalid API key"}`))
+		}))
+		defer server.Close()
+
+		client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m"})
+
+		_, err := GenerateCandidates(context.Background(), client, &Request{Diff: "some diff"}, 2)
+		if err == nil || !strings.Contains(err.Error(), "status 401") {
+			t.Errorf("Expected the 401 error, got %v", err)
+		}
+	})
+}
+
+func TestFallbackClient_GenerateCandidates(t *testing.T) {
+	failing := &APIError{Provider: "Test", StatusCode: http.StatusServiceUnavailable, Body: "unavailable"}
+	first := &failingClient{err: failing}
+	second := NewHeuristicClient()
+	diff := "diff --git a/README.md b/README.md\n--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-a\n+b"
+
+	var fallbacks []string
+	client := NewFallbackClient([]NamedClient{{Name: "first", Client: first}, {Name: "second", Client: second}}, func(failed, next string, err error) {
+		fallbacks = append(fallbacks, failed+" → "+next)
+	})
+
+	result, err := client.GenerateCandidates(context.Background(), &Request{Diff: diff}, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result) != 1 {
+		t.Errorf("Unexpected candidates %q", result)
+	}
+	if !reflect.DeepEqual(fallbacks, []string{"first → second"}) {
+		t.Errorf("Expected one fallback warning, got %q", fallbacks)
+	}
+	if client.LastUsed() != "second" {
+		t.Errorf("Expected second to be used, got %s", client.LastUsed())
+	}
+}
@@ -1,11 +1,45 @@
 package llm
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 // LLMClient defines the interface for interacting with language models
 type LLMClient interface {
-	// GenerateCommitMessage generates a commit message based on the provided diff
-	GenerateCommitMessage(ctx context.Context, diff string) (string, error)
+	// GenerateCommitMessage generates a commit message based on the provided request
+	GenerateCommitMessage(ctx context.Context, req *Request) (string, error)
+}
+
+// Request describes a single commit message generation
+type Request struct {
+	Diff  string   // The git diff to describe
+	Notes []string // Facts about the change detected locally that the message must reflect
+	// Intent is why the change was made, in the author's words. The diff cannot show it, so the
+	// prompt presents it as authoritative and the body must reflect it.
+	Intent string
+
+	// Summaries describe the parts of a diff too large to send, the prompt uses them instead of Diff
+	Summaries []string
+	// Summarize asks for a plain text summary of Diff, one part of a larger change, instead of a commit message
+	Summarize bool
+	// Options override the client's sampling and output settings for this request
+	Options Options
+	// History continues the conversation after the commit prompt, alternating the previous
+	// answers of the model and the user's refinement instructions
+	History []Message
+}
+
+// APIError is returned when a provider responds with a non-200 status
+type APIError struct {
+	Provider   string // Display name of the provider or endpoint
+	StatusCode int
+	Body       string // Raw response body
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API request failed with status %d: %s", e.Provider, e.StatusCode, e.Body)
 }
 
 // Message represents a single message in the conversation
@@ -16,11 +50,35 @@ type Message struct {
 
 // ChatCompletionRequest represents the request structure for chat completion APIs
 type ChatCompletionRequest struct {
-	Model       string    `json:"model"`
-	Messages    []Message `json:"messages"`
-	Temperature float64   `json:"temperature,omitempty"`
-	MaxTokens   int       `json:"max_tokens,omitempty"`
-	Stream      bool      `json:"stream,omitempty"`
+	Model          string          `json:"model"`
+	Messages       []Message       `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	TopP           *float64        `json:"top_p,omitempty"`
+	MaxTokens      int             `json:"max_tokens,omitempty"`
+	Seed           *int            `json:"seed,omitempty"`
+	Stop           []string        `json:"stop,omitempty"`
+	N              int             `json:"n,omitempty"` // number of alternative completions
+	Stream         bool            `json:"stream,omitempty"`
+	StreamOptions  *StreamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// StreamOptions configures a streamed chat completion
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"` // send token usage in a final event
+}
+
+// ResponseFormat constrains the output of a chat completion, e.g. to a JSON schema
+type ResponseFormat struct {
+	Type       string          `json:"type"` // "text", "json_object" or "json_schema"
+	JSONSchema *ResponseSchema `json:"json_schema,omitempty"`
+}
+
+// ResponseSchema is the named JSON schema of a "json_schema" response format
+type ResponseSchema struct {
+	Name   string      `json:"name"`
+	Strict bool        `json:"strict"`
+	Schema *JSONSchema `json:"schema"`
 }
 
 // ChatCompletionResponse represents the response structure from chat completion APIs
@@ -34,6 +92,9 @@ type ChatCompletionResponse struct {
 		Message struct {
 			Role    string `json:"role"`
 			Content string `json:"content"`
+			// Reasoning of reasoning models, "reasoning" on OpenRouter and "reasoning_content" on DeepSeek's API
+			Reasoning        string `json:"reasoning"`
+			ReasoningContent string `json:"reasoning_content"`
 		} `json:"message"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
@@ -43,3 +104,26 @@ type ChatCompletionResponse struct {
 		TotalTokens      int `json:"total_tokens"`
 	} `json:"usage"`
 }
+
+// ChatCompletionChunk represents a single server-sent event of a streamed chat completion
+type ChatCompletionChunk struct {
+	Choices []struct {
+		Index int `json:"index"`
+		Delta struct {
+			Role             string `json:"role"`
+			Content          string `json:"content"`
+			Reasoning        string `json:"reasoning"`
+			ReasoningContent string `json:"reasoning_content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	// Usage is sent in a final event without choices when requested with StreamOptions
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage,omitempty"`
+	// Some gateways report failures inside the stream after a 200 response
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
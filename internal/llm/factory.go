@@ -3,37 +3,207 @@ package llm
 import (
 	"fmt"
 
+	"github.com/siddhartha/rune/internal/aws"
 	"github.com/siddhartha/rune/internal/config"
+	"github.com/siddhartha/rune/internal/gcp"
+	"github.com/siddhartha/rune/internal/models"
 )
 
-// NewLLMClient creates a new LLM client based on the configuration
+// NewLLMClient creates a new LLM client based on the configuration. With structured_output,
+// models with the StructuredOutput capability are asked for JSON commit messages, otherwise
+// they answer in text, which can be streamed.
+// Requests use the configured timeout and the model's options from model_options, reasoning
+// models get a larger output limit unless one is configured.
 func NewLLMClient(cfg *config.Config) (LLMClient, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("configuration is required")
 	}
 
+	client, err := newProviderClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := cfg.ResolveModel("")
+	known := err == nil && model.Provider == cfg.Provider
+	if structured, ok := client.(structuredOutputClient); ok && known && model.StructuredOutput && cfg.StructuredOutput {
+		structured.setStructuredOutput(true)
+	}
+	if configurable, ok := client.(configurableClient); ok {
+		// The HTTP timeout matches the deadline of each generation, see config.GetTimeout
+		configurable.setTimeout(cfg.GetTimeout())
+		if known {
+			options := optionsFromConfig(cfg.OptionsFor(model))
+			if model.Reasoning && options.MaxTokens == 0 {
+				options.MaxTokens = ReasoningMaxTokens
+			}
+			if err := options.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid model_options for %s: %w", model.Name, err)
+			}
+			configurable.setOptions(options)
+		}
+	}
+	return client, nil
+}
+
+// optionsFromConfig converts the generation options of the config, nil means no options
+func optionsFromConfig(options *config.GenerationOptions) Options {
+	if options == nil {
+		return Options{}
+	}
+	return Options{
+		Temperature:  options.Temperature,
+		TopP:         options.TopP,
+		MaxTokens:    options.MaxTokens,
+		Seed:         options.Seed,
+		Stop:         options.Stop,
+		SystemPrompt: options.SystemPrompt,
+	}
+}
+
+// newProviderClient creates the client for the configured provider and model
+func newProviderClient(cfg *config.Config) (LLMClient, error) {
 	// Set the environment variable for the session
-	if err := cfg.SetEnvVar(); err != nil {
-		return nil, fmt.Errorf("failed to set environment variable: %w", err)
+	if cfg.NeedsAPIKey(cfg.Provider) {
+		if err := cfg.SetEnvVar(); err != nil {
+			return nil, fmt.Errorf("failed to set environment variable: %w", err)
+		}
 	}
 
 	switch cfg.Provider {
+	case config.ProviderNovita:
+		return NewNovitaClient(cfg.Model)
 	case config.ProviderGemini:
+		if cfg.Vertex.Enabled() {
+			return newVertexClient(cfg)
+		}
 		return NewGeminiClient(cfg.Model)
 	case config.ProviderOpenRouter:
 		return NewOpenRouterClient(cfg.Model)
+	case config.ProviderAnthropic:
+		return NewAnthropicClient(cfg.Model)
+	case config.ProviderHeuristic:
+		return NewHeuristicClient(), nil
+	case config.ProviderOllama:
+		return NewOllamaClient(cfg.GetOllamaHost(), cfg.Model), nil
+	case config.ProviderOpenAICompatible:
+		return newEndpointClient(cfg)
+	case config.ProviderAzure:
+		deployment, err := cfg.Azure.Deployment(cfg.Model)
+		if err != nil {
+			return nil, err
+		}
+		return NewAzureClient(cfg.Azure.GetEndpoint(), cfg.Azure.GetAPIVersion(), deployment)
+	case config.ProviderBedrock:
+		return newBedrockClient(cfg)
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", cfg.Provider)
 	}
 }
 
+// NewModelClient creates a client for a model that may belong to another provider than cfg.Provider.
+// cfg itself is not modified.
+func NewModelClient(cfg *config.Config, model *models.ModelInfo) (LLMClient, error) {
+	modelCfg := *cfg
+	modelCfg.Provider = model.Provider
+	modelCfg.Model = model.ID
+	if model.Endpoint != "" {
+		modelCfg.Endpoint = model.Endpoint
+	}
+	return NewLLMClient(&modelCfg)
+}
+
+// newVertexClient creates a Gemini client that authenticates to Vertex AI with a service account
+func newVertexClient(cfg *config.Config) (LLMClient, error) {
+	account, err := gcp.LoadServiceAccount(cfg.Vertex.GetCredentialsFile())
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := gcp.NewTokenSource(account)
+	if err != nil {
+		return nil, err
+	}
+
+	project := cfg.Vertex.GetProject()
+	if project == "" {
+		project = account.ProjectID
+	}
+	return NewVertexClient(project, cfg.Vertex.GetLocation(), cfg.Model, tokens)
+}
+
+// newEndpointClient creates a client for the configured OpenAI-compatible endpoint
+func newEndpointClient(cfg *config.Config) (LLMClient, error) {
+	endpoint, err := cfg.GetEndpoint(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := cfg.GetEndpointAPIKey(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = endpoint.Model
+	}
+
+	client, err := NewOpenAICompatibleClient(OpenAICompatibleConfig{
+		Name:       cfg.Endpoint,
+		BaseURL:    endpoint.BaseURL,
+		APIKey:     apiKey,
+		AuthStyle:  endpoint.AuthStyle,
+		AuthHeader: endpoint.AuthHeader,
+		Model:      model,
+		Headers:    endpoint.Headers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// newBedrockClient creates a Bedrock client with credentials from the AWS environment
+func newBedrockClient(cfg *config.Config) (LLMClient, error) {
+	credentials, err := aws.LoadCredentials(cfg.Bedrock.GetProfile())
+	if err != nil {
+		return nil, err
+	}
+
+	region := cfg.Bedrock.GetRegion()
+	if endpoint := cfg.Bedrock.GetEndpoint(); endpoint != "" {
+		return NewBedrockClientWithConfig(endpoint, region, cfg.Model, credentials), nil
+	}
+
+	client, err := NewBedrockClient(region, cfg.Model, credentials)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
 // GetProviderDisplayName returns a human-readable name for the provider
 func GetProviderDisplayName(provider string) string {
 	switch provider {
+	case config.ProviderNovita:
+		return "Novita.ai"
 	case config.ProviderGemini:
 		return "Google Gemini"
 	case config.ProviderOpenRouter:
 		return "OpenRouter"
+	case config.ProviderAnthropic:
+		return "Anthropic"
+	case config.ProviderAzure:
+		return "Azure OpenAI"
+	case config.ProviderBedrock:
+		return "AWS Bedrock"
+	case config.ProviderHeuristic:
+		return "Offline heuristic"
+	case config.ProviderOllama:
+		return "Ollama"
+	case config.ProviderOpenAICompatible:
+		return "OpenAI-compatible"
 	default:
 		return "Unknown"
 	}
@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/siddhartha/rune/internal/config"
+	"github.com/siddhartha/rune/internal/models"
+	"github.com/zalando/go-keyring"
+)
+
+func TestNewLLMClient(t *testing.T) {
+	keyring.MockInit()
+
+	tests := []struct {
+		provider  string
+		model     string
+		wantType  string
+		wantModel string
+	}{
+		{provider: config.ProviderNovita, model: "deepseek/deepseek-v3-0324", wantType: "*llm.QwenClient", wantModel: "deepseek/deepseek-v3-0324"},
+		{provider: config.ProviderNovita, model: "", wantType: "*llm.QwenClient", wantModel: defaultModel},
+		{provider: config.ProviderGemini, model: "gemini-1.5-pro", wantType: "*llm.GeminiClient", wantModel: "gemini-1.5-pro"},
+		{provider: config.ProviderOpenRouter, model: "qwen/qwq-32b-preview", wantType: "*llm.OpenRouterClient", wantModel: "qwen/qwq-32b-preview"},
+		{provider: config.ProviderAnthropic, model: "claude-sonnet-4-20250514", wantType: "*llm.AnthropicClient", wantModel: "claude-sonnet-4-20250514"},
+		{provider: config.ProviderOllama, model: "qwen2.5-coder:7b", wantType: "*llm.OllamaClient", wantModel: "qwen2.5-coder:7b"},
+		{provider: config.ProviderHeuristic, model: "heuristic", wantType: "*llm.HeuristicClient"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider+"/"+tt.model, func(t *testing.T) {
+			cfg := &config.Config{Provider: tt.provider, Model: tt.model}
+			if config.RequiresAPIKey(tt.provider) {
+				if err := cfg.SetAPIKey("test-key"); err != nil {
+					t.Fatalf("Failed to store API key: %v", err)
+				}
+				// SetEnvVar exports the key for the session, restore it after the test
+				t.Setenv(cfg.GetEnvVarName(), "")
+			}
+
+			client, err := NewLLMClient(cfg)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var gotType, gotModel string
+			var gotTimeout time.Duration
+			switch c := client.(type) {
+			case *QwenClient:
+				gotType, gotModel, gotTimeout = "*llm.QwenClient", c.model, c.httpClient.Timeout
+			case *GeminiClient:
+				gotType, gotModel, gotTimeout = "*llm.GeminiClient", c.model, c.httpClient.Timeout
+			case *OpenRouterClient:
+				gotType, gotModel, gotTimeout = "*llm.OpenRouterClient", c.model, c.httpClient.Timeout
+			case *AnthropicClient:
+				gotType, gotModel, gotTimeout = "*llm.AnthropicClient", c.model, c.httpClient.Timeout
+			case *OllamaClient:
+				gotType, gotModel, gotTimeout = "*llm.OllamaClient", c.model, c.httpClient.Timeout
+			case *HeuristicClient:
+				gotType, gotTimeout = "*llm.HeuristicClient", cfg.GetTimeout()
+			}
+
+			if gotType != tt.wantType {
+				t.Errorf("Expected %s, got %T", tt.wantType, client)
+			}
+			if gotModel != tt.wantModel {
+				t.Errorf("Expected model '%s', got '%s'", tt.wantModel, gotModel)
+			}
+			if gotTimeout != cfg.GetTimeout() {
+				t.Errorf("Expected the HTTP timeout to match the generation timeout %v, got %v", cfg.GetTimeout(), gotTimeout)
+			}
+		})
+	}
+}
+
+func TestNewLLMClient_Errors(t *testing.T) {
+	keyring.MockInit()
+
+	if _, err := NewLLMClient(nil); err == nil {
+		t.Error("Expected error for nil config")
+	}
+
+	// No key stored for the provider
+	_, err := NewLLMClient(&config.Config{Provider: config.ProviderNovita})
+	if err == nil || !strings.Contains(err.Error(), "failed to retrieve API key") {
+		t.Errorf("Expected missing key error, got %v", err)
+	}
+
+	// Vertex AI mode needs a service account, not an API key
+	_, err = NewLLMClient(&config.Config{Provider: config.ProviderGemini, Vertex: &config.VertexConfig{CredentialsFile: "/nonexistent/key.json"}})
+	if err == nil || !strings.Contains(err.Error(), "failed to load Google service account") {
+		t.Errorf("Expected service account error, got %v", err)
+	}
+
+	_, err = NewLLMClient(&config.Config{Provider: "unknown"})
+	if err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+}
+
+func TestNewModelClient(t *testing.T) {
+	keyring.MockInit()
+
+	cfg := &config.Config{Provider: config.ProviderNovita, Model: "qwen/qwen2.5-7b-instruct"}
+
+	// Without a stored key the fallback model cannot be used
+	gemini, err := models.FindModel("g")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := NewModelClient(cfg, gemini); err == nil {
+		t.Error("Expected error for a provider without API key")
+	}
+
+	heuristic, err := models.FindModel("heuristic")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	client, err := NewModelClient(cfg, heuristic)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := client.(*HeuristicClient); !ok {
+		t.Errorf("Expected *llm.HeuristicClient, got %T", client)
+	}
+	if cfg.Provider != config.ProviderNovita || cfg.Model != "qwen/qwen2.5-7b-instruct" {
+		t.Errorf("Expected config to be unchanged, got %s/%s", cfg.Provider, cfg.Model)
+	}
+}
+
+func TestGetProviderDisplayName(t *testing.T) {
+	if got := GetProviderDisplayName(config.ProviderNovita); got != "Novita.ai" {
+		t.Errorf("Expected 'Novita.ai', got '%s'", got)
+	}
+	if got := GetProviderDisplayName("unknown"); got != "Unknown" {
+		t.Errorf("Expected 'Unknown', got '%s'", got)
+	}
+}
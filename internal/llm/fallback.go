@@ -0,0 +1,165 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// NamedClient is a client together with the name of its model as shown to the user
+type NamedClient struct {
+	Name   string
+	Client LLMClient
+	// Check, if set, is called before the client is tried; an error skips the client,
+	// e.g. a paid model once the budget is used up
+	Check func() error
+}
+
+// FallbackClient tries its clients in order until one produces a commit message.
+// The next client is only tried when the failure is retryable elsewhere.
+type FallbackClient struct {
+	clients      []NamedClient
+	onFallback   func(failed, next string, err error)
+	entryTimeout time.Duration
+	used         string
+}
+
+// NewFallbackClient creates a client that falls back through clients in order.
+// onFallback, if set, is called with the failed and the next model before switching.
+func NewFallbackClient(clients []NamedClient, onFallback func(failed, next string, err error)) *FallbackClient {
+	return &FallbackClient{
+		clients:    clients,
+		onFallback: onFallback,
+	}
+}
+
+// SetEntryTimeout gives every client in the chain its own deadline of timeout instead of
+// the deadline of the caller's context, so that a primary that uses up its time leaves the
+// same time to the next model. Cancellation of the caller's context still stops every client.
+func (c *FallbackClient) SetEntryTimeout(timeout time.Duration) {
+	c.entryTimeout = timeout
+}
+
+// GenerateCommitMessage generates a commit message based on the provided request
+func (c *FallbackClient) GenerateCommitMessage(ctx context.Context, request *Request) (string, error) {
+	return c.StreamCommitMessage(ctx, request, nil)
+}
+
+// StreamCommitMessage streams from each client in turn until one succeeds
+func (c *FallbackClient) StreamCommitMessage(ctx context.Context, request *Request, onChunk StreamHandler) (string, error) {
+	var message string
+	err := c.try(ctx, func(ctx context.Context, client LLMClient) error {
+		var err error
+		message, err = Stream(ctx, client, request, onChunk)
+		return err
+	})
+	return message, err
+}
+
+// try calls generate with each client in order until one succeeds or fails fatally
+func (c *FallbackClient) try(ctx context.Context, generate func(ctx context.Context, client LLMClient) error) error {
+	if len(c.clients) == 0 {
+		return fmt.Errorf("no models available")
+	}
+
+	var lastErr error
+	for i, entry := range c.clients {
+		var err error
+		if entry.Check != nil {
+			err = entry.Check()
+		}
+		if err == nil {
+			clientCtx, cancel := c.entryContext(ctx, entry.Client)
+			err = generate(clientCtx, entry.Client)
+			cancel()
+			if err == nil {
+				c.used = entry.Name
+				return nil
+			}
+			if !IsRetryableElsewhere(err) {
+				if lastErr != nil && errors.Is(err, ErrSummaryNotSupported) {
+					// The heuristic at the end of the chain cannot take over summaries, report why the models failed
+					return lastErr
+				}
+				return err
+			}
+		}
+
+		lastErr = err
+		if i+1 < len(c.clients) && c.onFallback != nil {
+			c.onFallback(entry.Name, c.clients[i+1].Name, err)
+		}
+	}
+	return lastErr
+}
+
+// entryContext returns the context a client in the chain runs with. With an entry timeout it
+// has its own deadline and is only cancelled together with ctx when ctx is cancelled rather
+// than past its deadline.
+func (c *FallbackClient) entryContext(ctx context.Context, client LLMClient) (context.Context, context.CancelFunc) {
+	if c.entryTimeout > 0 {
+		entryCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.entryTimeout)
+		stop := context.AfterFunc(ctx, func() {
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				cancel()
+			}
+		})
+		return entryCtx, func() {
+			stop()
+			cancel()
+		}
+	}
+
+	if _, local := client.(*HeuristicClient); local && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// The heuristic is local, so it still works after the others used up the deadline
+		return context.WithoutCancel(ctx), func() {}
+	}
+	return ctx, func() {}
+}
+
+// GenerateCandidates asks each client in turn for n alternative commit messages until one succeeds
+func (c *FallbackClient) GenerateCandidates(ctx context.Context, request *Request, n int) ([]string, error) {
+	var candidates []string
+	err := c.try(ctx, func(ctx context.Context, client LLMClient) error {
+		var err error
+		candidates, err = GenerateCandidates(ctx, client, request, n)
+		return err
+	})
+	return candidates, err
+}
+
+// LastUsed returns the name of the model that produced the last message
+func (c *FallbackClient) LastUsed() string {
+	return c.used
+}
+
+// IsRetryableElsewhere reports whether another provider or model might succeed where err failed.
+// Rate limits, outages, rejected credentials, timeouts, network failures and truncated or blocked
+// answers are specific to a provider or model. Every other error, such as an invalid request or
+// cancellation by the user, would fail the same way on the next model and is returned at once.
+func IsRetryableElsewhere(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusRequestTimeout, http.StatusTooManyRequests:
+			return true
+		default:
+			return apiErr.StatusCode >= 500
+		}
+	}
+
+	var truncated *TruncatedError
+	var blocked *BlockedError
+	var netErr net.Error
+	return errors.As(err, &truncated) || errors.As(err, &blocked) || errors.As(err, &netErr)
+}
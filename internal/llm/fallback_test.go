@@ -0,0 +1,219 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// staticClient is an LLMClient that always returns the configured message
+type staticClient struct {
+	message string
+	calls   int
+}
+
+func (c *staticClient) GenerateCommitMessage(ctx context.Context, request *Request) (string, error) {
+	c.calls++
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return c.message, nil
+}
+
+func TestFallbackClient_Chain(t *testing.T) {
+	diff := "diff --git a/README.md b/README.md\n--- a/README.md\n+++ b/README.md\n@@ -1 +1 @@\n-a\n+b"
+	rateLimited := &APIError{Provider: "OpenRouter", StatusCode: http.StatusTooManyRequests, Body: "rate limited"}
+
+	t.Run("uses the first model that succeeds", func(t *testing.T) {
+		second := &staticClient{message: "Fix typo in README"}
+		third := &staticClient{message: "unused"}
+
+		var fallbacks []string
+		client := NewFallbackClient([]NamedClient{
+			{Name: "DeepSeek V3", Client: &failingClient{err: rateLimited}},
+			{Name: "Gemini 2.0 Flash", Client: second},
+			{Name: "Offline heuristic", Client: third},
+		}, func(failed, next string, err error) {
+			fallbacks = append(fallbacks, fmt.Sprintf("%s -> %s: %v", failed, next, err))
+		})
+
+		result, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: diff})
+		if err != nil || result != "Fix typo in README" {
+			t.Fatalf("Expected the second model's message, got %q (%v)", result, err)
+		}
+		if client.LastUsed() != "Gemini 2.0 Flash" {
+			t.Errorf("Expected LastUsed 'Gemini 2.0 Flash', got '%s'", client.LastUsed())
+		}
+		if third.calls != 0 {
+			t.Errorf("Expected the third model not to be called, got %d calls", third.calls)
+		}
+		if len(fallbacks) != 1 || !strings.HasPrefix(fallbacks[0], "DeepSeek V3 -> Gemini 2.0 Flash: OpenRouter API request failed with status 429") {
+			t.Errorf("Unexpected fallbacks %q", fallbacks)
+		}
+	})
+
+	t.Run("returns the last error when every model fails", func(t *testing.T) {
+		client := NewFallbackClient([]NamedClient{
+			{Name: "a", Client: &failingClient{err: rateLimited}},
+			{Name: "b", Client: &failingClient{err: errors.New("failed to make request: connection refused")}},
+		}, nil)
+
+		_, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: diff})
+		if err == nil || !strings.Contains(err.Error(), "connection refused") {
+			t.Errorf("Expected the last error, got %v", err)
+		}
+	})
+
+	t.Run("skips models whose check fails", func(t *testing.T) {
+		paid := &staticClient{message: "unused"}
+		free := &staticClient{message: "Fix typo in README"}
+		client := NewFallbackClient([]NamedClient{
+			{Name: "a", Client: &failingClient{err: rateLimited}},
+			{Name: "b", Client: paid, Check: func() error { return errors.New("monthly budget exceeded") }},
+			{Name: "c", Client: free},
+		}, nil)
+
+		result, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: diff})
+		if err != nil || result != "Fix typo in README" || paid.calls != 0 {
+			t.Errorf("Expected the check to skip b, got %q (%v, %d calls)", result, err, paid.calls)
+		}
+
+		client = NewFallbackClient([]NamedClient{
+			{Name: "a", Client: &failingClient{err: rateLimited}},
+			{Name: "b", Client: paid, Check: func() error { return errors.New("monthly budget exceeded") }},
+		}, nil)
+		if _, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: diff}); err == nil || err.Error() != "monthly budget exceeded" {
+			t.Errorf("Expected the error of the check, got %v", err)
+		}
+	})
+
+	t.Run("stops on a bad request", func(t *testing.T) {
+		next := &staticClient{message: "unused"}
+		client := NewFallbackClient([]NamedClient{
+			{Name: "a", Client: &failingClient{err: &APIError{Provider: "OpenAI", StatusCode: http.StatusBadRequest, Body: "invalid temperature"}}},
+			{Name: "b", Client: next},
+		}, nil)
+
+		_, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: diff})
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || next.calls != 0 {
+			t.Errorf("Expected the 400 without fallback, got %v (%d calls)", err, next.calls)
+		}
+	})
+
+	t.Run("stops on fatal errors", func(t *testing.T) {
+		next := &staticClient{message: "unused"}
+		client := NewFallbackClient([]NamedClient{
+			{Name: "a", Client: &failingClient{err: fmt.Errorf("failed to make request: %w", context.Canceled)}},
+			{Name: "b", Client: next},
+		}, nil)
+
+		_, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: diff})
+		if !errors.Is(err, context.Canceled) || next.calls != 0 {
+			t.Errorf("Expected cancellation without fallback, got %v (%d calls)", err, next.calls)
+		}
+	})
+
+	t.Run("heuristic runs after the deadline", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		remote := &staticClient{message: "unused"}
+		client := NewFallbackClient([]NamedClient{
+			{Name: "a", Client: &failingClient{err: context.DeadlineExceeded}},
+			{Name: "b", Client: remote},
+			{Name: "Offline heuristic", Client: NewHeuristicClient()},
+		}, nil)
+
+		result, err := client.GenerateCommitMessage(ctx, &Request{Diff: diff})
+		if err != nil || result != "docs: update README" {
+			t.Errorf("Expected heuristic message, got %q (%v)", result, err)
+		}
+		if client.LastUsed() != "Offline heuristic" {
+			t.Errorf("Expected the heuristic to be used, got '%s'", client.LastUsed())
+		}
+	})
+
+	t.Run("each model gets its own deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		second := &staticClient{message: "Fix typo in README"}
+		client := NewFallbackClient([]NamedClient{
+			{Name: "a", Client: &blockingClient{}},
+			{Name: "b", Client: second},
+		}, nil)
+		client.SetEntryTimeout(50 * time.Millisecond)
+
+		result, err := client.GenerateCommitMessage(ctx, &Request{Diff: diff})
+		if err != nil || result != "Fix typo in README" {
+			t.Errorf("Expected the second model's message after the first timed out, got %q (%v)", result, err)
+		}
+	})
+
+	t.Run("cancellation stops models with their own deadline", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		next := &staticClient{message: "unused"}
+		client := NewFallbackClient([]NamedClient{
+			{Name: "a", Client: &blockingClient{}},
+			{Name: "b", Client: next},
+		}, nil)
+		client.SetEntryTimeout(time.Minute)
+
+		_, err := client.GenerateCommitMessage(ctx, &Request{Diff: diff})
+		if !errors.Is(err, context.Canceled) || next.calls != 0 {
+			t.Errorf("Expected cancellation without fallback, got %v (%d calls)", err, next.calls)
+		}
+	})
+}
+
+// blockingClient is an LLMClient that waits until its context is done
+type blockingClient struct{}
+
+func (c *blockingClient) GenerateCommitMessage(ctx context.Context, request *Request) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestIsRetryableElsewhere(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "rate limited", err: &APIError{Provider: "OpenRouter", StatusCode: 429}, want: true},
+		{name: "service unavailable", err: fmt.Errorf("wrapped: %w", &APIError{Provider: "Gemini", StatusCode: 503}), want: true},
+		{name: "overloaded", err: &APIError{Provider: "Anthropic", StatusCode: 529}, want: true},
+		{name: "request timeout", err: &APIError{Provider: "Ollama", StatusCode: 408}, want: true},
+		{name: "invalid key", err: &APIError{Provider: "Anthropic", StatusCode: 401}, want: true},
+		{name: "forbidden", err: &APIError{Provider: "Bedrock", StatusCode: 403}, want: true},
+		{name: "bad request", err: &APIError{Provider: "OpenAI", StatusCode: 400}, want: false},
+		{name: "invalid parameters", err: fmt.Errorf("wrapped: %w", &APIError{Provider: "Gemini", StatusCode: 422}), want: false},
+		{name: "not found", err: &APIError{Provider: "OpenAI", StatusCode: 404}, want: false},
+		{name: "network error", err: fmt.Errorf("failed to make request: %w", &url.Error{Op: "Post", URL: "http://localhost", Err: errors.New("dial tcp: connection refused")}), want: true},
+		{name: "deadline", err: fmt.Errorf("failed to make request: %w", context.DeadlineExceeded), want: true},
+		{name: "truncated", err: &TruncatedError{Provider: "OpenAI", MaxTokens: 100}, want: true},
+		{name: "blocked", err: fmt.Errorf("wrapped: %w", &BlockedError{Provider: "Gemini", Reason: FinishSafety}), want: true},
+		{name: "cancelled", err: fmt.Errorf("failed to make request: %w", context.Canceled), want: false},
+		{name: "cancelled request", err: &url.Error{Op: "Post", URL: "http://localhost", Err: context.Canceled}, want: false},
+		{name: "no changes", err: ErrNoFileChanges, want: false},
+		{name: "refinement not supported", err: ErrRefinementNotSupported, want: false},
+		{name: "local error", err: errors.New("failed to marshal request: unsupported value"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableElsewhere(tt.err); got != tt.want {
+				t.Errorf("IsRetryableElsewhere(%v) = %v, expected %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
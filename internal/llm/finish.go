@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	// maxTruncationRetries bounds the retries of an answer cut off by the output limit
+	maxTruncationRetries = 2
+	// maxRetryTokens caps the output limit of those retries
+	maxRetryTokens = 16000
+)
+
+// FinishReason is why a provider stopped generating, normalized across providers
+type FinishReason string
+
+const (
+	FinishStop       FinishReason = "stop"       // the answer is complete
+	FinishLength     FinishReason = "length"     // the output limit cut the answer off
+	FinishSafety     FinishReason = "safety"     // a safety or content filter blocked the answer
+	FinishRecitation FinishReason = "recitation" // the answer repeated existing content too closely
+	FinishRefusal    FinishReason = "refusal"    // the model declined to answer
+	FinishOther      FinishReason = "other"      // any other reason, e.g. a malformed tool call
+)
+
+// finishReasons maps the values of finish_reason (OpenAI-compatible APIs and Ollama),
+// finishReason (Gemini) and stop_reason/stopReason (Anthropic and Bedrock)
+var finishReasons = map[string]FinishReason{
+	"":                     FinishStop, // streams and local servers may not report one
+	"stop":                 FinishStop,
+	"end_turn":             FinishStop,
+	"stop_sequence":        FinishStop,
+	"tool_use":             FinishStop, // the structured commit message
+	"tool_calls":           FinishStop,
+	"STOP":                 FinishStop,
+	"length":               FinishLength,
+	"max_tokens":           FinishLength,
+	"MAX_TOKENS":           FinishLength,
+	"content_filter":       FinishSafety,
+	"content_filtered":     FinishSafety,
+	"guardrail_intervened": FinishSafety,
+	"SAFETY":               FinishSafety,
+	"BLOCKLIST":            FinishSafety,
+	"PROHIBITED_CONTENT":   FinishSafety,
+	"SPII":                 FinishSafety,
+	"IMAGE_SAFETY":         FinishSafety,
+	"RECITATION":           FinishRecitation,
+	"refusal":              FinishRefusal,
+}
+
+// ParseFinishReason normalizes the finish reason reported by a provider
+func ParseFinishReason(raw string) FinishReason {
+	if reason, ok := finishReasons[raw]; ok {
+		return reason
+	}
+	return FinishOther
+}
+
+// TruncatedError is returned when the output limit cut the commit message off
+type TruncatedError struct {
+	Provider  string
+	MaxTokens int    // the output limit of the request
+	Partial   string // the text generated before the limit, without reasoning
+}
+
+// Error implements the error interface
+func (e *TruncatedError) Error() string {
+	if e.Partial == "" {
+		return fmt.Sprintf("%s used the whole output limit of %d tokens before answering", e.Provider, e.MaxTokens)
+	}
+	return fmt.Sprintf("commit message was truncated: %s reached the output limit of %d tokens", e.Provider, e.MaxTokens)
+}
+
+// BlockedError is returned when a provider blocked the prompt or the answer
+type BlockedError struct {
+	Provider string
+	Reason   FinishReason // FinishSafety, FinishRecitation or FinishRefusal
+	Raw      string       // the reason reported by the provider, e.g. "SAFETY"
+}
+
+// Error implements the error interface
+func (e *BlockedError) Error() string {
+	switch e.Reason {
+	case FinishRecitation:
+		return fmt.Sprintf("%s response was blocked because it recited existing content (%s)", e.Provider, e.Raw)
+	case FinishRefusal:
+		return fmt.Sprintf("%s: the model declined to generate a commit message", e.Provider)
+	default:
+		return fmt.Sprintf("%s response was blocked by the content filter (%s)", e.Provider, e.Raw)
+	}
+}
+
+// checkFinish turns the finish reason of a response into a typed error, nil when the answer
+// can be used. partial is the answer generated so far, maxTokens the output limit of the request.
+func checkFinish(provider, raw, partial string, maxTokens int) error {
+	switch reason := ParseFinishReason(raw); reason {
+	case FinishLength:
+		return &TruncatedError{Provider: provider, MaxTokens: maxTokens, Partial: partial}
+	case FinishSafety, FinishRecitation, FinishRefusal:
+		return &BlockedError{Provider: provider, Reason: reason, Raw: raw}
+	default:
+		return nil
+	}
+}
+
+// retryTruncated calls generate and, while the output limit cuts the answer off, calls it again
+// with twice the limit. Retries are reported to the retry notifier of ctx.
+func retryTruncated[T any](ctx context.Context, request *Request, generate func(request *Request) (T, error)) (T, error) {
+	result, err := generate(request)
+	for attempt := 1; attempt <= maxTruncationRetries; attempt++ {
+		var truncated *TruncatedError
+		if !errors.As(err, &truncated) || truncated.MaxTokens <= 0 || truncated.MaxTokens >= maxRetryTokens || ctx.Err() != nil {
+			break
+		}
+
+		retry := *request
+		retry.Options.MaxTokens = min(truncated.MaxTokens*2, maxRetryTokens)
+		if notify, ok := ctx.Value(retryNotifierKey{}).(func(RetryEvent)); ok && notify != nil {
+			notify(RetryEvent{Attempt: attempt, MaxAttempts: maxTruncationRetries + 1, MaxTokens: retry.Options.MaxTokens})
+		}
+		result, err = generate(&retry)
+	}
+	return result, err
+}
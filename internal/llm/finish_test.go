@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// truncatingClient cuts every answer off below an output limit of minTokens
+type truncatingClient struct {
+	minTokens int
+	limits    []int
+}
+
+func (c *truncatingClient) GenerateCommitMessage(ctx context.Context, request *Request) (string, error) {
+	limit := request.Options.maxTokens()
+	c.limits = append(c.limits, limit)
+	if limit < c.minTokens {
+		return "", &TruncatedError{Provider: "Test", MaxTokens: limit, Partial: "Add"}
+	}
+	return "Add feature", nil
+}
+
+func TestParseFinishReason(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected FinishReason
+	}{
+		{"", FinishStop},
+		{"stop", FinishStop},
+		{"end_turn", FinishStop},
+		{"STOP", FinishStop},
+		{"length", FinishLength},
+		{"max_tokens", FinishLength},
+		{"MAX_TOKENS", FinishLength},
+		{"content_filter", FinishSafety},
+		{"guardrail_intervened", FinishSafety},
+		{"SAFETY", FinishSafety},
+		{"RECITATION", FinishRecitation},
+		{"refusal", FinishRefusal},
+		{"MALFORMED_FUNCTION_CALL", FinishOther},
+	}
+
+	for _, tt := range tests {
+		if got := ParseFinishReason(tt.raw); got != tt.expected {
+			t.Errorf("Expected ParseFinishReason(%q) = %s, got %s", tt.raw, tt.expected, got)
+		}
+	}
+}
+
+func TestStream_RetriesTruncatedAnswers(t *testing.T) {
+	client := &truncatingClient{minTokens: 4000}
+
+	var events []RetryEvent
+	ctx := WithRetryNotifier(context.Background(), func(event RetryEvent) {
+		events = append(events, event)
+	})
+
+	message, err := Stream(ctx, client, &Request{Diff: "diff"}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if message != "Add feature" {
+		t.Errorf("Expected the complete message, got %q", message)
+	}
+	if len(client.limits) != 3 || client.limits[1] != 2000 || client.limits[2] != 4000 {
+		t.Errorf("Expected the limit to double on each retry, got %v", client.limits)
+	}
+	if len(events) != 2 || events[0].MaxTokens != 2000 || events[0].String() != "Answer was cut off, retrying with 2000 output tokens (attempt 2 of 3)..." {
+		t.Errorf("Unexpected retry events %+v", events)
+	}
+}
+
+func TestStream_TruncatedAfterRetries(t *testing.T) {
+	client := &truncatingClient{minTokens: maxRetryTokens + 1}
+
+	_, err := Stream(context.Background(), client, &Request{Diff: "diff", Options: Options{MaxTokens: 6000}}, nil)
+
+	var truncated *TruncatedError
+	if !errors.As(err, &truncated) {
+		t.Fatalf("Expected a TruncatedError, got %v", err)
+	}
+	if len(client.limits) != 3 || client.limits[2] != maxRetryTokens {
+		t.Errorf("Expected retries up to %d tokens, got %v", maxRetryTokens, client.limits)
+	}
+	if !IsRetryableElsewhere(err) {
+		t.Error("Expected another model to be tried after a truncated answer")
+	}
+}
+
+func TestCheckFinish(t *testing.T) {
+	if err := checkFinish("Test", "stop", "Add x", 1000); err != nil {
+		t.Errorf("Expected no error for a complete answer, got %v", err)
+	}
+
+	var blocked *BlockedError
+	if err := checkFinish("Test", "SAFETY", "", 1000); !errors.As(err, &blocked) || blocked.Reason != FinishSafety {
+		t.Errorf("Expected a safety BlockedError, got %v", err)
+	}
+	if err := checkFinish("Test", "refusal", "", 1000); err == nil || err.Error() != "Test: the model declined to generate a commit message" {
+		t.Errorf("Expected a refusal, got %v", err)
+	}
+
+	var truncated *TruncatedError
+	if err := checkFinish("Test", "length", "Add", 500); !errors.As(err, &truncated) || truncated.MaxTokens != 500 || truncated.Partial != "Add" {
+		t.Errorf("Expected a TruncatedError, got %v", err)
+	}
+}
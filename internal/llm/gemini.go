@@ -7,21 +7,31 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/siddhartha/rune/internal/gcp"
 )
 
 const (
 	// Gemini API base URL (model will be appended)
 	geminiAPIBaseURL   = "https://generativelanguage.googleapis.com/v1beta/models"
 	defaultGeminiModel = "gemini-2.0-flash-exp"
+	// Vertex AI publisher model path; host and location are filled in per region
+	vertexModelPath = "v1/projects/%s/locations/%s/publishers/google/models/%s:generateContent"
 )
 
-// GeminiClient implements the LLMClient interface for Google Gemini models
+// GeminiClient implements the LLMClient interface for Google Gemini models.
+// It talks to the Gemini API with an API key, or to Vertex AI with service-account tokens.
 type GeminiClient struct {
 	apiKey     string
 	baseURL    string
 	model      string
+	tokens     *gcp.TokenSource // set in Vertex AI mode
+	structured bool             // ask for JSON matching CommitMessageSchema
+	options    Options
 	httpClient *http.Client
 }
 
@@ -31,6 +41,16 @@ type GeminiRequest struct {
 	GenerationConfig *GeminiGenerationConfig `json:"generationConfig,omitempty"`
 }
 
+// GeminiCountTokensRequest is the request of the countTokens endpoint
+type GeminiCountTokensRequest struct {
+	Contents []GeminiContent `json:"contents"`
+}
+
+// GeminiCountTokensResponse is the response of the countTokens endpoint
+type GeminiCountTokensResponse struct {
+	TotalTokens int `json:"totalTokens"`
+}
+
 // GeminiContent represents content in Gemini API
 type GeminiContent struct {
 	Parts []GeminiPart `json:"parts"`
@@ -39,18 +59,42 @@ type GeminiContent struct {
 
 // GeminiPart represents a part of content in Gemini API
 type GeminiPart struct {
-	Text string `json:"text"`
+	Text    string `json:"text"`
+	Thought bool   `json:"thought,omitempty"` // set on the reasoning of thinking models
 }
 
 // GeminiGenerationConfig represents generation configuration for Gemini API
 type GeminiGenerationConfig struct {
-	Temperature     float64 `json:"temperature,omitempty"`
-	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
+	Temperature     float64  `json:"temperature"`
+	TopP            *float64 `json:"topP,omitempty"`
+	MaxOutputTokens int      `json:"maxOutputTokens,omitempty"`
+	Seed            *int     `json:"seed,omitempty"`
+	StopSequences   []string `json:"stopSequences,omitempty"`
+	CandidateCount  int      `json:"candidateCount,omitempty"`
+
+	// Structured output, e.g. "application/json" with a schema in Gemini's OpenAPI subset
+	ResponseMimeType string      `json:"responseMimeType,omitempty"`
+	ResponseSchema   *JSONSchema `json:"responseSchema,omitempty"`
 }
 
 // GeminiResponse represents the response structure from Gemini API
 type GeminiResponse struct {
-	Candidates []GeminiCandidate `json:"candidates"`
+	Candidates     []GeminiCandidate     `json:"candidates"`
+	PromptFeedback *GeminiPromptFeedback `json:"promptFeedback,omitempty"`
+	UsageMetadata  *GeminiUsageMetadata  `json:"usageMetadata,omitempty"`
+}
+
+// GeminiPromptFeedback explains why Gemini returned no candidates for a prompt
+type GeminiPromptFeedback struct {
+	BlockReason string `json:"blockReason"` // e.g. "SAFETY" or "PROHIBITED_CONTENT"
+}
+
+// GeminiUsageMetadata reports the tokens used by a Gemini request
+type GeminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	ThoughtsTokenCount   int `json:"thoughtsTokenCount"` // billed as output tokens
+	TotalTokenCount      int `json:"totalTokenCount"`
 }
 
 // GeminiCandidate represents a candidate response from Gemini API
@@ -74,12 +118,10 @@ func NewGeminiClient(model string) (*GeminiClient, error) {
 	baseURL := fmt.Sprintf("%s/%s:generateContent", geminiAPIBaseURL, model)
 
 	return &GeminiClient{
-		apiKey:  apiKey,
-		baseURL: baseURL,
-		model:   model,
-		httpClient: &http.Client{
-			Timeout: defaultTimeout,
-		},
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		model:      model,
+		httpClient: newHTTPClient(defaultTimeout),
 	}, nil
 
 }
@@ -96,55 +138,107 @@ func NewGeminiClientWithConfig(apiKey, baseURL, model string) *GeminiClient {
 	}
 
 	return &GeminiClient{
-		apiKey:  apiKey,
-		baseURL: baseURL,
-		model:   model,
-		httpClient: &http.Client{
-			Timeout: defaultTimeout,
-		},
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		model:      model,
+		httpClient: newHTTPClient(defaultTimeout),
 	}
 }
 
-// GenerateCommitMessage generates a commit message based on the provided diff
-func (c *GeminiClient) GenerateCommitMessage(ctx context.Context, diff string) (string, error) {
-	prompt := BuildCommitPrompt(diff)
+// NewVertexClient creates a GeminiClient that calls Gemini models through Vertex AI
+func NewVertexClient(project, location, model string, tokens *gcp.TokenSource) (*GeminiClient, error) {
+	if project == "" {
+		return nil, fmt.Errorf("Google Cloud project is required for Vertex AI")
+	}
+	if location == "" {
+		return nil, fmt.Errorf("Google Cloud location is required for Vertex AI")
+	}
+	if model == "" {
+		model = defaultGeminiModel
+	}
 
-	// Create the request payload using Gemini's format
-	reqBody := GeminiRequest{
-		Contents: []GeminiContent{
-			{
-				Parts: []GeminiPart{
-					{
-						Text: "You are a helpful assistant that generates concise, descriptive Git commit messages following GitHub conventions.\n\n" + prompt,
-					},
-				},
-				Role: "user",
-			},
-		},
-		GenerationConfig: &GeminiGenerationConfig{
-			Temperature:     0.3,
-			MaxOutputTokens: 1000,
-		},
+	return NewVertexClientWithConfig(vertexURL(project, location, model), model, tokens), nil
+}
+
+// NewVertexClientWithConfig creates a Vertex AI GeminiClient with a custom generateContent URL
+func NewVertexClientWithConfig(baseURL, model string, tokens *gcp.TokenSource) *GeminiClient {
+	client := NewGeminiClientWithConfig("", baseURL, model)
+	client.tokens = tokens
+	return client
+}
+
+// vertexURL returns the generateContent URL of a Gemini model in a Vertex AI location
+func vertexURL(project, location, model string) string {
+	host := "https://" + location + "-aiplatform.googleapis.com"
+	if location == "global" {
+		host = "https://aiplatform.googleapis.com"
 	}
+	return host + "/" + fmt.Sprintf(vertexModelPath, url.PathEscape(project), url.PathEscape(location), url.PathEscape(model))
+}
 
-	jsonBody, err := json.Marshal(reqBody)
+// GenerateCommitMessage generates a commit message based on the provided request
+func (c *GeminiClient) GenerateCommitMessage(ctx context.Context, request *Request) (string, error) {
+	response, err := c.generate(ctx, request, 1)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", err
+	}
+
+	candidate := response.Candidates[0]
+	commitMsg := candidateText(candidate)
+	if err := checkFinish(c.providerName(), candidate.FinishReason, commitMsg, c.maxTokens(request)); err != nil {
+		return "", err
+	}
+	if len(candidate.Content.Parts) == 0 {
+		return "", fmt.Errorf("no parts in candidate content")
+	}
+	if commitMsg == "" {
+		return "", fmt.Errorf("empty commit message received")
 	}
 
-	// Add API key as query parameter for Gemini
-	url := c.baseURL + "?key=" + c.apiKey
+	return commitMsg, nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonBody))
+// GenerateCandidates asks for n alternative commit messages in one request using candidateCount
+func (c *GeminiClient) GenerateCandidates(ctx context.Context, request *Request, n int) ([]string, error) {
+	response, err := c.generate(ctx, request, n)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	var candidates []string
+	var firstErr error
+	for _, candidate := range response.Candidates {
+		message := candidateText(candidate)
+		if err := checkFinish(c.providerName(), candidate.FinishReason, message, c.maxTokens(request)); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if message != "" {
+			candidates = append(candidates, message)
+		}
+	}
+	if len(candidates) == 0 {
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return nil, fmt.Errorf("empty commit message received")
+	}
+	return candidates, nil
+}
+
+// generate calls generateContent and returns a response with at least one candidate
+func (c *GeminiClient) generate(ctx context.Context, request *Request, candidateCount int) (*GeminiResponse, error) {
+	req, err := c.newRequest(ctx, c.baseURL, request, candidateCount)
+	if err != nil {
+		return nil, err
+	}
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to make request: %w", err)
+		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -154,32 +248,296 @@ func (c *GeminiClient) GenerateCommitMessage(ctx context.Context, diff string) (
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, &APIError{Provider: c.providerName(), StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	var response GeminiResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
+	c.recordUsage(ctx, response.UsageMetadata, time.Since(start))
 
 	// Extract the message from Gemini's response format
 	if len(response.Candidates) == 0 {
-		return "", fmt.Errorf("no candidates in response")
+		if err := c.promptBlocked(&response); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("no candidates in response")
 	}
 
-	candidate := response.Candidates[0]
-	if len(candidate.Content.Parts) == 0 {
-		return "", fmt.Errorf("no parts in candidate content")
+	return &response, nil
+}
+
+// StreamCommitMessage generates a commit message with streamGenerateContent,
+// passing text to onChunk as it is generated
+func (c *GeminiClient) StreamCommitMessage(ctx context.Context, request *Request, onChunk StreamHandler) (string, error) {
+	req, err := c.newRequest(ctx, c.streamURL(), request, 1)
+	if err != nil {
+		return "", err
 	}
+	req.Header.Set("Accept", "text/event-stream")
 
-	commitMsg := strings.TrimSpace(candidate.Content.Parts[0].Text)
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close response body: %v\n", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", fmt.Errorf("failed to read response: %w", err)
+		}
+		return "", &APIError{Provider: c.providerName(), StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	// Every event is a partial GenerateContentResponse carrying the next piece of text.
+	// Usage metadata is cumulative, the last event has the totals.
+	var text strings.Builder
+	var usage *GeminiUsageMetadata
+	var finishReason string
+	err = readSSE(resp.Body, func(data string) error {
+		var response GeminiResponse
+		if err := json.Unmarshal([]byte(data), &response); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+		if response.UsageMetadata != nil {
+			usage = response.UsageMetadata
+		}
+		if len(response.Candidates) == 0 {
+			return c.promptBlocked(&response)
+		}
+		if response.Candidates[0].FinishReason != "" {
+			finishReason = response.Candidates[0].FinishReason
+		}
+		for _, part := range response.Candidates[0].Content.Parts {
+			if part.Text == "" || part.Thought {
+				continue
+			}
+			text.WriteString(part.Text)
+			if onChunk != nil {
+				onChunk(part.Text)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read response stream: %w", err)
+	}
+	c.recordUsage(ctx, usage, time.Since(start))
+
+	commitMsg := strings.TrimSpace(text.String())
+	if err := checkFinish(c.providerName(), finishReason, commitMsg, c.maxTokens(request)); err != nil {
+		return "", err
+	}
 	if commitMsg == "" {
 		return "", fmt.Errorf("empty commit message received")
 	}
 
 	return commitMsg, nil
 }
+
+// streamURL returns the streamGenerateContent URL matching baseURL, with server-sent events
+func (c *GeminiClient) streamURL() string {
+	streamURL := strings.Replace(c.baseURL, ":generateContent", ":streamGenerateContent", 1)
+	if strings.Contains(streamURL, "?") {
+		return streamURL + "&alt=sse"
+	}
+	return streamURL + "?alt=sse"
+}
+
+// CountTokens counts the prompt tokens of a request with the countTokens endpoint, which is free
+func (c *GeminiClient) CountTokens(ctx context.Context, request *Request) (int, error) {
+	jsonBody, err := json.Marshal(GeminiCountTokensRequest{Contents: c.requestBody(request, 1).Contents})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := strings.Replace(c.baseURL, ":generateContent", ":countTokens", 1)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.authorize(ctx, req); err != nil {
+		return 0, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close response body: %v\n", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, &APIError{Provider: c.providerName(), StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var response GeminiCountTokensResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return response.TotalTokens, nil
+}
+
+// newRequest creates an authenticated generateContent request for candidateCount commit messages
+func (c *GeminiClient) newRequest(ctx context.Context, endpoint string, request *Request, candidateCount int) (*http.Request, error) {
+	jsonBody, err := json.Marshal(c.requestBody(request, candidateCount))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.authorize(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// requestBody builds the generateContent payload for candidateCount commit messages
+func (c *GeminiClient) requestBody(request *Request, candidateCount int) GeminiRequest {
+	structured := wantsJSON(c.structured, request)
+	prompt := BuildCommitPrompt(request)
+	if structured {
+		prompt = BuildStructuredCommitPrompt(request)
+	}
+
+	// Create the request payload using Gemini's format
+	options := c.options.Merge(request.Options)
+	reqBody := GeminiRequest{
+		Contents: geminiContents(conversation(options.systemPrompt(defaultSystemPrompt)+"\n\n"+prompt, request)),
+		GenerationConfig: &GeminiGenerationConfig{
+			Temperature:     options.temperature(),
+			TopP:            options.TopP,
+			MaxOutputTokens: options.maxTokens(),
+			Seed:            options.Seed,
+			StopSequences:   options.Stop,
+		},
+	}
+	if candidateCount > 1 {
+		reqBody.GenerationConfig.CandidateCount = candidateCount
+	}
+	if structured {
+		reqBody.GenerationConfig.ResponseMimeType = "application/json"
+		reqBody.GenerationConfig.ResponseSchema = geminiSchema(CommitMessageSchema())
+	}
+	return reqBody
+}
+
+// authorize adds credentials to the request. The API key goes in a header
+// rather than the query string so it does not end up in proxy logs or error messages.
+func (c *GeminiClient) authorize(ctx context.Context, req *http.Request) error {
+	if c.tokens == nil {
+		req.Header.Set("x-goog-api-key", c.apiKey)
+		return nil
+	}
+
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// promptBlocked returns a BlockedError when Gemini refused the prompt itself
+func (c *GeminiClient) promptBlocked(response *GeminiResponse) error {
+	if response.PromptFeedback == nil || response.PromptFeedback.BlockReason == "" {
+		return nil
+	}
+	raw := response.PromptFeedback.BlockReason
+	reason := ParseFinishReason(raw)
+	if reason != FinishRecitation {
+		reason = FinishSafety
+	}
+	return &BlockedError{Provider: c.providerName(), Reason: reason, Raw: raw}
+}
+
+// maxTokens returns the output limit sent with request
+func (c *GeminiClient) maxTokens(request *Request) int {
+	return c.options.Merge(request.Options).maxTokens()
+}
+
+// candidateText joins the text parts of a candidate, leaving out the reasoning of thinking models
+func candidateText(candidate GeminiCandidate) string {
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		if !part.Thought {
+			text.WriteString(part.Text)
+		}
+	}
+	return strings.TrimSpace(text.String())
+}
+
+// setOptions sets the defaults of every request
+func (c *GeminiClient) setOptions(options Options) {
+	c.options = options
+}
+
+// setTimeout bounds each request, including its retries
+func (c *GeminiClient) setTimeout(timeout time.Duration) {
+	c.httpClient.Timeout = timeout
+}
+
+// setStructuredOutput switches between JSON and free text commit messages
+func (c *GeminiClient) setStructuredOutput(enabled bool) {
+	c.structured = enabled
+}
+
+// structuredOutput reports whether commit messages are requested as JSON
+func (c *GeminiClient) structuredOutput() bool {
+	return c.structured
+}
+
+// recordUsage reports the token counts of a response
+func (c *GeminiClient) recordUsage(ctx context.Context, metadata *GeminiUsageMetadata, latency time.Duration) {
+	usage := Usage{Provider: c.providerName(), Model: c.model, Latency: latency}
+	if metadata != nil {
+		usage.PromptTokens = metadata.PromptTokenCount
+		usage.CompletionTokens = metadata.CandidatesTokenCount + metadata.ThoughtsTokenCount
+	}
+	recordUsage(ctx, usage)
+}
+
+// providerName returns the name used in error messages and Usage
+func (c *GeminiClient) providerName() string {
+	if c.tokens != nil {
+		return "Vertex AI"
+	}
+	return "Gemini"
+}
+
+// geminiContents converts conversation turns to Gemini contents, which call the assistant "model"
+func geminiContents(turns []Message) []GeminiContent {
+	contents := make([]GeminiContent, len(turns))
+	for i, turn := range turns {
+		role := turn.Role
+		if role == "assistant" {
+			role = "model"
+		}
+		contents[i] = GeminiContent{Parts: []GeminiPart{{Text: turn.Content}}, Role: role}
+	}
+	return contents
+}
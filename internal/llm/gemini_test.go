@@ -0,0 +1,203 @@
+package llm
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/siddhartha/rune/internal/gcp"
+)
+
+func TestGeminiClient_GenerateCommitMessage(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   string
+		expectedMsg    string
+		expectedError  string
+	}{
+		{
+			name:           "successful response",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"candidates": [{"content": {"parts": [{"text": "Add Hello world print statement\n"}], "role": "model"}, "finishReason": "STOP"}]}`,
+			expectedMsg:    "Add Hello world print statement",
+		},
+		{
+			name:           "multi-part response",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"candidates": [{"content": {"parts": [{"text": "Add greeting\n\n"}, {"text": "Print hello on startup."}], "role": "model"}, "finishReason": "STOP"}]}`,
+			expectedMsg:    "Add greeting\n\nPrint hello on startup.",
+		},
+		{
+			name:           "thought part",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"candidates": [{"content": {"parts": [{"text": "The diff adds a print", "thought": true}, {"text": "Add greeting"}], "role": "model"}, "finishReason": "STOP"}]}`,
+			expectedMsg:    "Add greeting",
+		},
+		{
+			name:           "API error response",
+			responseStatus: http.StatusBadRequest,
+			responseBody:   `{"error": {"code": 400, "message": "API key not valid.", "status": "INVALID_ARGUMENT"}}`,
+			expectedError:  "Gemini API request failed with status 400",
+		},
+		{
+			name:           "no candidates",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"candidates": []}`,
+			expectedError:  "no candidates in response",
+		},
+		{
+			name:           "truncated",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"candidates": [{"content": {"parts": [{"text": "Add Hello"}], "role": "model"}, "finishReason": "MAX_TOKENS"}]}`,
+			expectedError:  "commit message was truncated: Gemini reached the output limit of 1000 tokens",
+		},
+		{
+			name:           "safety block",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"candidates": [{"content": {"role": "model"}, "finishReason": "SAFETY"}]}`,
+			expectedError:  "Gemini response was blocked by the content filter (SAFETY)",
+		},
+		{
+			name:           "recitation",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"candidates": [{"content": {"role": "model"}, "finishReason": "RECITATION"}]}`,
+			expectedError:  "recited existing content (RECITATION)",
+		},
+		{
+			name:           "prompt blocked",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"promptFeedback": {"blockReason": "PROHIBITED_CONTENT"}}`,
+			expectedError:  "blocked by the content filter (PROHIBITED_CONTENT)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.RawQuery != "" {
+					t.Errorf("Expected no query string, got %q", r.URL.RawQuery)
+				}
+				if r.Header.Get("x-goog-api-key") != "test-key" {
+					t.Errorf("Expected API key header, got %q", r.Header.Get("x-goog-api-key"))
+				}
+				if r.Header.Get("Authorization") != "" {
+					t.Error("Expected no Authorization header in API key mode")
+				}
+
+				w.WriteHeader(tt.responseStatus)
+				if _, err := w.Write([]byte(tt.responseBody)); err != nil {
+					t.Errorf("Failed to write response body: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			client := NewGeminiClientWithConfig("test-key", server.URL+"/v1beta/models/gemini-2.0-flash:generateContent", "gemini-2.0-flash")
+			result, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: "some diff"})
+
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Errorf("Expected error containing '%s', got nil", tt.expectedError)
+				} else if !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("Expected error containing '%s', got '%s'", tt.expectedError, err.Error())
+				} else if strings.Contains(err.Error(), "test-key") {
+					t.Errorf("API key leaked into error: %s", err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if result != tt.expectedMsg {
+				t.Errorf("Expected message '%s', got '%s'", tt.expectedMsg, result)
+			}
+		})
+	}
+}
+
+func TestGeminiClient_Vertex(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	account := &gcp.ServiceAccount{
+		Type:        "service_account",
+		ProjectID:   "my-project",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		ClientEmail: "rune@my-project.iam.gserviceaccount.com",
+	}
+
+	var tokenRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			if _, err := w.Write([]byte(`{"access_token": "ya29.vertex", "expires_in": 3600}`)); err != nil {
+				t.Errorf("Failed to write response body: %v", err)
+			}
+		case "/v1/projects/my-project/locations/europe-west4/publishers/google/models/gemini-2.0-flash:generateContent":
+			if r.Header.Get("Authorization") != "Bearer ya29.vertex" {
+				t.Errorf("Expected bearer token, got %q", r.Header.Get("Authorization"))
+			}
+			if r.Header.Get("x-goog-api-key") != "" {
+				t.Error("Expected no API key header in Vertex AI mode")
+			}
+			if _, err := w.Write([]byte(`{"candidates": [{"content": {"parts": [{"text": "Fix login redirect"}], "role": "model"}, "finishReason": "STOP"}]}`)); err != nil {
+				t.Errorf("Failed to write response body: %v", err)
+			}
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tokens, err := gcp.NewTokenSource(account)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	tokens.TokenURL = server.URL + "/token"
+
+	baseURL := strings.Replace(vertexURL("my-project", "europe-west4", "gemini-2.0-flash"), "https://europe-west4-aiplatform.googleapis.com", server.URL, 1)
+	client := NewVertexClientWithConfig(baseURL, "gemini-2.0-flash", tokens)
+
+	for i := 0; i < 2; i++ {
+		result, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: "some diff"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result != "Fix login redirect" {
+			t.Errorf("Expected message 'Fix login redirect', got '%s'", result)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("Expected one token request, got %d", tokenRequests)
+	}
+}
+
+func TestVertexURL(t *testing.T) {
+	tests := []struct {
+		location string
+		expected string
+	}{
+		{"us-central1", "https://us-central1-aiplatform.googleapis.com/v1/projects/p/locations/us-central1/publishers/google/models/gemini-2.0-flash:generateContent"},
+		{"global", "https://aiplatform.googleapis.com/v1/projects/p/locations/global/publishers/google/models/gemini-2.0-flash:generateContent"},
+	}
+
+	for _, tt := range tests {
+		if got := vertexURL("p", tt.location, "gemini-2.0-flash"); got != tt.expected {
+			t.Errorf("vertexURL(%s) = %s, expected %s", tt.location, got, tt.expected)
+		}
+	}
+
+	if _, err := NewVertexClient("", "us-central1", "", nil); err == nil {
+		t.Error("Expected error without project")
+	}
+}
@@ -0,0 +1,277 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/siddhartha/rune/internal/git"
+)
+
+const (
+	// HeuristicModel is the model ID of the offline heuristic generator
+	HeuristicModel = "heuristic"
+	// heuristicSubjectLength is the subject length the heuristic tries to stay under
+	heuristicSubjectLength = 50
+)
+
+// ErrNoFileChanges is returned when the diff contains no file changes to describe
+var ErrNoFileChanges = errors.New("no file changes found in diff")
+
+// genericDirs are directory names too broad to be a useful commit scope
+var genericDirs = map[string]bool{
+	"internal": true, "pkg": true, "src": true, "lib": true, "cmd": true, "app": true,
+	"test": true, "tests": true, "docs": true, "doc": true, ".github": true,
+}
+
+// HeuristicClient implements the LLMClient interface without a language model.
+// It derives a conventional commit message from the structure of the diff, so it
+// works offline and without an API key.
+type HeuristicClient struct{}
+
+// NewHeuristicClient creates a new HeuristicClient
+func NewHeuristicClient() *HeuristicClient {
+	return &HeuristicClient{}
+}
+
+// GenerateCommitMessage generates a commit message based on the provided request
+func (c *HeuristicClient) GenerateCommitMessage(ctx context.Context, request *Request) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if len(request.History) > 0 {
+		return "", ErrRefinementNotSupported
+	}
+	if request.Summarize {
+		return "", ErrSummaryNotSupported
+	}
+
+	files := git.ParseDiff(request.Diff)
+	if len(files) == 0 {
+		return "", ErrNoFileChanges
+	}
+
+	header := inferType(files)
+	if scope := inferScope(files); scope != "" {
+		header += "(" + scope + ")"
+	}
+
+	message := header + ": " + describeChanges(files, heuristicSubjectLength-len(header)-2)
+	if len(files) > 1 {
+		message += "\n\n" + summarizeFiles(files)
+	}
+	return message, nil
+}
+
+// inferType picks the conventional commit type from the kinds of files touched
+func inferType(files []*git.FileDiff) string {
+	allTests, allDocs, allCI, allRenames := true, true, true, true
+	addsSource := false
+
+	for _, file := range files {
+		p := file.Path()
+		allTests = allTests && isTestFile(p)
+		allDocs = allDocs && isDocFile(p)
+		allCI = allCI && isCIFile(p)
+		added, removed := file.Stats()
+		allRenames = allRenames && file.Status == git.StatusRenamed && added+removed == 0
+		addsSource = addsSource || (file.Status == git.StatusAdded && !isTestFile(p) && !isDocFile(p))
+	}
+
+	switch {
+	case allTests:
+		return "test"
+	case allDocs:
+		return "docs"
+	case allCI:
+		return "ci"
+	case allRenames:
+		return "refactor"
+	case addsSource:
+		return "feat"
+	default:
+		return "chore"
+	}
+}
+
+// inferScope returns the most specific directory shared by all files, if it is meaningful
+func inferScope(files []*git.FileDiff) string {
+	common := path.Dir(files[0].Path())
+	for _, file := range files {
+		common = commonDir(common, path.Dir(file.Path()))
+		if file.Status == git.StatusRenamed {
+			common = commonDir(common, path.Dir(file.OldPath))
+		}
+	}
+
+	if common == "." || common == "" {
+		return ""
+	}
+	scope := path.Base(common)
+	if genericDirs[scope] {
+		return ""
+	}
+	return scope
+}
+
+// commonDir returns the longest directory prefix shared by a and b
+func commonDir(a, b string) string {
+	aParts, bParts := strings.Split(a, "/"), strings.Split(b, "/")
+	var shared []string
+	for i := 0; i < len(aParts) && i < len(bParts) && aParts[i] == bParts[i]; i++ {
+		shared = append(shared, aParts[i])
+	}
+	return strings.Join(shared, "/")
+}
+
+// describeChanges builds a subject description such as "update formatter and add wrap tests",
+// falling back to file counts when the full description does not fit in maxLength
+func describeChanges(files []*git.FileDiff, maxLength int) string {
+	nouns := make(map[string][]string)
+
+	for _, file := range files {
+		verb := verbFor(file.Status)
+		noun := describeFile(file.Path())
+		if !containsString(nouns[verb], noun) {
+			nouns[verb] = append(nouns[verb], noun)
+		}
+	}
+
+	// Updates lead the subject since they are usually the main change
+	verbs := []string{"update", "add", "rename", "remove"}
+
+	var detailed, counted []string
+	for _, verb := range verbs {
+		if len(nouns[verb]) == 0 {
+			continue
+		}
+		detailed = append(detailed, verb+" "+joinWords(nouns[verb]))
+		counted = append(counted, verb+" "+pluralize(len(nouns[verb]), "file"))
+	}
+
+	if description := joinWords(detailed); len(description) <= maxLength {
+		return description
+	}
+	if description := joinWords(counted); len(description) <= maxLength {
+		return description
+	}
+	return "update " + pluralize(len(files), "file")
+}
+
+// summarizeFiles lists every changed file with its line counts for the body
+func summarizeFiles(files []*git.FileDiff) string {
+	lines := make([]string, 0, len(files))
+	for _, file := range files {
+		added, removed := file.Stats()
+		switch {
+		case file.Binary:
+			lines = append(lines, fmt.Sprintf("- %s %s (binary)", verbFor(file.Status), file.Path()))
+		case file.Status == git.StatusRenamed:
+			lines = append(lines, fmt.Sprintf("- rename %s to %s (+%d -%d)", file.OldPath, file.NewPath, added, removed))
+		default:
+			lines = append(lines, fmt.Sprintf("- %s %s (+%d -%d)", verbFor(file.Status), file.Path(), added, removed))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// verbFor maps a file status to the imperative verb used in the subject
+func verbFor(status git.FileStatus) string {
+	switch status {
+	case git.StatusAdded:
+		return "add"
+	case git.StatusDeleted:
+		return "remove"
+	case git.StatusRenamed:
+		return "rename"
+	default:
+		return "update"
+	}
+}
+
+// describeFile turns a path into a short noun, e.g. "internal/commit/wrap_test.go" -> "wrap tests"
+func describeFile(p string) string {
+	base := path.Base(p)
+	if strings.EqualFold(base, "README.md") {
+		return "README"
+	}
+
+	name := strings.TrimSuffix(base, path.Ext(base))
+	isTest := isTestFile(p)
+	for _, suffix := range []string{"_test", ".test", ".spec", "_spec"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	name = strings.TrimPrefix(name, "test_")
+	name = strings.NewReplacer("_", " ", "-", " ").Replace(name)
+
+	if isTest {
+		return name + " tests"
+	}
+	return name
+}
+
+// isTestFile reports whether a path holds tests or test fixtures
+func isTestFile(p string) bool {
+	base := path.Base(p)
+	if strings.HasSuffix(base, "_test.go") || strings.HasPrefix(base, "test_") ||
+		strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") || strings.HasSuffix(strings.TrimSuffix(base, path.Ext(base)), "_spec") {
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(p), "/") {
+		if dir == "testdata" || dir == "__tests__" || dir == "test" || dir == "tests" {
+			return true
+		}
+	}
+	return false
+}
+
+// isDocFile reports whether a path is documentation
+func isDocFile(p string) bool {
+	ext := strings.ToLower(path.Ext(p))
+	if ext == ".md" || ext == ".rst" || ext == ".adoc" {
+		return true
+	}
+	base := strings.ToUpper(path.Base(p))
+	if base == "LICENSE" || base == "AUTHORS" || base == "CHANGELOG" {
+		return true
+	}
+	return strings.HasPrefix(p, "docs/") || strings.HasPrefix(p, "doc/")
+}
+
+// isCIFile reports whether a path configures continuous integration
+func isCIFile(p string) bool {
+	return strings.HasPrefix(p, ".github/workflows/") || strings.HasPrefix(p, ".circleci/") ||
+		p == ".gitlab-ci.yml" || p == ".travis.yml"
+}
+
+// joinWords joins items as "a", "a and b" or "a, b and c"
+func joinWords(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	default:
+		return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+	}
+}
+
+// pluralize formats a count with a noun, e.g. "1 file" or "3 files"
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// containsString reports whether items contains s
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
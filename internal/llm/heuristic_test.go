@@ -0,0 +1,148 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestHeuristicClient_GenerateCommitMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		diff        string
+		wantSubject string
+		wantBody    string
+		wantError   string
+	}{
+		{
+			name: "source update with new tests",
+			diff: `diff --git a/internal/commit/formatter.go b/internal/commit/formatter.go
+--- a/internal/commit/formatter.go
++++ b/internal/commit/formatter.go
+@@ -1,1 +1,1 @@
+-old
++new
+diff --git a/internal/commit/wrap_test.go b/internal/commit/wrap_test.go
+new file mode 100644
+--- /dev/null
++++ b/internal/commit/wrap_test.go
+@@ -0,0 +1,2 @@
++package commit
++`,
+			wantSubject: "chore(commit): update formatter and add wrap tests",
+			wantBody:    "- update internal/commit/formatter.go (+1 -1)\n- add internal/commit/wrap_test.go (+2 -0)",
+		},
+		{
+			name: "tests only",
+			diff: `diff --git a/internal/llm/qwen_test.go b/internal/llm/qwen_test.go
+--- a/internal/llm/qwen_test.go
++++ b/internal/llm/qwen_test.go
+@@ -1,1 +1,1 @@
+-a
++b`,
+			wantSubject: "test(llm): update qwen tests",
+		},
+		{
+			name: "docs only",
+			diff: `diff --git a/README.md b/README.md
+--- a/README.md
++++ b/README.md
+@@ -1,1 +1,1 @@
+-a
++b
+diff --git a/docs/setup.md b/docs/setup.md
+new file mode 100644
+--- /dev/null
++++ b/docs/setup.md
+@@ -0,0 +1 @@
++# Setup`,
+			wantSubject: "docs: update README and add setup",
+			wantBody:    "- update README.md (+1 -1)\n- add docs/setup.md (+1 -0)",
+		},
+		{
+			name: "pure renames",
+			diff: `diff --git a/pkg/util/strings.go b/pkg/text/strings.go
+similarity index 100%
+rename from pkg/util/strings.go
+rename to pkg/text/strings.go`,
+			wantSubject: "refactor: rename strings",
+		},
+		{
+			name: "new source file",
+			diff: `diff --git a/internal/llm/heuristic.go b/internal/llm/heuristic.go
+new file mode 100644
+--- /dev/null
++++ b/internal/llm/heuristic.go
+@@ -0,0 +1 @@
++package llm`,
+			wantSubject: "feat(llm): add heuristic",
+		},
+		{
+			name: "too many files for a detailed subject",
+			diff: `diff --git a/cmd/rune/command_root.go b/cmd/rune/command_root.go
+--- a/cmd/rune/command_root.go
++++ b/cmd/rune/command_root.go
+@@ -1 +1 @@
+-a
++b
+diff --git a/internal/config/configuration.go b/internal/config/configuration.go
+--- a/internal/config/configuration.go
++++ b/internal/config/configuration.go
+@@ -1 +1 @@
+-a
++b
+diff --git a/internal/models/registry.go b/internal/models/registry.go
+--- a/internal/models/registry.go
++++ b/internal/models/registry.go
+@@ -1 +1 @@
+-a
++b
+diff --git a/internal/llm/factory.go b/internal/llm/factory.go
+--- a/internal/llm/factory.go
++++ b/internal/llm/factory.go
+@@ -1 +1 @@
+-a
++b`,
+			wantSubject: "chore: update 4 files",
+		},
+		{
+			name:      "empty diff",
+			diff:      "",
+			wantError: "no file changes found",
+		},
+	}
+
+	client := NewHeuristicClient()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: tt.diff})
+
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("Expected error containing '%s', got %v", tt.wantError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			subject, body, _ := strings.Cut(result, "\n\n")
+			if subject != tt.wantSubject {
+				t.Errorf("Subject = %q, want %q", subject, tt.wantSubject)
+			}
+			if tt.wantBody != "" && body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+// failingClient is an LLMClient that always returns the configured error
+type failingClient struct {
+	err error
+}
+
+func (c *failingClient) GenerateCommitMessage(ctx context.Context, request *Request) (string, error) {
+	return "", c.err
+}
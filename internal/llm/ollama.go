@@ -0,0 +1,169 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// Ollama chat endpoint, relative to the server URL
+	ollamaChatPath     = "/api/chat"
+	defaultOllamaModel = "llama3.2"
+	// Local models can be slow on CPU, the context deadline still applies
+	ollamaTimeout = 120 * time.Second
+)
+
+// OllamaClient implements the LLMClient interface for a local Ollama server
+type OllamaClient struct {
+	baseURL    string
+	model      string
+	options    Options
+	httpClient *http.Client
+}
+
+// OllamaChatRequest represents the request structure for Ollama's chat API
+type OllamaChatRequest struct {
+	Model    string         `json:"model"`
+	Messages []Message      `json:"messages"`
+	Stream   bool           `json:"stream"`
+	Options  *OllamaOptions `json:"options,omitempty"`
+}
+
+// OllamaOptions represents model parameters for Ollama's chat API
+type OllamaOptions struct {
+	Temperature float64  `json:"temperature"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+}
+
+// OllamaChatResponse represents the response structure from Ollama's chat API
+type OllamaChatResponse struct {
+	Model           string  `json:"model"`
+	Message         Message `json:"message"`
+	Done            bool    `json:"done"`
+	DoneReason      string  `json:"done_reason"`
+	PromptEvalCount int     `json:"prompt_eval_count"`
+	EvalCount       int     `json:"eval_count"`
+	Error           string  `json:"error"`
+}
+
+// NewOllamaClient creates a new OllamaClient for the server at host.
+// No API key is needed since the server runs locally.
+func NewOllamaClient(host, model string) *OllamaClient {
+	if model == "" {
+		model = defaultOllamaModel
+	}
+
+	return &OllamaClient{
+		baseURL:    strings.TrimSuffix(host, "/") + ollamaChatPath,
+		model:      model,
+		httpClient: newHTTPClient(ollamaTimeout),
+	}
+}
+
+// GenerateCommitMessage generates a commit message based on the provided request
+func (c *OllamaClient) GenerateCommitMessage(ctx context.Context, request *Request) (string, error) {
+	prompt := BuildCommitPrompt(request)
+
+	options := c.options.Merge(request.Options)
+	reqBody := OllamaChatRequest{
+		Model: c.model,
+		Messages: append([]Message{
+			{
+				Role:    "system",
+				Content: options.systemPrompt(defaultSystemPrompt),
+			},
+		}, conversation(prompt, request)...),
+		Stream: false,
+		Options: &OllamaOptions{
+			Temperature: options.temperature(),
+			TopP:        options.TopP,
+			NumPredict:  options.maxTokens(),
+			Seed:        options.Seed,
+			Stop:        options.Stop,
+		},
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach Ollama at %s: %w", strings.TrimSuffix(c.baseURL, ollamaChatPath), err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close response body: %v\n", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var response OllamaChatResponse
+	if resp.StatusCode != http.StatusOK {
+		if json.Unmarshal(body, &response) == nil && response.Error != "" {
+			return "", &APIError{Provider: "Ollama", StatusCode: resp.StatusCode, Body: response.Error}
+		}
+		return "", &APIError{Provider: "Ollama", StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	recordUsage(ctx, Usage{
+		Provider:         c.providerName(),
+		Model:            c.model,
+		PromptTokens:     response.PromptEvalCount,
+		CompletionTokens: response.EvalCount,
+		Latency:          time.Since(start),
+	})
+
+	// Reasoning models such as deepseek-r1 think in <think> blocks before answering
+	commitMsg, reasoning := SplitReasoning(response.Message.Content)
+	reportReasoning(ctx, reasoning)
+	if err := checkFinish("Ollama", response.DoneReason, commitMsg, reqBody.Options.NumPredict); err != nil {
+		return "", err
+	}
+	if commitMsg == "" {
+		return "", fmt.Errorf("empty commit message received")
+	}
+
+	return commitMsg, nil
+}
+
+// setOptions sets the defaults of every request
+func (c *OllamaClient) setOptions(options Options) {
+	c.options = options
+}
+
+// setTimeout bounds each request, including its retries
+func (c *OllamaClient) setTimeout(timeout time.Duration) {
+	c.httpClient.Timeout = timeout
+}
+
+// providerName returns the name reported in Usage
+func (c *OllamaClient) providerName() string {
+	return "Ollama"
+}
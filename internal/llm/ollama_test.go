@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOllamaClient_GenerateCommitMessage(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   string
+		expectedMsg    string
+		expectedError  string
+	}{
+		{
+			name:           "successful response",
+			responseStatus: http.StatusOK,
+			responseBody: `{
+				"model": "llama3.2",
+				"message": {"role": "assistant", "content": "Add Hello world print statement\n"},
+				"done": true,
+				"done_reason": "stop",
+				"prompt_eval_count": 120,
+				"eval_count": 8
+			}`,
+			expectedMsg: "Add Hello world print statement",
+		},
+		{
+			name:           "model not installed",
+			responseStatus: http.StatusNotFound,
+			responseBody:   `{"error": "model 'llama3.2' not found, try pulling it first"}`,
+			expectedError:  "Ollama API request failed with status 404: model 'llama3.2' not found",
+		},
+		{
+			name:           "non-JSON error",
+			responseStatus: http.StatusInternalServerError,
+			responseBody:   `boom`,
+			expectedError:  "Ollama API request failed with status 500: boom",
+		},
+		{
+			name:           "invalid JSON response",
+			responseStatus: http.StatusOK,
+			responseBody:   `invalid json`,
+			expectedError:  "failed to parse response",
+		},
+		{
+			name:           "empty commit message",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"message": {"role": "assistant", "content": "  "}, "done": true}`,
+			expectedError:  "empty commit message received",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "POST" {
+					t.Errorf("Expected POST request, got %s", r.Method)
+				}
+				if r.URL.Path != "/api/chat" {
+					t.Errorf("Expected /api/chat, got %s", r.URL.Path)
+				}
+				if r.Header.Get("Authorization") != "" {
+					t.Errorf("Expected no Authorization header, got %s", r.Header.Get("Authorization"))
+				}
+
+				var req OllamaChatRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("Failed to decode request: %v", err)
+				}
+				if req.Model != "llama3.2" || req.Stream {
+					t.Errorf("Unexpected request: model=%s stream=%v", req.Model, req.Stream)
+				}
+				if len(req.Messages) != 2 || !strings.Contains(req.Messages[1].Content, "some diff") {
+					t.Errorf("Expected system and user messages with the diff, got %+v", req.Messages)
+				}
+
+				w.WriteHeader(tt.responseStatus)
+				if _, err := w.Write([]byte(tt.responseBody)); err != nil {
+					t.Errorf("Failed to write response body: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			client := NewOllamaClient(server.URL+"/", "llama3.2")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, err := client.GenerateCommitMessage(ctx, &Request{Diff: "some diff"})
+
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Errorf("Expected error containing '%s', got nil", tt.expectedError)
+				} else if !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("Expected error containing '%s', got '%s'", tt.expectedError, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if result != tt.expectedMsg {
+				t.Errorf("Expected message '%s', got '%s'", tt.expectedMsg, result)
+			}
+		})
+	}
+}
+
+func TestOllamaClient_ServerNotRunning(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	client := NewOllamaClient(url, "")
+	if client.model != defaultOllamaModel {
+		t.Errorf("Expected default model, got '%s'", client.model)
+	}
+
+	_, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: "diff"})
+	if err == nil || !strings.Contains(err.Error(), "failed to reach Ollama at "+url) {
+		t.Errorf("Expected connection error mentioning the host, got %v", err)
+	}
+}
+
+func TestOllamaClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": "model 'llama3.2' not found, try pulling it first"}`))
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "llama3.2")
+	_, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: "some diff"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an APIError, got %v", err)
+	}
+	if apiErr.Provider != "Ollama" || apiErr.StatusCode != http.StatusNotFound || apiErr.Body != "model 'llama3.2' not found, try pulling it first" {
+		t.Errorf("Unexpected APIError %+v", apiErr)
+	}
+}
@@ -0,0 +1,373 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/siddhartha/rune/internal/config"
+)
+
+const (
+	chatCompletionsPath     = "/chat/completions"
+	defaultAPIKeyHeader     = "api-key"
+	openAICompatibleTimeout = 60 * time.Second
+)
+
+// OpenAICompatibleConfig describes an API that implements OpenAI's chat completions endpoint
+type OpenAICompatibleConfig struct {
+	Name         string            // Display name used in errors, e.g. "OpenAI" or the endpoint name
+	BaseURL      string            // API base URL, e.g. "https://api.openai.com/v1"
+	APIKey       string            // Empty when AuthStyle is config.AuthStyleNone
+	AuthStyle    string            // One of the config.AuthStyle constants, defaults to config.AuthStyleBearer
+	AuthHeader   string            // Header name for config.AuthStyleAPIKey, defaults to "api-key"
+	Model        string            // Model ID sent with every request
+	Headers      map[string]string // Extra headers sent with every request
+	SystemPrompt string            // Optional override of the default system prompt
+	Timeout      time.Duration     // Defaults to 60 seconds
+
+	StructuredOutput bool // Ask for JSON matching CommitMessageSchema via response_format
+}
+
+// OpenAICompatibleClient implements the LLMClient interface for any API
+// that speaks OpenAI's chat completions protocol
+type OpenAICompatibleClient struct {
+	name         string
+	apiKey       string
+	baseURL      string // Full chat completions URL
+	model        string
+	authStyle    string
+	authHeader   string
+	headers      map[string]string
+	systemPrompt string
+	structured   bool
+	options      Options
+	httpClient   *http.Client
+}
+
+// NewOpenAICompatibleClient creates a client for an OpenAI-compatible API
+func NewOpenAICompatibleClient(cfg OpenAICompatibleConfig) (*OpenAICompatibleClient, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("base URL is required for %s", cfg.Name)
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("model is required for %s", cfg.Name)
+	}
+
+	switch cfg.AuthStyle {
+	case "":
+		cfg.AuthStyle = config.AuthStyleBearer
+	case config.AuthStyleBearer, config.AuthStyleAPIKey, config.AuthStyleNone:
+	default:
+		return nil, fmt.Errorf("unsupported auth style %q for %s", cfg.AuthStyle, cfg.Name)
+	}
+	if cfg.AuthStyle != config.AuthStyleNone && cfg.APIKey == "" {
+		return nil, fmt.Errorf("API key is required for %s", cfg.Name)
+	}
+
+	cfg.BaseURL = chatCompletionsURL(cfg.BaseURL)
+	return newOpenAICompatibleClient(cfg), nil
+}
+
+// newOpenAICompatibleClient creates a client without validation.
+// cfg.BaseURL is used as the full chat completions URL.
+func newOpenAICompatibleClient(cfg OpenAICompatibleConfig) *OpenAICompatibleClient {
+	if cfg.AuthStyle == "" {
+		cfg.AuthStyle = config.AuthStyleBearer
+	}
+	if cfg.AuthHeader == "" {
+		cfg.AuthHeader = defaultAPIKeyHeader
+	}
+	if cfg.SystemPrompt == "" {
+		cfg.SystemPrompt = defaultSystemPrompt
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = openAICompatibleTimeout
+	}
+
+	return &OpenAICompatibleClient{
+		name:         cfg.Name,
+		apiKey:       cfg.APIKey,
+		baseURL:      cfg.BaseURL,
+		model:        cfg.Model,
+		authStyle:    cfg.AuthStyle,
+		authHeader:   cfg.AuthHeader,
+		headers:      cfg.Headers,
+		systemPrompt: cfg.SystemPrompt,
+		structured:   cfg.StructuredOutput,
+		httpClient:   newHTTPClient(cfg.Timeout),
+	}
+}
+
+// chatCompletionsURL appends the chat completions path to an API base URL
+func chatCompletionsURL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if strings.HasSuffix(baseURL, chatCompletionsPath) {
+		return baseURL
+	}
+	return baseURL + chatCompletionsPath
+}
+
+// GenerateCommitMessage generates a commit message based on the provided request
+func (c *OpenAICompatibleClient) GenerateCommitMessage(ctx context.Context, request *Request) (string, error) {
+	reqBody := c.chatRequest(request)
+	response, err := c.complete(ctx, reqBody)
+	if err != nil {
+		return "", err
+	}
+
+	choice := response.Choices[0]
+	return c.finish(ctx, choice.Message.Content, choice.Message.Reasoning+choice.Message.ReasoningContent, choice.FinishReason, reqBody.MaxTokens)
+}
+
+// GenerateCandidates asks for n alternative commit messages in one request using the "n" parameter.
+// APIs that ignore "n" return a single choice.
+func (c *OpenAICompatibleClient) GenerateCandidates(ctx context.Context, request *Request, n int) ([]string, error) {
+	reqBody := c.chatRequest(request)
+	reqBody.N = n
+
+	response, err := c.complete(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	var firstErr error
+	for _, choice := range response.Choices {
+		message, err := c.finish(ctx, choice.Message.Content, choice.Message.Reasoning+choice.Message.ReasoningContent, choice.FinishReason, reqBody.MaxTokens)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		candidates = append(candidates, message)
+	}
+	if len(candidates) == 0 {
+		return nil, firstErr
+	}
+	return candidates, nil
+}
+
+// complete sends a chat completions request and returns a response with at least one choice
+func (c *OpenAICompatibleClient) complete(ctx context.Context, reqBody ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	req, err := c.newRequest(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close response body: %v\n", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{Provider: c.name, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var response ChatCompletionResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	recordUsage(ctx, Usage{
+		Provider:         c.providerName(),
+		Model:            c.model,
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+		Latency:          time.Since(start),
+	})
+
+	// Extract the message from OpenAI-compatible response format
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	return &response, nil
+}
+
+// StreamCommitMessage generates a commit message, passing text to onChunk as it is generated
+func (c *OpenAICompatibleClient) StreamCommitMessage(ctx context.Context, request *Request, onChunk StreamHandler) (string, error) {
+	reqBody := c.chatRequest(request)
+	reqBody.Stream = true
+	reqBody.StreamOptions = &StreamOptions{IncludeUsage: true}
+
+	req, err := c.newRequest(ctx, reqBody)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close response body: %v\n", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", fmt.Errorf("failed to read response: %w", err)
+		}
+		return "", &APIError{Provider: c.name, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var text, reasoning strings.Builder
+	var finishReason string
+	filter := newThinkFilter(onChunk)
+	usage := Usage{Provider: c.providerName(), Model: c.model}
+	err = readSSE(resp.Body, func(data string) error {
+		var chunk ChatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("%s stream failed: %s", c.name, chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			usage.PromptTokens = chunk.Usage.PromptTokens
+			usage.CompletionTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) == 0 {
+			return nil // e.g. a final usage-only event
+		}
+
+		choice := chunk.Choices[0]
+		reasoning.WriteString(choice.Delta.Reasoning + choice.Delta.ReasoningContent)
+		if choice.Delta.Content != "" {
+			text.WriteString(choice.Delta.Content)
+			filter.write(choice.Delta.Content)
+		}
+		if choice.FinishReason != "" {
+			finishReason = choice.FinishReason
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read response stream: %w", err)
+	}
+	filter.flush()
+	usage.Latency = time.Since(start)
+	recordUsage(ctx, usage)
+
+	return c.finish(ctx, text.String(), reasoning.String(), finishReason, reqBody.MaxTokens)
+}
+
+// chatRequest builds the chat completions request for a commit message
+func (c *OpenAICompatibleClient) chatRequest(request *Request) ChatCompletionRequest {
+	prompt := BuildCommitPrompt(request)
+	var responseFormat *ResponseFormat
+	if wantsJSON(c.structured, request) {
+		prompt = BuildStructuredCommitPrompt(request)
+		responseFormat = &ResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &ResponseSchema{Name: commitMessageSchemaName, Strict: true, Schema: CommitMessageSchema()},
+		}
+	}
+
+	options := c.options.Merge(request.Options)
+	return ChatCompletionRequest{
+		Model: c.model,
+		Messages: append([]Message{
+			{
+				Role:    "system",
+				Content: options.systemPrompt(c.systemPrompt),
+			},
+		}, conversation(prompt, request)...),
+		Temperature:    options.temperature(),
+		TopP:           options.TopP,
+		MaxTokens:      options.maxTokens(),
+		Seed:           options.Seed,
+		Stop:           options.Stop,
+		ResponseFormat: responseFormat,
+	}
+}
+
+// newRequest creates an authenticated chat completions request
+func (c *OpenAICompatibleClient) newRequest(ctx context.Context, reqBody ChatCompletionRequest) (*http.Request, error) {
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	switch c.authStyle {
+	case config.AuthStyleBearer:
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	case config.AuthStyleAPIKey:
+		req.Header.Set(c.authHeader, c.apiKey)
+	}
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+// setOptions sets the defaults of every request
+func (c *OpenAICompatibleClient) setOptions(options Options) {
+	c.options = options
+}
+
+// setTimeout bounds each request, including its retries
+func (c *OpenAICompatibleClient) setTimeout(timeout time.Duration) {
+	c.httpClient.Timeout = timeout
+}
+
+// providerName returns the name reported in Usage
+func (c *OpenAICompatibleClient) providerName() string {
+	return c.name
+}
+
+// setStructuredOutput switches between JSON and free text commit messages
+func (c *OpenAICompatibleClient) setStructuredOutput(enabled bool) {
+	c.structured = enabled
+}
+
+// structuredOutput reports whether commit messages are requested as JSON
+func (c *OpenAICompatibleClient) structuredOutput() bool {
+	return c.structured
+}
+
+// finish validates the generated text once the response is complete. The reasoning of
+// reasoning models, from its own field or <think> blocks in content, is passed to the
+// reasoning handler of ctx and left out of the message. Truncated and blocked answers
+// are returned as a TruncatedError or BlockedError.
+func (c *OpenAICompatibleClient) finish(ctx context.Context, content, reasoning, finishReason string, maxTokens int) (string, error) {
+	commitMsg, thoughts := SplitReasoning(content)
+	reasoning = joinReasoning(reasoning, thoughts)
+	reportReasoning(ctx, reasoning)
+	if err := checkFinish(c.name, finishReason, commitMsg, maxTokens); err != nil {
+		return "", err
+	}
+	if commitMsg == "" {
+		return "", fmt.Errorf("empty commit message received")
+	}
+
+	return commitMsg, nil
+}
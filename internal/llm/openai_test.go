@@ -0,0 +1,186 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/siddhartha/rune/internal/config"
+)
+
+func TestOpenAICompatibleClient_Auth(t *testing.T) {
+	tests := []struct {
+		name       string
+		authStyle  string
+		authHeader string
+		apiKey     string
+		wantHeader string
+		wantValue  string
+	}{
+		{name: "bearer by default", apiKey: "sk-test", wantHeader: "Authorization", wantValue: "Bearer sk-test"},
+		{name: "api-key header", authStyle: config.AuthStyleAPIKey, apiKey: "secret", wantHeader: "api-key", wantValue: "secret"},
+		{name: "custom key header", authStyle: config.AuthStyleAPIKey, authHeader: "X-Gateway-Key", apiKey: "secret", wantHeader: "X-Gateway-Key", wantValue: "secret"},
+		{name: "no auth", authStyle: config.AuthStyleNone, wantHeader: "Authorization", wantValue: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/chat/completions" {
+					t.Errorf("Expected /v1/chat/completions, got %s", r.URL.Path)
+				}
+				if got := r.Header.Get(tt.wantHeader); got != tt.wantValue {
+					t.Errorf("Expected %s header '%s', got '%s'", tt.wantHeader, tt.wantValue, got)
+				}
+				if tt.authStyle == config.AuthStyleAPIKey && r.Header.Get("Authorization") != "" {
+					t.Errorf("Expected no Authorization header, got %s", r.Header.Get("Authorization"))
+				}
+				if r.Header.Get("X-Team") != "platform" {
+					t.Errorf("Expected extra header X-Team, got '%s'", r.Header.Get("X-Team"))
+				}
+
+				var req ChatCompletionRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("Failed to decode request: %v", err)
+				}
+				if req.Model != "local-model" {
+					t.Errorf("Expected model 'local-model', got '%s'", req.Model)
+				}
+
+				_, err := w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Add retry to fetcher"}}]}`))
+				if err != nil {
+					t.Errorf("Failed to write response body: %v", err)
+				}
+			}))
+			defer server.Close()
+
+			client, err := NewOpenAICompatibleClient(OpenAICompatibleConfig{
+				Name:       "local",
+				BaseURL:    server.URL + "/v1/",
+				APIKey:     tt.apiKey,
+				AuthStyle:  tt.authStyle,
+				AuthHeader: tt.authHeader,
+				Model:      "local-model",
+				Headers:    map[string]string{"X-Team": "platform"},
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			result, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: "some diff"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result != "Add retry to fetcher" {
+				t.Errorf("Expected message 'Add retry to fetcher', got '%s'", result)
+			}
+		})
+	}
+}
+
+func TestOpenAICompatibleClient_ErrorNamesEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client, err := NewOpenAICompatibleClient(OpenAICompatibleConfig{
+		Name:      "gateway",
+		BaseURL:   server.URL,
+		AuthStyle: config.AuthStyleNone,
+		Model:     "m",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, err = client.GenerateCommitMessage(context.Background(), &Request{Diff: "diff"})
+	if err == nil || !strings.Contains(err.Error(), "gateway API request failed with status 502") {
+		t.Errorf("Expected error naming the endpoint, got %v", err)
+	}
+}
+
+func TestNewOpenAICompatibleClient_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           OpenAICompatibleConfig
+		expectedError string
+	}{
+		{
+			name:          "missing base URL",
+			cfg:           OpenAICompatibleConfig{Name: "x", Model: "m", APIKey: "k"},
+			expectedError: "base URL is required",
+		},
+		{
+			name:          "missing model",
+			cfg:           OpenAICompatibleConfig{Name: "x", BaseURL: "http://localhost", APIKey: "k"},
+			expectedError: "model is required",
+		},
+		{
+			name:          "missing API key",
+			cfg:           OpenAICompatibleConfig{Name: "x", BaseURL: "http://localhost", Model: "m"},
+			expectedError: "API key is required for x",
+		},
+		{
+			name:          "unknown auth style",
+			cfg:           OpenAICompatibleConfig{Name: "x", BaseURL: "http://localhost", Model: "m", AuthStyle: "basic"},
+			expectedError: "unsupported auth style",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewOpenAICompatibleClient(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing '%s', got %v", tt.expectedError, err)
+			}
+			if client != nil {
+				t.Error("Expected nil client on error")
+			}
+		})
+	}
+}
+
+func TestChatCompletionsURL(t *testing.T) {
+	tests := map[string]string{
+		"https://api.openai.com/v1":                    "https://api.openai.com/v1/chat/completions",
+		"http://localhost:1234/v1/":                    "http://localhost:1234/v1/chat/completions",
+		"https://gateway.internal/v1/chat/completions": "https://gateway.internal/v1/chat/completions",
+	}
+
+	for input, want := range tests {
+		if got := chatCompletionsURL(input); got != want {
+			t.Errorf("chatCompletionsURL(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestNewLLMClient_Endpoint(t *testing.T) {
+	cfg := &config.Config{
+		Provider: config.ProviderOpenAICompatible,
+		Endpoint: "lmstudio",
+		Endpoints: map[string]*config.Endpoint{
+			"lmstudio": {BaseURL: "http://localhost:1234/v1", Model: "qwen2.5-coder-7b", AuthStyle: config.AuthStyleNone},
+		},
+	}
+
+	client, err := NewLLMClient(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	endpointClient, ok := client.(*OpenAICompatibleClient)
+	if !ok {
+		t.Fatalf("Expected *OpenAICompatibleClient, got %T", client)
+	}
+	if endpointClient.model != "qwen2.5-coder-7b" || endpointClient.baseURL != "http://localhost:1234/v1/chat/completions" {
+		t.Errorf("Unexpected client: model=%s url=%s", endpointClient.model, endpointClient.baseURL)
+	}
+
+	cfg.Endpoint = "missing"
+	if _, err := NewLLMClient(cfg); err == nil || !strings.Contains(err.Error(), "endpoint not configured: missing") {
+		t.Errorf("Expected endpoint error, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultTemperature keeps commit messages focused while allowing some variation between runs
+	DefaultTemperature = 0.3
+	// DefaultMaxTokens bounds the length of a generated commit message
+	DefaultMaxTokens = 1000
+	// ReasoningMaxTokens is the default output limit of reasoning models, whose reasoning
+	// counts towards the limit before the answer
+	ReasoningMaxTokens = 8000
+	// defaultSystemPrompt describes the task to models that take a system prompt
+	defaultSystemPrompt = "You are a helpful assistant that generates concise, descriptive Git commit messages following GitHub conventions."
+)
+
+// Options are the sampling and output settings of a request. Unset fields fall back to the
+// defaults of the client, which come from the model's options in the config, and then to the
+// built-in defaults. Providers ignore settings they do not support, e.g. Anthropic has no seed.
+type Options struct {
+	Temperature  *float64
+	TopP         *float64
+	MaxTokens    int
+	Seed         *int
+	Stop         []string
+	SystemPrompt string // replaces the default system prompt
+}
+
+// Merge returns o with the fields that are set in override replaced
+func (o Options) Merge(override Options) Options {
+	if override.Temperature != nil {
+		o.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		o.TopP = override.TopP
+	}
+	if override.MaxTokens > 0 {
+		o.MaxTokens = override.MaxTokens
+	}
+	if override.Seed != nil {
+		o.Seed = override.Seed
+	}
+	if len(override.Stop) > 0 {
+		o.Stop = override.Stop
+	}
+	if override.SystemPrompt != "" {
+		o.SystemPrompt = override.SystemPrompt
+	}
+	return o
+}
+
+// Validate checks that the settings are within the ranges providers accept
+func (o Options) Validate() error {
+	if o.Temperature != nil && (*o.Temperature < 0 || *o.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2, got %g", *o.Temperature)
+	}
+	if o.TopP != nil && (*o.TopP <= 0 || *o.TopP > 1) {
+		return fmt.Errorf("top_p must be greater than 0 and at most 1, got %g", *o.TopP)
+	}
+	if o.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must be positive, got %d", o.MaxTokens)
+	}
+	return nil
+}
+
+// temperature returns the temperature to send, the default if unset
+func (o Options) temperature() float64 {
+	if o.Temperature == nil {
+		return DefaultTemperature
+	}
+	return *o.Temperature
+}
+
+// maxTokens returns the output token limit to send, the default if unset
+func (o Options) maxTokens() int {
+	if o.MaxTokens <= 0 {
+		return DefaultMaxTokens
+	}
+	return o.MaxTokens
+}
+
+// systemPrompt returns the system prompt to send, fallback if unset
+func (o Options) systemPrompt(fallback string) string {
+	if o.SystemPrompt == "" {
+		return fallback
+	}
+	return o.SystemPrompt
+}
+
+// configurableClient is implemented by clients whose defaults are set from the config
+type configurableClient interface {
+	setOptions(options Options)
+	setTimeout(timeout time.Duration)
+}
@@ -0,0 +1,162 @@
+package llm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/siddhartha/rune/internal/config"
+	"github.com/zalando/go-keyring"
+)
+
+func TestOptions_Merge(t *testing.T) {
+	low, high := 0.0, 0.9
+	seed := 7
+	defaults := Options{Temperature: &high, MaxTokens: 200, Stop: []string{"END"}, SystemPrompt: "config"}
+
+	merged := defaults.Merge(Options{Temperature: &low, Seed: &seed})
+
+	if merged.Temperature == nil || *merged.Temperature != 0 {
+		t.Errorf("Expected the override temperature 0, got %v", merged.Temperature)
+	}
+	if merged.Seed == nil || *merged.Seed != 7 {
+		t.Errorf("Expected seed 7, got %v", merged.Seed)
+	}
+	if merged.MaxTokens != 200 || merged.SystemPrompt != "config" || len(merged.Stop) != 1 {
+		t.Errorf("Expected unset fields to keep the defaults, got %+v", merged)
+	}
+	if *defaults.Temperature != 0.9 {
+		t.Error("Expected the defaults to be unchanged")
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		options Options
+		wantErr string
+	}{
+		{name: "empty", options: Options{}},
+		{name: "zero temperature", options: Options{Temperature: value(0)}},
+		{name: "full range", options: Options{Temperature: value(2), TopP: value(1), MaxTokens: 4000}},
+		{name: "temperature too high", options: Options{Temperature: value(2.5)}, wantErr: "temperature"},
+		{name: "negative temperature", options: Options{Temperature: value(-0.1)}, wantErr: "temperature"},
+		{name: "zero top_p", options: Options{TopP: value(0)}, wantErr: "top_p"},
+		{name: "negative max_tokens", options: Options{MaxTokens: -1}, wantErr: "max_tokens"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error about %s, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOpenAICompatibleClient_Options(t *testing.T) {
+	client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: "http://localhost", APIKey: "key", Model: "m"})
+
+	// Built-in defaults
+	body, _ := json.Marshal(client.chatRequest(&Request{Diff: "diff"}))
+	for _, expected := range []string{`"temperature":0.3`, `"max_tokens":1000`, defaultSystemPrompt} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("Expected %s in %s", expected, body)
+		}
+	}
+	for _, unexpected := range []string{`"top_p"`, `"seed"`, `"stop"`} {
+		if strings.Contains(string(body), unexpected) {
+			t.Errorf("Expected no %s in %s", unexpected, body)
+		}
+	}
+
+	// Config defaults with a per-request override
+	zero, topP := 0.0, 0.8
+	seed := 42
+	client.setOptions(Options{Temperature: &topP, TopP: &topP, MaxTokens: 300, SystemPrompt: "Write terse messages."})
+	req := client.chatRequest(&Request{Diff: "diff", Options: Options{Temperature: &zero, Seed: &seed, Stop: []string{"\n\n\n"}}})
+
+	if req.Temperature != 0 || req.TopP == nil || *req.TopP != 0.8 || req.MaxTokens != 300 {
+		t.Errorf("Unexpected sampling settings: %+v", req)
+	}
+	if req.Seed == nil || *req.Seed != 42 || len(req.Stop) != 1 {
+		t.Errorf("Expected seed and stop from the request, got %v %v", req.Seed, req.Stop)
+	}
+	if req.Messages[0].Content != "Write terse messages." {
+		t.Errorf("Expected the configured system prompt, got %q", req.Messages[0].Content)
+	}
+	body, _ = json.Marshal(req)
+	if !strings.Contains(string(body), `"temperature":0,`) {
+		t.Errorf("Expected temperature 0 to be sent, got %s", body)
+	}
+}
+
+func TestGeminiClient_Options(t *testing.T) {
+	client := NewGeminiClientWithConfig("key", "http://localhost", "gemini-1.5-pro")
+	zero, topP := 0.0, 0.5
+	seed := 3
+	client.setOptions(Options{TopP: &topP, Stop: []string{"END"}})
+
+	body := client.requestBody(&Request{Diff: "diff", Options: Options{Temperature: &zero, Seed: &seed, MaxTokens: 256}}, 1)
+
+	generation := body.GenerationConfig
+	if generation.Temperature != 0 || generation.TopP == nil || *generation.TopP != 0.5 {
+		t.Errorf("Unexpected sampling settings: %+v", generation)
+	}
+	if generation.MaxOutputTokens != 256 || generation.Seed == nil || *generation.Seed != 3 {
+		t.Errorf("Expected max tokens and seed from the request, got %+v", generation)
+	}
+	if len(generation.StopSequences) != 1 || generation.StopSequences[0] != "END" {
+		t.Errorf("Expected the configured stop sequence, got %v", generation.StopSequences)
+	}
+}
+
+func TestNewLLMClient_ModelOptions(t *testing.T) {
+	keyring.MockInit()
+
+	temperature := 0.1
+	cfg := &config.Config{
+		Provider:       config.ProviderAnthropic,
+		Model:          "claude-sonnet-4-20250514",
+		TimeoutSeconds: 5,
+		ModelOptions: map[string]*config.GenerationOptions{
+			"claude-sonnet-4-20250514": {Temperature: &temperature, MaxTokens: 400},
+			"gemini-1.5-pro":           {MaxTokens: 50},
+		},
+	}
+	if err := cfg.SetAPIKey("test-key"); err != nil {
+		t.Fatalf("Failed to store API key: %v", err)
+	}
+	t.Setenv(cfg.GetEnvVarName(), "")
+
+	client, err := NewLLMClient(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	anthropic, ok := client.(*AnthropicClient)
+	if !ok {
+		t.Fatalf("Expected *llm.AnthropicClient, got %T", client)
+	}
+	if anthropic.options.temperature() != 0.1 || anthropic.options.maxTokens() != 400 {
+		t.Errorf("Expected the options of the model, got %+v", anthropic.options)
+	}
+	if anthropic.httpClient.Timeout != 5*time.Second {
+		t.Errorf("Expected the configured timeout, got %v", anthropic.httpClient.Timeout)
+	}
+
+	// Invalid options are reported with the model
+	temperature = 3
+	if _, err := NewLLMClient(cfg); err == nil || !strings.Contains(err.Error(), "invalid model_options") {
+		t.Errorf("Expected invalid model_options error, got %v", err)
+	}
+}
@@ -33,21 +33,94 @@ Examples of good commit messages:
 - "Update README with installation instructions"
 - "Remove deprecated API endpoints"
 
+%s%s%s
+
+%s
+`
+
+const summaryPromptTemplate = `The following diff is one part of a change that is too large to describe at once.
+Summarize what this part changes and, where the diff shows it, why. Use at most 5 short
+bullet points, name the affected components and leave out details that do not matter
+for a commit message.
+
 Git diff:
 %s
 
-Generate ONLY the commit message (no quotes, no explanations):
+Respond with ONLY the bullet points:
 `
 
-// BuildCommitPrompt creates a prompt for generating commit messages from a git diff
-func BuildCommitPrompt(diff string) string {
-	// Truncate very long diffs to avoid token limits
+const (
+	textOutputInstruction = "Generate ONLY the commit message (no quotes, no explanations):"
+	jsonOutputInstruction = `Respond with a JSON object with the fields "type", "scope", "subject", "body", "breaking" and "footers".
+The subject is the summary without the type prefix. Use empty strings for a missing scope or body.`
+)
+
+// BuildCommitPrompt creates a prompt for generating commit messages from a request
+func BuildCommitPrompt(req *Request) string {
+	return buildPrompt(req, textOutputInstruction)
+}
+
+// BuildStructuredCommitPrompt creates a prompt that asks for the commit message as a JSON object.
+// Summary requests are always answered in text.
+func BuildStructuredCommitPrompt(req *Request) string {
+	return buildPrompt(req, jsonOutputInstruction)
+}
+
+// buildPrompt renders the commit prompt with the given output instruction
+func buildPrompt(req *Request, instruction string) string {
+	if req.Summarize {
+		return fmt.Sprintf(summaryPromptTemplate, truncateDiff(req.Diff))
+	}
+	return fmt.Sprintf(commitPromptTemplate, buildIntentSection(req.Intent), buildNotesSection(req.Notes), buildChangeSection(req), instruction)
+}
+
+// buildChangeSection renders the diff, or the summaries of its parts when it is too large
+func buildChangeSection(req *Request) string {
+	if len(req.Summaries) == 0 {
+		return "Git diff:\n" + truncateDiff(req.Diff)
+	}
+
+	var section strings.Builder
+	section.WriteString("The diff is too large to include. Summaries of its parts:\n")
+	for _, summary := range req.Summaries {
+		section.WriteString("\n" + strings.TrimSpace(summary) + "\n")
+	}
+	return section.String()
+}
+
+// truncateDiff shortens very long diffs to avoid token limits
+func truncateDiff(diff string) string {
 	const maxDiffLength = 500_000
 	if len(diff) > maxDiffLength {
-		diff = diff[:maxDiffLength] + "\n... (diff truncated)"
+		return diff[:maxDiffLength] + "\n... (diff truncated)"
 	}
+	return diff
+}
 
-	return fmt.Sprintf(commitPromptTemplate, diff)
+// buildIntentSection renders the author's reason for the change for the prompt
+func buildIntentSection(intent string) string {
+	intent = strings.TrimSpace(intent)
+	if intent == "" {
+		return ""
+	}
+	return "Why this change was made, according to its author. This is authoritative: the body must\n" +
+		"explain this reason and must not contradict it, even where the diff does not show it:\n" +
+		intent + "\n\n"
+}
+
+// buildNotesSection renders locally detected facts about the change for the prompt
+func buildNotesSection(notes []string) string {
+	if len(notes) == 0 {
+		return ""
+	}
+
+	var section strings.Builder
+	section.WriteString("Important facts about this change (the message must reflect them):\n")
+	for _, note := range notes {
+		section.WriteString("- " + note + "\n")
+	}
+	section.WriteString("\n")
+	return section.String()
 }
 
 // ParseCommitMessage parses and validates a commit message
@@ -0,0 +1,29 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildCommitPrompt_Intent(t *testing.T) {
+	prompt := BuildCommitPrompt(&Request{
+		Diff:   "diff --git a/fetch.go b/fetch.go",
+		Notes:  []string{"Only tests changed"},
+		Intent: "why: customer reported timeouts on large repos\n",
+	})
+
+	intent := strings.Index(prompt, "why: customer reported timeouts on large repos\n")
+	if intent < 0 {
+		t.Fatalf("Expected prompt to contain the intent:\n%s", prompt)
+	}
+	if !strings.Contains(prompt[:intent], "authoritative") {
+		t.Errorf("Expected the intent to be introduced as authoritative:\n%s", prompt)
+	}
+	if notes := strings.Index(prompt, "Only tests changed"); notes < intent {
+		t.Errorf("Expected the intent before the notes:\n%s", prompt)
+	}
+
+	if prompt := BuildCommitPrompt(&Request{Diff: "diff", Intent: "  \n"}); strings.Contains(prompt, "authoritative") {
+		t.Errorf("Expected no intent section for a blank intent:\n%s", prompt)
+	}
+}
@@ -1,14 +1,8 @@
 package llm
 
 import (
-	"bytes"
-	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"strings"
 	"time"
 )
 
@@ -19,32 +13,28 @@ const (
 	defaultTimeout    = 30 * time.Second
 )
 
-// QwenClient implements the LLMClient interface for Qwen models
+// QwenClient implements the LLMClient interface for Qwen models hosted on Novita.ai
 type QwenClient struct {
-	apiKey     string
-	baseURL    string
-	model      string
-	httpClient *http.Client
+	*OpenAICompatibleClient
 }
 
-// NewQwenClient creates a new QwenClient with the API key from environment
+// NewQwenClient creates a new QwenClient for the default model with the API key from environment
 func NewQwenClient() (*QwenClient, error) {
+	return NewNovitaClient("")
+}
+
+// NewNovitaClient creates a new QwenClient for any Novita.ai model with the API key from environment
+func NewNovitaClient(model string) (*QwenClient, error) {
 	apiKey := os.Getenv("NOVITA_API_KEY")
 	if apiKey == "" {
 		return nil, fmt.Errorf("NOVITA_API_KEY environment variable is required")
 	}
 
-	return &QwenClient{
-		apiKey:  apiKey,
-		baseURL: defaultQwenAPIURL,
-		model:   defaultModel,
-		httpClient: &http.Client{
-			Timeout: defaultTimeout,
-		},
-	}, nil
+	return NewQwenClientWithConfig(apiKey, "", model), nil
 }
 
-// NewQwenClientWithConfig creates a new QwenClient with custom configuration
+// NewQwenClientWithConfig creates a new QwenClient with custom configuration.
+// baseURL is the full chat completions URL.
 func NewQwenClientWithConfig(apiKey, baseURL, model string) *QwenClient {
 	if baseURL == "" {
 		baseURL = defaultQwenAPIURL
@@ -54,82 +44,12 @@ func NewQwenClientWithConfig(apiKey, baseURL, model string) *QwenClient {
 	}
 
 	return &QwenClient{
-		apiKey:  apiKey,
-		baseURL: baseURL,
-		model:   model,
-		httpClient: &http.Client{
+		OpenAICompatibleClient: newOpenAICompatibleClient(OpenAICompatibleConfig{
+			Name:    "Novita",
+			BaseURL: baseURL,
+			APIKey:  apiKey,
+			Model:   model,
 			Timeout: defaultTimeout,
-		},
-	}
-}
-
-// GenerateCommitMessage generates a commit message based on the provided diff
-func (c *QwenClient) GenerateCommitMessage(ctx context.Context, diff string) (string, error) {
-	prompt := BuildCommitPrompt(diff)
-
-	// Create the request payload using OpenAI-compatible format for Novita.ai
-	reqBody := ChatCompletionRequest{
-		Model: c.model,
-		Messages: []Message{
-			{
-				Role:    "system",
-				Content: "You are a helpful assistant that generates concise, descriptive Git commit messages following GitHub conventions.",
-			},
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Temperature: 0.3,
-		MaxTokens:   512,
-	}
-
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to make request: %w", err)
-	}
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to close response body: %v\n", err)
-		}
-	}()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		}),
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var response ChatCompletionResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	// Extract the message from OpenAI-compatible response format
-	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
-	}
-
-	commitMsg := strings.TrimSpace(response.Choices[0].Message.Content)
-	if commitMsg == "" {
-		return "", fmt.Errorf("empty commit message received")
-	}
-
-	return commitMsg, nil
 }
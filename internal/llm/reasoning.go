@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	thinkOpenTag  = "<think>"
+	thinkCloseTag = "</think>"
+)
+
+// reasoningHandlerKey is the context key of the reasoning callback
+type reasoningHandlerKey struct{}
+
+// WithReasoningHandler returns a context that passes the reasoning of reasoning models to handle,
+// separately from the commit message. Calls may run in parallel, so handle must be safe for
+// concurrent use.
+func WithReasoningHandler(ctx context.Context, handle func(reasoning string)) context.Context {
+	return context.WithValue(ctx, reasoningHandlerKey{}, handle)
+}
+
+// reportReasoning passes non-empty reasoning to the handler of ctx, if any
+func reportReasoning(ctx context.Context, reasoning string) {
+	if reasoning == "" {
+		return
+	}
+	if handle, ok := ctx.Value(reasoningHandlerKey{}).(func(string)); ok && handle != nil {
+		handle(reasoning)
+	}
+}
+
+// SplitReasoning separates the <think> blocks of reasoning models such as DeepSeek R1 and QwQ
+// from the answer. A closing tag without an opening one ends reasoning that started with the
+// response, and an unclosed block runs to the end, e.g. when the output limit was reached.
+func SplitReasoning(text string) (answer, reasoning string) {
+	var answers, thoughts []string
+
+	// Some chat templates open the block in the prompt, so the response only closes it
+	if end := strings.Index(text, thinkCloseTag); end >= 0 && !strings.Contains(text[:end], thinkOpenTag) {
+		thoughts = append(thoughts, text[:end])
+		text = text[end+len(thinkCloseTag):]
+	}
+
+	for {
+		start := strings.Index(text, thinkOpenTag)
+		if start < 0 {
+			answers = append(answers, text)
+			break
+		}
+		answers = append(answers, text[:start])
+		text = text[start+len(thinkOpenTag):]
+
+		end := strings.Index(text, thinkCloseTag)
+		if end < 0 {
+			thoughts = append(thoughts, text)
+			break
+		}
+		thoughts = append(thoughts, text[:end])
+		text = text[end+len(thinkCloseTag):]
+	}
+
+	return strings.TrimSpace(strings.Join(answers, "")), joinReasoning(thoughts...)
+}
+
+// joinReasoning joins the non-empty parts of the reasoning of a response
+func joinReasoning(parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
+
+// thinkFilter removes <think> blocks from streamed text, so that only the answer is shown.
+// Text that may be the start of a tag is held back until the next chunk decides it.
+type thinkFilter struct {
+	onChunk  StreamHandler
+	thinking bool
+	pending  string
+}
+
+// newThinkFilter returns a filter that passes the answer to onChunk
+func newThinkFilter(onChunk StreamHandler) *thinkFilter {
+	return &thinkFilter{onChunk: onChunk}
+}
+
+// write filters a chunk of the stream
+func (f *thinkFilter) write(chunk string) {
+	f.pending += chunk
+	for {
+		tag := thinkOpenTag
+		if f.thinking {
+			tag = thinkCloseTag
+		}
+
+		if i := strings.Index(f.pending, tag); i >= 0 {
+			if !f.thinking {
+				f.emit(f.pending[:i])
+			}
+			f.pending = f.pending[i+len(tag):]
+			f.thinking = !f.thinking
+			continue
+		}
+
+		keep := partialTagLength(f.pending, tag)
+		if !f.thinking {
+			f.emit(f.pending[:len(f.pending)-keep])
+		}
+		f.pending = f.pending[len(f.pending)-keep:]
+		return
+	}
+}
+
+// flush passes on text held back at the end of the stream
+func (f *thinkFilter) flush() {
+	if !f.thinking {
+		f.emit(f.pending)
+	}
+	f.pending = ""
+}
+
+// emit passes non-empty text to the handler
+func (f *thinkFilter) emit(text string) {
+	if text != "" && f.onChunk != nil {
+		f.onChunk(text)
+	}
+}
+
+// partialTagLength returns the length of the longest suffix of text that starts tag
+func partialTagLength(text, tag string) int {
+	for n := min(len(tag)-1, len(text)); n > 0; n-- {
+		if strings.HasSuffix(text, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
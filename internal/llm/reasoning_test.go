@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/siddhartha/rune/internal/config"
+	"github.com/zalando/go-keyring"
+)
+
+func TestSplitReasoning(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		wantAnswer    string
+		wantReasoning string
+	}{
+		{name: "no reasoning", text: "Add x\n\nBody", wantAnswer: "Add x\n\nBody"},
+		{name: "think block", text: "<think>\nThe diff adds x.\n</think>\n\nAdd x", wantAnswer: "Add x", wantReasoning: "The diff adds x."},
+		{name: "opening tag in the prompt", text: "The diff adds x.\n</think>\nAdd x", wantAnswer: "Add x", wantReasoning: "The diff adds x."},
+		{name: "several blocks", text: "<think>a</think>Add x<think>b</think>", wantAnswer: "Add x", wantReasoning: "a\n\nb"},
+		{name: "unclosed block", text: "<think>The diff adds", wantReasoning: "The diff adds"},
+		{name: "empty block", text: "<think></think>Add x", wantAnswer: "Add x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer, reasoning := SplitReasoning(tt.text)
+			if answer != tt.wantAnswer {
+				t.Errorf("Expected answer %q, got %q", tt.wantAnswer, answer)
+			}
+			if reasoning != tt.wantReasoning {
+				t.Errorf("Expected reasoning %q, got %q", tt.wantReasoning, reasoning)
+			}
+		})
+	}
+}
+
+func TestThinkFilter(t *testing.T) {
+	text := "<think>plan < and </thin more</think>Add x <b>\n\nBody"
+
+	// Every way of splitting the text into two chunks shows the same answer
+	for i := 0; i <= len(text); i++ {
+		var shown strings.Builder
+		filter := newThinkFilter(func(chunk string) { shown.WriteString(chunk) })
+		filter.write(text[:i])
+		filter.write(text[i:])
+		filter.flush()
+
+		if shown.String() != "Add x <b>\n\nBody" {
+			t.Errorf("Expected only the answer for split at %d, got %q", i, shown.String())
+		}
+	}
+}
+
+func TestOpenAICompatibleClient_Reasoning(t *testing.T) {
+	tests := []struct {
+		name          string
+		responseBody  string
+		wantMessage   string
+		wantReasoning string
+		wantError     string
+	}{
+		{
+			name:          "OpenRouter reasoning field",
+			responseBody:  `{"choices": [{"message": {"content": "Add x", "reasoning": "The diff adds x."}, "finish_reason": "stop"}]}`,
+			wantMessage:   "Add x",
+			wantReasoning: "The diff adds x.",
+		},
+		{
+			name:          "DeepSeek reasoning_content field",
+			responseBody:  `{"choices": [{"message": {"content": "Add x", "reasoning_content": "The diff adds x."}, "finish_reason": "stop"}]}`,
+			wantMessage:   "Add x",
+			wantReasoning: "The diff adds x.",
+		},
+		{
+			name:          "think block in content",
+			responseBody:  `{"choices": [{"message": {"content": "<think>The diff adds x.</think>\n\nAdd x"}, "finish_reason": "stop"}]}`,
+			wantMessage:   "Add x",
+			wantReasoning: "The diff adds x.",
+		},
+		{
+			name:          "reasoning used the output limit",
+			responseBody:  `{"choices": [{"message": {"content": "", "reasoning": "The diff adds"}, "finish_reason": "length"}]}`,
+			wantReasoning: "The diff adds",
+			wantError:     "whole output limit of 1000 tokens",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m"})
+
+			var reasoning string
+			ctx := WithReasoningHandler(context.Background(), func(text string) { reasoning = text })
+			message, err := client.GenerateCommitMessage(ctx, &Request{Diff: "diff"})
+
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("Expected error containing '%s', got %v", tt.wantError, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if message != tt.wantMessage {
+				t.Errorf("Expected message %q, got %q", tt.wantMessage, message)
+			}
+			if reasoning != tt.wantReasoning {
+				t.Errorf("Expected reasoning %q, got %q", tt.wantReasoning, reasoning)
+			}
+		})
+	}
+}
+
+func TestNewLLMClient_ReasoningMaxTokens(t *testing.T) {
+	keyring.MockInit()
+
+	cfg := &config.Config{Provider: config.ProviderOpenRouter, Model: "deepseek/deepseek-r1-0528:free"}
+	if err := cfg.SetAPIKey("test-key"); err != nil {
+		t.Fatalf("Failed to store API key: %v", err)
+	}
+	t.Setenv(cfg.GetEnvVarName(), "")
+
+	client, err := NewLLMClient(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := client.(*OpenRouterClient).options.maxTokens(); got != ReasoningMaxTokens {
+		t.Errorf("Expected the reasoning output limit %d, got %d", ReasoningMaxTokens, got)
+	}
+
+	// A configured limit wins
+	cfg.ModelOptions = map[string]*config.GenerationOptions{"dr1": {MaxTokens: 2000}}
+	client, err = NewLLMClient(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := client.(*OpenRouterClient).options.maxTokens(); got != 2000 {
+		t.Errorf("Expected the configured output limit 2000, got %d", got)
+	}
+}
@@ -0,0 +1,33 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// refinementTemplate asks the model to revise its previous answer
+const refinementTemplate = `Revise the commit message above: %s
+
+Keep following the conventions from my first message and answer in the same format, with ONLY the revised commit message.`
+
+// ErrRefinementNotSupported is returned by clients that cannot continue a conversation
+var ErrRefinementNotSupported = errors.New("the heuristic generator cannot refine messages, regenerate or edit instead")
+
+// Refine returns a copy of request that continues its conversation with the previous answer of
+// the model and the user's instruction for revising it, e.g. "shorter, no body". Refining a
+// refined request keeps the whole conversation, so earlier instructions still apply.
+func Refine(request *Request, previous, instruction string) *Request {
+	refined := *request
+	refined.History = append(slices.Clip(request.History),
+		Message{Role: "assistant", Content: previous},
+		Message{Role: "user", Content: fmt.Sprintf(refinementTemplate, instruction)},
+	)
+	return &refined
+}
+
+// conversation returns the turns sent after the system prompt: the commit prompt followed by
+// the refinement history of the request
+func conversation(prompt string, request *Request) []Message {
+	return append([]Message{{Role: "user", Content: prompt}}, request.History...)
+}
@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRefine(t *testing.T) {
+	request := &Request{Diff: "diff"}
+
+	first := Refine(request, "Add x\n\nLong body", "shorter, no body")
+	second := Refine(first, "Add x", "mention the migration")
+
+	if len(request.History) != 0 {
+		t.Errorf("Expected the original request to be unchanged, got %v", request.History)
+	}
+	if len(first.History) != 2 {
+		t.Errorf("Expected the first refinement to keep its own history, got %v", first.History)
+	}
+	if len(second.History) != 4 {
+		t.Fatalf("Expected the whole conversation, got %v", second.History)
+	}
+
+	roles := []string{"assistant", "user", "assistant", "user"}
+	for i, turn := range second.History {
+		if turn.Role != roles[i] {
+			t.Errorf("Expected role %s for turn %d, got %s", roles[i], i, turn.Role)
+		}
+	}
+	if second.History[2].Content != "Add x" || !strings.Contains(second.History[3].Content, "mention the migration") {
+		t.Errorf("Unexpected refinement turns %v", second.History[2:])
+	}
+}
+
+func TestOpenAICompatibleClient_Refine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+
+		roles := []string{"system", "user", "assistant", "user"}
+		if len(req.Messages) != len(roles) {
+			t.Errorf("Expected %d messages, got %d", len(roles), len(req.Messages))
+		} else {
+			for i, message := range req.Messages {
+				if message.Role != roles[i] {
+					t.Errorf("Expected role %s for message %d, got %s", roles[i], i, message.Role)
+				}
+			}
+			if req.Messages[2].Content != "Add x\n\nLong body" {
+				t.Errorf("Expected the previous answer, got %q", req.Messages[2].Content)
+			}
+		}
+
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"content": "Add x"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m"})
+	request := Refine(&Request{Diff: "diff"}, "Add x\n\nLong body", "shorter, no body")
+
+	message, err := client.GenerateCommitMessage(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if message != "Add x" {
+		t.Errorf("Expected the refined message, got %q", message)
+	}
+}
+
+func TestGeminiContents(t *testing.T) {
+	contents := geminiContents([]Message{{Role: "user", Content: "prompt"}, {Role: "assistant", Content: "Add x"}})
+
+	if len(contents) != 2 || contents[0].Role != "user" || contents[1].Role != "model" {
+		t.Errorf("Expected the assistant turn to use the model role, got %+v", contents)
+	}
+}
+
+func TestHeuristicClient_Refine(t *testing.T) {
+	request := Refine(&Request{Diff: "diff"}, "Add x", "shorter")
+
+	_, err := NewHeuristicClient().GenerateCommitMessage(context.Background(), request)
+	if !errors.Is(err, ErrRefinementNotSupported) {
+		t.Errorf("Expected ErrRefinementNotSupported, got %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how failed provider requests are retried
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first request
+	BaseDelay   time.Duration // backoff before the first retry, doubled for every retry
+	MaxDelay    time.Duration // upper bound of the computed backoff
+	MaxWait     time.Duration // longest server-requested wait (Retry-After) that is honoured
+}
+
+// retryPolicy applies to every provider client
+var retryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   time.Second,
+	MaxDelay:    20 * time.Second,
+	MaxWait:     60 * time.Second,
+}
+
+// RetryEvent describes a retry that is about to happen
+type RetryEvent struct {
+	Attempt     int           // the attempt that failed, starting at 1
+	MaxAttempts int           // total attempts allowed
+	Delay       time.Duration // wait before the next attempt
+	StatusCode  int           // status of the failed attempt, 0 for network errors
+	Err         error         // network error of the failed attempt, if any
+	MaxTokens   int           // output limit of the next attempt after a truncated answer, 0 for failed requests
+}
+
+// String describes the retry for the spinner, e.g. "Rate limited (429), retrying in 4s (attempt 2 of 4)..."
+func (e RetryEvent) String() string {
+	if e.MaxTokens > 0 {
+		return fmt.Sprintf("Answer was cut off, retrying with %d output tokens (attempt %d of %d)...", e.MaxTokens, e.Attempt+1, e.MaxAttempts)
+	}
+
+	var reason string
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests:
+		reason = "Rate limited (429)"
+	case e.StatusCode != 0:
+		reason = fmt.Sprintf("Service unavailable (%d)", e.StatusCode)
+	default:
+		reason = "Connection failed"
+	}
+
+	delay := e.Delay.Round(time.Second)
+	if e.Delay < time.Second {
+		delay = e.Delay.Round(100 * time.Millisecond)
+	}
+	return fmt.Sprintf("%s, retrying in %s (attempt %d of %d)...", reason, delay, e.Attempt+1, e.MaxAttempts)
+}
+
+// retryNotifierKey is the context key of the RetryEvent callback
+type retryNotifierKey struct{}
+
+// WithRetryNotifier returns a context that reports upcoming retries to notify
+func WithRetryNotifier(ctx context.Context, notify func(RetryEvent)) context.Context {
+	return context.WithValue(ctx, retryNotifierKey{}, notify)
+}
+
+// newHTTPClient creates the HTTP client shared by all providers. Transient failures
+// are retried with exponential backoff within the request's context deadline.
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &retryTransport{base: http.DefaultTransport},
+	}
+}
+
+// retryTransport retries rate limited and temporarily unavailable requests
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	policy := retryPolicy
+
+	attemptReq := req
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(attemptReq)
+		if attempt >= policy.MaxAttempts || !shouldRetry(resp, err) || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		delay, requested := serverRetryDelay(resp, time.Now())
+		if !requested {
+			delay = backoff(policy, attempt)
+		}
+		if delay > policy.MaxWait {
+			return resp, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			// Waiting would use up the deadline, report the failure instead
+			return resp, err
+		}
+
+		event := RetryEvent{Attempt: attempt, MaxAttempts: policy.MaxAttempts, Delay: delay, Err: err}
+		if resp != nil {
+			event.StatusCode = resp.StatusCode
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		if notify, ok := ctx.Value(retryNotifierKey{}).(func(RetryEvent)); ok && notify != nil {
+			notify(event)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		attemptReq = req.Clone(ctx)
+		if req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to replay request body: %w", err)
+			}
+			attemptReq.Body = body
+		}
+	}
+}
+
+// shouldRetry reports whether a failed attempt is worth repeating
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		// Only retry connections dropped by the server, not unreachable hosts or cancellation
+		return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusRequestTimeout,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout,
+		529: // Anthropic "overloaded"
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns the exponential backoff with jitter before the retry after attempt
+func backoff(policy RetryPolicy, attempt int) time.Duration {
+	delay := policy.BaseDelay << (attempt - 1)
+	if delay > policy.MaxDelay || delay <= 0 {
+		delay = policy.MaxDelay
+	}
+	// Equal jitter: wait at least half the backoff so retries still spread out
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// serverRetryDelay reads how long the server asked us to wait from Retry-After or
+// the providers' rate limit headers. It reports false when no header is present.
+func serverRetryDelay(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	header := resp.Header
+
+	// Azure OpenAI and OpenAI send milliseconds
+	if value := header.Get("Retry-After-Ms"); value != "" {
+		if ms, err := strconv.ParseFloat(value, 64); err == nil {
+			return clampDelay(time.Duration(ms * float64(time.Millisecond))), true
+		}
+	}
+
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			return clampDelay(time.Duration(seconds) * time.Second), true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return clampDelay(at.Sub(now)), true
+		}
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	// OpenAI style durations such as "6m0s" or "20ms"
+	var longest time.Duration
+	var found bool
+	for _, name := range []string{"X-Ratelimit-Reset-Requests", "X-Ratelimit-Reset-Tokens"} {
+		if d, err := time.ParseDuration(header.Get(name)); err == nil {
+			found = true
+			longest = max(longest, d)
+		}
+	}
+
+	// Anthropic sends RFC 3339 timestamps
+	for _, name := range []string{"Anthropic-Ratelimit-Requests-Reset", "Anthropic-Ratelimit-Tokens-Reset"} {
+		if at, err := time.Parse(time.RFC3339, header.Get(name)); err == nil {
+			found = true
+			longest = max(longest, at.Sub(now))
+		}
+	}
+
+	// OpenRouter sends a Unix timestamp in milliseconds
+	if value := header.Get("X-Ratelimit-Reset"); value != "" && !found {
+		if stamp, err := strconv.ParseInt(value, 10, 64); err == nil {
+			var at time.Time
+			if stamp > 1e12 {
+				at = time.UnixMilli(stamp)
+			} else {
+				at = time.Unix(stamp, 0)
+			}
+			found = true
+			longest = at.Sub(now)
+		}
+	}
+
+	return clampDelay(longest), found
+}
+
+// clampDelay turns waits in the past into an immediate retry
+func clampDelay(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,242 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// Keep provider tests that exercise 429 and 5xx responses fast
+	retryPolicy.BaseDelay = time.Millisecond
+	retryPolicy.MaxDelay = 5 * time.Millisecond
+	os.Exit(m.Run())
+}
+
+// failingServer fails the first n requests with status and the given headers, then succeeds
+func failingServer(t *testing.T, n int32, status int, headers map[string]string) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "some diff") {
+			t.Errorf("Expected the request body to be replayed, got %q", body)
+		}
+
+		if atomic.AddInt32(&requests, 1) <= n {
+			for name, value := range headers {
+				w.Header().Set(name, value)
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"error": {"message": "try again"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Fix retry handling"}, "finish_reason": "stop"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		status       int
+		headers      map[string]string
+		wantRequests int32
+		wantRetries  int
+		wantDelay    time.Duration // expected delay of the first retry, if set
+		wantError    string
+	}{
+		{name: "no failures", failures: 0, status: http.StatusOK, wantRequests: 1},
+		{name: "transient 503", failures: 2, status: http.StatusServiceUnavailable, wantRequests: 3, wantRetries: 2},
+		{name: "502 then success", failures: 1, status: http.StatusBadGateway, wantRequests: 2, wantRetries: 1},
+		{
+			name:         "rate limited with Retry-After-Ms",
+			failures:     1,
+			status:       http.StatusTooManyRequests,
+			headers:      map[string]string{"Retry-After-Ms": "30"},
+			wantRequests: 2,
+			wantRetries:  1,
+			wantDelay:    30 * time.Millisecond,
+		},
+		{
+			name:         "gives up after max attempts",
+			failures:     10,
+			status:       http.StatusServiceUnavailable,
+			wantRequests: 4,
+			wantRetries:  3,
+			wantError:    "Test API request failed with status 503",
+		},
+		{
+			name:         "client errors are not retried",
+			failures:     1,
+			status:       http.StatusBadRequest,
+			wantRequests: 1,
+			wantError:    "status 400",
+		},
+		{
+			name:         "Retry-After beyond the limit",
+			failures:     1,
+			status:       http.StatusTooManyRequests,
+			headers:      map[string]string{"Retry-After": "3600"},
+			wantRequests: 1,
+			wantError:    "status 429",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := failingServer(t, tt.failures, tt.status, tt.headers)
+			client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m"})
+
+			var events []RetryEvent
+			ctx := WithRetryNotifier(context.Background(), func(event RetryEvent) {
+				events = append(events, event)
+			})
+
+			result, err := client.GenerateCommitMessage(ctx, &Request{Diff: "some diff"})
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("Expected error containing '%s', got %v", tt.wantError, err)
+				}
+			} else if err != nil || result != "Fix retry handling" {
+				t.Errorf("Expected message, got '%s' (%v)", result, err)
+			}
+
+			if *requests != tt.wantRequests {
+				t.Errorf("Expected %d requests, got %d", tt.wantRequests, *requests)
+			}
+			if len(events) != tt.wantRetries {
+				t.Errorf("Expected %d retry events, got %d", tt.wantRetries, len(events))
+			}
+			for i, event := range events {
+				if event.Attempt != i+1 || event.MaxAttempts != retryPolicy.MaxAttempts || event.StatusCode != tt.status {
+					t.Errorf("Unexpected event %+v", event)
+				}
+			}
+			if tt.wantDelay != 0 && len(events) > 0 && events[0].Delay != tt.wantDelay {
+				t.Errorf("Expected delay %s, got %s", tt.wantDelay, events[0].Delay)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_Deadline(t *testing.T) {
+	server, requests := failingServer(t, 1, http.StatusTooManyRequests, map[string]string{"Retry-After": "2"})
+	client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m"})
+
+	// The requested wait does not fit in the deadline, so the 429 is reported right away
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GenerateCommitMessage(ctx, &Request{Diff: "some diff"})
+	if err == nil || !strings.Contains(err.Error(), "status 429") {
+		t.Errorf("Expected the 429 error, got %v", err)
+	}
+	if *requests != 1 {
+		t.Errorf("Expected 1 request, got %d", *requests)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected no wait, took %s", elapsed)
+	}
+}
+
+func TestRetryTransport_Cancel(t *testing.T) {
+	server, _ := failingServer(t, 10, http.StatusServiceUnavailable, map[string]string{"Retry-After": "30"})
+	client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = WithRetryNotifier(ctx, func(RetryEvent) { cancel() })
+
+	_, err := client.GenerateCommitMessage(ctx, &Request{Diff: "some diff"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation while waiting, got %v", err)
+	}
+}
+
+func TestServerRetryDelay(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		status    int
+		headers   map[string]string
+		wantDelay time.Duration
+		wantFound bool
+	}{
+		{name: "no headers", status: http.StatusTooManyRequests},
+		{name: "Retry-After seconds", status: http.StatusServiceUnavailable, headers: map[string]string{"Retry-After": "7"}, wantDelay: 7 * time.Second, wantFound: true},
+		{name: "Retry-After date", status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "Sat, 01 Mar 2025 12:00:05 GMT"}, wantDelay: 5 * time.Second, wantFound: true},
+		{name: "Retry-After in the past", status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "Sat, 01 Mar 2025 11:00:00 GMT"}, wantDelay: 0, wantFound: true},
+		{name: "Retry-After-Ms", status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After-Ms": "1500"}, wantDelay: 1500 * time.Millisecond, wantFound: true},
+		{
+			name:      "OpenAI reset durations",
+			status:    http.StatusTooManyRequests,
+			headers:   map[string]string{"X-Ratelimit-Reset-Requests": "1s", "X-Ratelimit-Reset-Tokens": "6m0s"},
+			wantDelay: 6 * time.Minute,
+			wantFound: true,
+		},
+		{
+			name:      "Anthropic reset timestamp",
+			status:    http.StatusTooManyRequests,
+			headers:   map[string]string{"Anthropic-Ratelimit-Requests-Reset": "2025-03-01T12:00:10Z"},
+			wantDelay: 10 * time.Second,
+			wantFound: true,
+		},
+		{
+			name:      "OpenRouter reset in milliseconds",
+			status:    http.StatusTooManyRequests,
+			headers:   map[string]string{"X-Ratelimit-Reset": "1740830403000"},
+			wantDelay: 3 * time.Second,
+			wantFound: true,
+		},
+		{
+			name:    "rate limit headers on a 503 are ignored",
+			status:  http.StatusServiceUnavailable,
+			headers: map[string]string{"X-Ratelimit-Reset-Requests": "1s"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for name, value := range tt.headers {
+				resp.Header.Set(name, value)
+			}
+
+			delay, found := serverRetryDelay(resp, now)
+			if delay != tt.wantDelay || found != tt.wantFound {
+				t.Errorf("Expected (%s, %v), got (%s, %v)", tt.wantDelay, tt.wantFound, delay, found)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 10: 10 * time.Second} {
+		for i := 0; i < 20; i++ {
+			if got := backoff(policy, attempt); got < want/2 || got > want {
+				t.Errorf("backoff(%d) = %s, expected between %s and %s", attempt, got, want/2, want)
+			}
+		}
+	}
+}
+
+func TestRetryEvent_String(t *testing.T) {
+	event := RetryEvent{Attempt: 1, MaxAttempts: 4, Delay: 3200 * time.Millisecond, StatusCode: http.StatusTooManyRequests}
+	if got := event.String(); got != "Rate limited (429), retrying in 3s (attempt 2 of 4)..." {
+		t.Errorf("Unexpected message '%s'", got)
+	}
+}
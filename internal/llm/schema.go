@@ -0,0 +1,85 @@
+package llm
+
+import "strings"
+
+// commitMessageSchemaName names the schema in OpenAI's response_format and Anthropic's tool definition
+const commitMessageSchemaName = "commit_message"
+
+// JSONSchema is the subset of JSON Schema used to describe structured commit messages
+type JSONSchema struct {
+	Type                 string                 `json:"type"`
+	Description          string                 `json:"description,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+}
+
+// commitTypes are the conventional commit types a structured message may use
+var commitTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// CommitMessageSchema returns the JSON schema of a structured commit message. Every field is
+// required so that it can be used with OpenAI's strict mode; empty strings mean "not set".
+func CommitMessageSchema() *JSONSchema {
+	noExtra := false
+	return &JSONSchema{
+		Type: "object",
+		Properties: map[string]*JSONSchema{
+			"type":     {Type: "string", Enum: commitTypes, Description: "Conventional commit type"},
+			"scope":    {Type: "string", Description: "Optional area of the code base, e.g. \"api\", or an empty string"},
+			"subject":  {Type: "string", Description: "Imperative summary without the type prefix, under 50 characters, no trailing period"},
+			"body":     {Type: "string", Description: "Optional explanation of what changed and why, or an empty string"},
+			"breaking": {Type: "boolean", Description: "Whether the change breaks compatibility"},
+			"footers":  {Type: "array", Items: &JSONSchema{Type: "string"}, Description: "Trailer lines such as \"BREAKING CHANGE: ...\" or \"Refs: #123\""},
+		},
+		Required:             []string{"type", "scope", "subject", "body", "breaking", "footers"},
+		AdditionalProperties: &noExtra,
+	}
+}
+
+// geminiSchema converts a schema to Gemini's OpenAPI subset, which uses upper case type
+// names, marks string enums with format "enum" and has no additionalProperties
+func geminiSchema(schema *JSONSchema) *JSONSchema {
+	if schema == nil {
+		return nil
+	}
+
+	converted := &JSONSchema{
+		Type:        strings.ToUpper(schema.Type),
+		Description: schema.Description,
+		Enum:        schema.Enum,
+		Required:    schema.Required,
+		Items:       geminiSchema(schema.Items),
+	}
+	if len(schema.Enum) > 0 {
+		converted.Format = "enum"
+	}
+	if len(schema.Properties) > 0 {
+		converted.Properties = make(map[string]*JSONSchema, len(schema.Properties))
+		for name, property := range schema.Properties {
+			converted.Properties[name] = geminiSchema(property)
+		}
+	}
+	return converted
+}
+
+// structuredOutputClient is implemented by clients that can return commit messages as JSON
+// objects matching CommitMessageSchema instead of free text
+type structuredOutputClient interface {
+	setStructuredOutput(enabled bool)
+	structuredOutput() bool
+}
+
+// isStructured reports whether client returns JSON commit messages
+func isStructured(client LLMClient) bool {
+	structured, ok := client.(structuredOutputClient)
+	return ok && structured.structuredOutput()
+}
+
+// wantsJSON reports whether a client in the given mode answers request with JSON.
+// Summaries of diff parts are always plain text.
+func wantsJSON(structured bool, request *Request) bool {
+	return structured && !request.Summarize
+}
@@ -0,0 +1,191 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/siddhartha/rune/internal/config"
+	"github.com/zalando/go-keyring"
+)
+
+const structuredReply = `{"type":"fix","scope":"","subject":"Close response bodies","body":"","breaking":false,"footers":[]}`
+
+func TestOpenAICompatibleClient_StructuredOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("Failed to parse request: %v", err)
+			return
+		}
+		if req.ResponseFormat == nil || req.ResponseFormat.Type != "json_schema" || req.ResponseFormat.JSONSchema == nil {
+			t.Errorf("Expected a json_schema response format, got %s", body)
+			return
+		}
+		if schema := req.ResponseFormat.JSONSchema; schema.Name != "commit_message" || !schema.Strict || schema.Schema.Properties["subject"] == nil {
+			t.Errorf("Unexpected schema %s", body)
+		}
+		if !strings.Contains(req.Messages[1].Content, "Respond with a JSON object") {
+			t.Errorf("Expected the JSON instruction in the prompt")
+		}
+
+		reply, _ := json.Marshal(structuredReply)
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": ` + string(reply) + `}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m", StructuredOutput: true})
+
+	// Structured clients are not streamed, their JSON is delivered without chunks
+	var chunks int
+	result, err := Stream(context.Background(), client, &Request{Diff: "some diff"}, func(string) { chunks++ })
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result != structuredReply {
+		t.Errorf("Expected the JSON reply, got '%s'", result)
+	}
+	if chunks != 0 {
+		t.Errorf("Expected no chunks, got %d", chunks)
+	}
+}
+
+func TestOpenAICompatibleClient_TextMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "response_format") {
+			t.Errorf("Expected no response format in text mode, got %s", body)
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Close response bodies"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m"})
+	if _, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: "some diff"}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestGeminiClient_StructuredOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GeminiRequest
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("Failed to parse request: %v", err)
+			return
+		}
+		config := req.GenerationConfig
+		if config.ResponseMimeType != "application/json" || config.ResponseSchema == nil {
+			t.Errorf("Expected a JSON response schema, got %s", body)
+			return
+		}
+		if config.ResponseSchema.Type != "OBJECT" || config.ResponseSchema.Properties["type"].Format != "enum" {
+			t.Errorf("Expected Gemini schema types, got %s", body)
+		}
+		if strings.Contains(string(body), "additionalProperties") {
+			t.Errorf("Gemini does not accept additionalProperties, got %s", body)
+		}
+
+		reply, _ := json.Marshal(structuredReply)
+		_, _ = w.Write([]byte(`{"candidates": [{"content": {"parts": [{"text": ` + string(reply) + `}], "role": "model"}, "finishReason": "STOP"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewGeminiClientWithConfig("test-key", server.URL, "gemini-1.5-pro")
+	client.setStructuredOutput(true)
+
+	result, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: "some diff"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result != structuredReply {
+		t.Errorf("Expected the JSON reply, got '%s'", result)
+	}
+}
+
+func TestAnthropicClient_StructuredOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req AnthropicRequest
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("Failed to parse request: %v", err)
+			return
+		}
+		if len(req.Tools) != 1 || req.Tools[0].Name != "commit_message" || req.Tools[0].InputSchema == nil {
+			t.Errorf("Expected the commit_message tool, got %s", body)
+			return
+		}
+		if req.ToolChoice == nil || req.ToolChoice.Type != "tool" || req.ToolChoice.Name != "commit_message" {
+			t.Errorf("Expected a forced tool call, got %s", body)
+		}
+
+		_, _ = w.Write([]byte(`{
+			"content": [
+				{"type": "text", "text": "Here is the commit message."},
+				{"type": "tool_use", "id": "toolu_01", "name": "commit_message", "input": ` + structuredReply + `}
+			],
+			"stop_reason": "tool_use"
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClientWithConfig("test-key", server.URL, "")
+	client.setStructuredOutput(true)
+
+	result, err := client.GenerateCommitMessage(context.Background(), &Request{Diff: "some diff"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result != structuredReply {
+		t.Errorf("Expected the tool input, got '%s'", result)
+	}
+}
+
+func TestNewLLMClient_StructuredOutput(t *testing.T) {
+	keyring.MockInit()
+
+	tests := []struct {
+		provider string
+		model    string
+		want     bool
+	}{
+		{provider: config.ProviderGemini, model: "gemini-1.5-pro", want: true},
+		{provider: config.ProviderAnthropic, model: "claude-3-5-haiku-20241022", want: true},
+		{provider: config.ProviderOpenRouter, model: "google/gemini-2.0-flash-exp:free", want: true},
+		{provider: config.ProviderOpenRouter, model: "qwen/qwq-32b-preview", want: false},
+		{provider: config.ProviderNovita, model: "qwen/qwen2.5-7b-instruct", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider+"/"+tt.model, func(t *testing.T) {
+			cfg := &config.Config{Provider: tt.provider, Model: tt.model}
+			if err := cfg.SetAPIKey("test-key"); err != nil {
+				t.Fatalf("Failed to store API key: %v", err)
+			}
+			t.Setenv(cfg.GetEnvVarName(), "")
+
+			// Text answers, which can be streamed, unless structured_output is enabled
+			client, err := NewLLMClient(cfg)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if isStructured(client) {
+				t.Error("Expected text output without structured_output")
+			}
+
+			cfg.StructuredOutput = true
+			client, err = NewLLMClient(cfg)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := isStructured(client); got != tt.want {
+				t.Errorf("Expected structured output %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
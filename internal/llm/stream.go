@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// maxSSELineSize bounds a single server-sent event line
+const maxSSELineSize = 1024 * 1024
+
+// StreamHandler receives generated text as it arrives
+type StreamHandler func(chunk string)
+
+// StreamingClient is implemented by clients that can deliver a commit message incrementally
+type StreamingClient interface {
+	LLMClient
+	// StreamCommitMessage generates a commit message, passing each text chunk to onChunk
+	// as it arrives. It returns the complete message like GenerateCommitMessage.
+	StreamCommitMessage(ctx context.Context, req *Request, onChunk StreamHandler) (string, error)
+}
+
+// Stream generates a commit message, streaming it when the client supports it.
+// Other clients deliver the whole message as a single chunk once it is complete.
+// Clients in structured output mode are not streamed, their JSON is not meant for display.
+// Answers cut off by the output limit are generated again with a larger limit.
+func Stream(ctx context.Context, client LLMClient, request *Request, onChunk StreamHandler) (string, error) {
+	if chain, ok := client.(*FallbackClient); ok {
+		// Every model of the chain retries its own truncated answers
+		return chain.StreamCommitMessage(ctx, request, onChunk)
+	}
+	return retryTruncated(ctx, request, func(request *Request) (string, error) {
+		return stream(ctx, client, request, onChunk)
+	})
+}
+
+// stream generates a commit message once, streaming it when the client supports it
+func stream(ctx context.Context, client LLMClient, request *Request, onChunk StreamHandler) (string, error) {
+	if isStructured(client) {
+		return client.GenerateCommitMessage(ctx, request)
+	}
+	if streaming, ok := client.(StreamingClient); ok {
+		return streaming.StreamCommitMessage(ctx, request, onChunk)
+	}
+
+	message, err := client.GenerateCommitMessage(ctx, request)
+	if err == nil && onChunk != nil {
+		onChunk(message)
+	}
+	return message, err
+}
+
+// readSSE reads a server-sent event stream and calls onEvent with the data of each event.
+// It stops at the end of the stream or at OpenAI's "[DONE]" sentinel.
+func readSSE(r io.Reader, onEvent func(data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSELineSize)
+
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			return nil
+		}
+		event := strings.Join(data, "\n")
+		data = data[:0]
+		return onEvent(event)
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			value := strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+			if value == "[DONE]" {
+				return nil
+			}
+			data = append(data, value)
+		}
+		// Comments (": keep-alive") and the event, id and retry fields are ignored
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return dispatch()
+}
@@ -0,0 +1,231 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadSSE(t *testing.T) {
+	input := ": keep-alive\n\n" +
+		"data: {\"a\":1}\n\n" +
+		"event: message\nid: 7\ndata:{\"b\":2}\n\n" +
+		"data: line one\ndata: line two\n\n" +
+		"data: [DONE]\n\n" +
+		"data: ignored\n\n"
+
+	var events []string
+	err := readSSE(strings.NewReader(input), func(data string) error {
+		events = append(events, data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{`{"a":1}`, `{"b":2}`, "line one\nline two"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected %q, got %q", expected, events)
+	}
+
+	// A final event without a trailing blank line is still delivered
+	events = nil
+	if err := readSSE(strings.NewReader("data: last"), func(data string) error {
+		events = append(events, data)
+		return nil
+	}); err != nil || len(events) != 1 {
+		t.Errorf("Expected the final event, got %q (%v)", events, err)
+	}
+
+	// Handler errors stop the stream
+	stop := errors.New("stop")
+	if err := readSSE(strings.NewReader(input), func(string) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("Expected handler error, got %v", err)
+	}
+}
+
+func TestOpenAICompatibleClient_StreamCommitMessage(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseStatus int
+		events         []string
+		expectedMsg    string
+		expectedChunks []string
+		expectedError  string
+	}{
+		{
+			name:           "streamed response",
+			responseStatus: http.StatusOK,
+			events: []string{
+				`{"choices":[{"index":0,"delta":{"role":"assistant","content":""}}]}`,
+				`{"choices":[{"index":0,"delta":{"content":"Add "}}]}`,
+				`{"choices":[{"index":0,"delta":{"content":"streaming\n"}}]}`,
+				`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+				`{"choices":[],"usage":{"total_tokens":12}}`,
+				`[DONE]`,
+			},
+			expectedMsg:    "Add streaming",
+			expectedChunks: []string{"Add ", "streaming\n"},
+		},
+		{
+			name:           "think block split across chunks",
+			responseStatus: http.StatusOK,
+			events: []string{
+				`{"choices":[{"index":0,"delta":{"content":"<th"}}]}`,
+				`{"choices":[{"index":0,"delta":{"content":"ink>plan"}}]}`,
+				`{"choices":[{"index":0,"delta":{"content":"ning</thi"}}]}`,
+				`{"choices":[{"index":0,"delta":{"content":"nk>\n\nAdd x"}}]}`,
+				`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+			},
+			expectedMsg:    "Add x",
+			expectedChunks: []string{"\n\nAdd x"},
+		},
+		{
+			name:           "separate reasoning field",
+			responseStatus: http.StatusOK,
+			events: []string{
+				`{"choices":[{"index":0,"delta":{"reasoning":"The diff adds x."}}]}`,
+				`{"choices":[{"index":0,"delta":{"content":"Add x"}}]}`,
+				`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+			},
+			expectedMsg:    "Add x",
+			expectedChunks: []string{"Add x"},
+		},
+		{
+			name:           "API error response",
+			responseStatus: http.StatusUnauthorized,
+			expectedError:  "Test API request failed with status 401",
+		},
+		{
+			name:           "error inside the stream",
+			responseStatus: http.StatusOK,
+			events:         []string{`{"error":{"message":"Provider returned error"}}`},
+			expectedError:  "Test stream failed: Provider returned error",
+		},
+		{
+			name:           "content filter",
+			responseStatus: http.StatusOK,
+			events: []string{
+				`{"choices":[{"index":0,"delta":{"content":"Add"}}]}`,
+				`{"choices":[{"index":0,"delta":{},"finish_reason":"content_filter"}]}`,
+			},
+			expectedError: "blocked by the content filter",
+		},
+		{
+			name:           "empty stream",
+			responseStatus: http.StatusOK,
+			events:         []string{`[DONE]`},
+			expectedError:  "empty commit message received",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req ChatCompletionRequest
+				body, _ := io.ReadAll(r.Body)
+				if err := json.Unmarshal(body, &req); err != nil || !req.Stream {
+					t.Errorf("Expected a streaming request, got %s", body)
+				}
+
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.WriteHeader(tt.responseStatus)
+				if tt.responseStatus != http.StatusOK {
+					_, _ = w.Write([]byte(`{"error": "Invalid API key"}`))
+					return
+				}
+				for _, event := range tt.events {
+					_, _ = w.Write([]byte("data: " + event + "\n\n"))
+					w.(http.Flusher).Flush()
+				}
+			}))
+			defer server.Close()
+
+			client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m"})
+
+			var chunks []string
+			result, err := client.StreamCommitMessage(context.Background(), &Request{Diff: "some diff"}, func(chunk string) {
+				chunks = append(chunks, chunk)
+			})
+
+			if tt.expectedError != "" {
+				if err == nil {
+					t.Errorf("Expected error containing '%s', got nil", tt.expectedError)
+				} else if !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("Expected error containing '%s', got '%s'", tt.expectedError, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if result != tt.expectedMsg {
+				t.Errorf("Expected message '%s', got '%s'", tt.expectedMsg, result)
+			}
+			if !reflect.DeepEqual(chunks, tt.expectedChunks) {
+				t.Errorf("Expected chunks %q, got %q", tt.expectedChunks, chunks)
+			}
+		})
+	}
+}
+
+func TestGeminiClient_StreamCommitMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models/gemini-2.0-flash:streamGenerateContent" || r.URL.Query().Get("alt") != "sse" {
+			t.Errorf("Unexpected URL %s", r.URL)
+		}
+		if r.Header.Get("x-goog-api-key") != "test-key" {
+			t.Errorf("Expected API key header, got %q", r.Header.Get("x-goog-api-key"))
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{
+			`{"candidates": [{"content": {"parts": [{"text": "Fix nil "}], "role": "model"}}]}`,
+			`{"candidates": [{"content": {"parts": [{"text": "pointer in parser"}], "role": "model"}, "finishReason": "STOP"}]}`,
+		} {
+			_, _ = w.Write([]byte("data: " + event + "\r\n\r\n"))
+		}
+	}))
+	defer server.Close()
+
+	client := NewGeminiClientWithConfig("test-key", server.URL+"/v1beta/models/gemini-2.0-flash:generateContent", "gemini-2.0-flash")
+
+	var chunks []string
+	result, err := client.StreamCommitMessage(context.Background(), &Request{Diff: "some diff"}, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result != "Fix nil pointer in parser" {
+		t.Errorf("Unexpected message '%s'", result)
+	}
+	if len(chunks) != 2 {
+		t.Errorf("Expected 2 chunks, got %q", chunks)
+	}
+}
+
+func TestStream_NonStreamingClient(t *testing.T) {
+	client := NewHeuristicClient()
+	if _, ok := interface{}(client).(StreamingClient); ok {
+		t.Fatal("Expected the heuristic client not to stream")
+	}
+
+	var chunks []string
+	result, err := Stream(context.Background(), client, &Request{Diff: "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new\n"}, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(chunks) != 1 || chunks[0] != result {
+		t.Errorf("Expected the whole message as one chunk, got %q", chunks)
+	}
+}
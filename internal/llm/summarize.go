@@ -0,0 +1,101 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/siddhartha/rune/internal/git"
+)
+
+const (
+	// maxSummaryConcurrency bounds the parts summarized at the same time
+	maxSummaryConcurrency = 4
+	// maxSummaryPaths bounds the file names listed in the label of a summary
+	maxSummaryPaths = 5
+)
+
+// ErrSummaryNotSupported is returned by clients that only write commit messages
+var ErrSummaryNotSupported = errors.New("the heuristic generator cannot summarize diffs")
+
+// SummarizeDiff splits the diff of request into parts of at most partBytes and summarizes each
+// part with client, using a bounded number of parallel requests and the options of request.
+// Each part gets its own deadline of partTimeout once it is sent, 0 for none.
+// The labelled summaries are meant for Request.Summaries, so that the commit message can be
+// written from them. onProgress, if set, is called with the number of finished parts. The
+// first failure cancels the remaining parts.
+func SummarizeDiff(ctx context.Context, client LLMClient, request *Request, partBytes int, partTimeout time.Duration, onProgress func(done, total int)) ([]string, error) {
+	parts := git.SplitDiff(request.Diff, partBytes)
+	if len(parts) == 0 {
+		return nil, ErrNoFileChanges
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	summaries := make([]string, len(parts))
+	var (
+		mu       sync.Mutex
+		done     int
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	slots := make(chan struct{}, maxSummaryConcurrency)
+	for i, part := range parts {
+		wg.Add(1)
+		go func(i int, part git.DiffPart) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+
+			partCtx, cancelPart := ctx, context.CancelFunc(func() {})
+			if partTimeout > 0 {
+				partCtx, cancelPart = context.WithTimeout(ctx, partTimeout)
+			}
+			defer cancelPart()
+
+			summary, err := retryTruncated(partCtx, &Request{Diff: part.Diff, Summarize: true, Options: request.Options}, func(request *Request) (string, error) {
+				return client.GenerateCommitMessage(partCtx, request)
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to summarize part %d of %d: %w", i+1, len(parts), err)
+					cancel()
+				}
+				return
+			}
+			summaries[i] = fmt.Sprintf("Part %d (%s):\n%s", i+1, describePaths(part.Paths), strings.TrimSpace(summary))
+			done++
+			if onProgress != nil {
+				onProgress(done, len(parts))
+			}
+		}(i, part)
+	}
+	wg.Wait()
+
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return summaries, nil
+}
+
+// describePaths lists the first file names of a part
+func describePaths(paths []string) string {
+	if len(paths) <= maxSummaryPaths {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more files", strings.Join(paths[:maxSummaryPaths], ", "), len(paths)-maxSummaryPaths)
+}
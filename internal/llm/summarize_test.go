@@ -0,0 +1,168 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// largeDiff returns a diff of n files with a few lines each
+func largeDiff(n int) string {
+	var diff strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&diff, "diff --git a/pkg%d/file.go b/pkg%d/file.go\n--- a/pkg%d/file.go\n+++ b/pkg%d/file.go\n@@ -1 +1 @@\n-old %d\n+new %d\n", i, i, i, i, i, i)
+	}
+	return diff.String()
+}
+
+func TestSummarizeDiff(t *testing.T) {
+	var active, maxActive, calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			seen := atomic.LoadInt32(&maxActive)
+			if now <= seen || atomic.CompareAndSwapInt32(&maxActive, seen, now) {
+				break
+			}
+		}
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+
+		var req ChatCompletionRequest
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("Failed to parse request: %v", err)
+			return
+		}
+		if req.ResponseFormat != nil {
+			t.Error("Expected summaries to be requested as text in structured mode")
+		}
+		prompt := req.Messages[len(req.Messages)-1].Content
+		if !strings.Contains(prompt, "one part of a change") {
+			t.Errorf("Expected the summary prompt, got %q", prompt)
+		}
+
+		_, _ = w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "- Change a file\n"}, "finish_reason": "stop"}]}`))
+	}))
+	defer server.Close()
+
+	client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m", StructuredOutput: true})
+
+	var mu sync.Mutex
+	var progress []int
+	summaries, err := SummarizeDiff(context.Background(), client, &Request{Diff: largeDiff(12)}, 200, 0, func(done, total int) {
+		mu.Lock()
+		defer mu.Unlock()
+		if total != 12 {
+			t.Errorf("Expected a part per file, got %d parts", total)
+		}
+		progress = append(progress, done)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if n := atomic.LoadInt32(&calls); len(summaries) != 12 || n != 12 {
+		t.Errorf("Expected 12 summaries from 12 calls, got %d from %d", len(summaries), n)
+	}
+	if n := atomic.LoadInt32(&maxActive); n > maxSummaryConcurrency {
+		t.Errorf("Expected at most %d parallel requests, got %d", maxSummaryConcurrency, n)
+	}
+	if len(progress) != 12 || progress[len(progress)-1] != 12 {
+		t.Errorf("Expected progress up to 12, got %v", progress)
+	}
+	if summaries[0] != "Part 1 (pkg0/file.go):\n- Change a file" {
+		t.Errorf("Unexpected first summary %q", summaries[0])
+	}
+}
+
+func TestSummarizeDiff_FailureCancelsRemainingParts(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: server.URL, APIKey: "key", Model: "m"})
+
+	_, err := SummarizeDiff(context.Background(), client, &Request{Diff: largeDiff(40)}, 200, 0, nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to summarize part") {
+		t.Fatalf("Expected a part to fail, got %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n >= 40 {
+		t.Errorf("Expected the failure to cancel the remaining parts, got %d calls", n)
+	}
+}
+
+func TestBuildCommitPrompt_Summaries(t *testing.T) {
+	prompt := BuildCommitPrompt(&Request{
+		Diff:      "diff --git a/secret.go b/secret.go",
+		Summaries: []string{"Part 1 (a.go):\n- Add a", "Part 2 (b.go):\n- Remove b"},
+	})
+
+	if strings.Contains(prompt, "secret.go") {
+		t.Error("Expected the diff to be replaced by the summaries")
+	}
+	for _, expected := range []string{"too large to include", "Part 1 (a.go):\n- Add a", "Part 2 (b.go):\n- Remove b", textOutputInstruction} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("Expected prompt to contain %q:\n%s", expected, prompt)
+		}
+	}
+}
+
+// slowClient is an LLMClient that answers after delay unless its context is done first
+type slowClient struct {
+	delay time.Duration
+}
+
+func (c *slowClient) GenerateCommitMessage(ctx context.Context, request *Request) (string, error) {
+	select {
+	case <-time.After(c.delay):
+		return "- Change a file", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func TestSummarizeDiff_DeadlinePerPart(t *testing.T) {
+	// 12 parts in batches of 4 take three times as long as a single part
+	summaries, err := SummarizeDiff(context.Background(), &slowClient{delay: 40 * time.Millisecond}, &Request{Diff: largeDiff(12)}, 200, 100*time.Millisecond, nil)
+	if err != nil || len(summaries) != 12 {
+		t.Fatalf("Expected every part to finish within its own deadline, got %d summaries (%v)", len(summaries), err)
+	}
+
+	_, err = SummarizeDiff(context.Background(), &slowClient{delay: time.Second}, &Request{Diff: largeDiff(4)}, 200, 20*time.Millisecond, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a slow part to exceed its deadline, got %v", err)
+	}
+}
+
+func TestSummarizeDiff_Heuristic(t *testing.T) {
+	_, err := SummarizeDiff(context.Background(), NewHeuristicClient(), &Request{Diff: largeDiff(4)}, 200, 0, nil)
+	if !errors.Is(err, ErrSummaryNotSupported) {
+		t.Errorf("Expected ErrSummaryNotSupported, got %v", err)
+	}
+
+	// A heuristic at the end of a fallback chain reports why the models failed instead
+	rateLimited := &APIError{Provider: "OpenRouter", StatusCode: http.StatusTooManyRequests, Body: "rate limited"}
+	client := NewFallbackClient([]NamedClient{
+		{Name: "a", Client: &failingClient{err: rateLimited}},
+		{Name: "Offline heuristic", Client: NewHeuristicClient()},
+	}, nil)
+	_, err = SummarizeDiff(context.Background(), client, &Request{Diff: largeDiff(4)}, 200, 0, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected the error of the model, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/siddhartha/rune/internal/models"
+)
+
+const (
+	// bytesPerToken approximates how much text a token covers for code and English
+	bytesPerToken = 4
+	// OutputTokenReserve is the room left in the context window for the generated message
+	OutputTokenReserve = 1000
+)
+
+// TokenCounter is implemented by clients whose provider can count the tokens of a prompt exactly
+type TokenCounter interface {
+	CountTokens(ctx context.Context, request *Request) (int, error)
+}
+
+// OutputReserve returns the room left in the context window of model for its output,
+// reasoning models also need room for their reasoning
+func OutputReserve(model *models.ModelInfo) int {
+	if model.Reasoning {
+		return ReasoningMaxTokens
+	}
+	return OutputTokenReserve
+}
+
+// EstimateTokens approximates the number of tokens in text without calling a provider
+func EstimateTokens(text string) int {
+	return (len(text) + bytesPerToken - 1) / bytesPerToken
+}
+
+// EstimateBytes converts a token count back to the approximate size of text
+func EstimateBytes(tokens int) int {
+	return tokens * bytesPerToken
+}
+
+// CountPromptTokens returns the number of prompt tokens of a request for client. The count
+// is exact when the provider can count tokens, otherwise it is estimated from the prompt size.
+func CountPromptTokens(ctx context.Context, client LLMClient, request *Request) (tokens int, exact bool) {
+	if counter, ok := client.(TokenCounter); ok {
+		if tokens, err := counter.CountTokens(ctx, request); err == nil {
+			return tokens, true
+		}
+	}
+
+	prompt := BuildCommitPrompt(request)
+	if isStructured(client) {
+		prompt = BuildStructuredCommitPrompt(request)
+	}
+	for _, turn := range request.History {
+		prompt += turn.Content
+	}
+	return EstimateTokens(defaultSystemPrompt + prompt), false
+}
+
+// CountTokens counts the prompt tokens with the first model of the chain, which is the one
+// the request is sent to unless it fails
+func (c *FallbackClient) CountTokens(ctx context.Context, request *Request) (int, error) {
+	if len(c.clients) == 0 {
+		return 0, fmt.Errorf("no models available")
+	}
+	counter, ok := c.clients[0].Client.(TokenCounter)
+	if !ok {
+		return 0, fmt.Errorf("%s cannot count tokens", c.clients[0].Name)
+	}
+	return counter.CountTokens(ctx, request)
+}
@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{strings.Repeat("x", 400_000), 100_000},
+	}
+
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.expected {
+			t.Errorf("EstimateTokens(%d bytes) = %d, want %d", len(tt.text), got, tt.expected)
+		}
+	}
+}
+
+func TestGeminiClient_CountTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models/gemini-2.0-flash:countTokens" {
+			t.Errorf("Expected countTokens path, got %s", r.URL.Path)
+		}
+		if r.Header.Get("x-goog-api-key") != "test-key" {
+			t.Errorf("Expected API key header, got %q", r.Header.Get("x-goog-api-key"))
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var request map[string]json.RawMessage
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Errorf("Failed to parse request: %v", err)
+		}
+		if _, ok := request["generationConfig"]; ok {
+			t.Error("Expected countTokens request without generationConfig")
+		}
+		if !strings.Contains(string(request["contents"]), "some diff") {
+			t.Errorf("Expected the prompt in contents, got %s", request["contents"])
+		}
+
+		_, _ = w.Write([]byte(`{"totalTokens": 1234}`))
+	}))
+	defer server.Close()
+
+	client := NewGeminiClientWithConfig("test-key", server.URL+"/v1beta/models/gemini-2.0-flash:generateContent", "gemini-2.0-flash")
+	tokens, exact := CountPromptTokens(context.Background(), client, &Request{Diff: "some diff"})
+
+	if tokens != 1234 || !exact {
+		t.Errorf("Expected exact count of 1234, got %d (exact %v)", tokens, exact)
+	}
+}
+
+func TestCountPromptTokens_Estimate(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer failing.Close()
+
+	request := &Request{Diff: strings.Repeat("+line\n", 10_000)}
+	minimum := EstimateTokens(request.Diff)
+
+	tests := []struct {
+		name   string
+		client LLMClient
+	}{
+		{"client without token counting", NewHeuristicClient()},
+		{"count request fails", NewGeminiClientWithConfig("bad-key", failing.URL+"/models/g:generateContent", "g")},
+		{"fallback chain without counter", NewFallbackClient([]NamedClient{{Name: "heuristic", Client: NewHeuristicClient()}}, nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, exact := CountPromptTokens(context.Background(), tt.client, request)
+			if exact {
+				t.Error("Expected an estimate")
+			}
+			if tokens <= minimum {
+				t.Errorf("Expected estimate above the diff alone (%d), got %d", minimum, tokens)
+			}
+		})
+	}
+}
+
+func TestFallbackClient_CountTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"totalTokens": 42}`))
+	}))
+	defer server.Close()
+
+	chain := NewFallbackClient([]NamedClient{
+		{Name: "gemini", Client: NewGeminiClientWithConfig("key", server.URL+"/models/g:generateContent", "g")},
+		{Name: "heuristic", Client: NewHeuristicClient()},
+	}, nil)
+
+	tokens, exact := CountPromptTokens(context.Background(), chain, &Request{Diff: "diff"})
+	if tokens != 42 || !exact {
+		t.Errorf("Expected the first model to count 42 tokens, got %d (exact %v)", tokens, exact)
+	}
+}
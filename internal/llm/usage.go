@@ -0,0 +1,40 @@
+package llm
+
+import (
+	"context"
+	"time"
+)
+
+// Usage describes the tokens used by a single provider call
+type Usage struct {
+	Provider         string        // Display name of the provider or endpoint
+	Model            string        // Model ID sent to the provider
+	PromptTokens     int           // Input tokens, including the diff
+	CompletionTokens int           // Output tokens, including any reasoning
+	Latency          time.Duration // Time from sending the request to the complete response, including retries
+}
+
+// UsageProvider returns the provider name that client reports in Usage, "" for clients such
+// as the heuristic generator that make no provider calls
+func UsageProvider(client LLMClient) string {
+	if named, ok := client.(interface{ providerName() string }); ok {
+		return named.providerName()
+	}
+	return ""
+}
+
+// usageRecorderKey is the context key of the Usage callback
+type usageRecorderKey struct{}
+
+// WithUsageRecorder returns a context that reports the usage of every completed provider call
+// to record. Calls may run in parallel, so record must be safe for concurrent use.
+func WithUsageRecorder(ctx context.Context, record func(Usage)) context.Context {
+	return context.WithValue(ctx, usageRecorderKey{}, record)
+}
+
+// recordUsage reports usage to the recorder of ctx, if any
+func recordUsage(ctx context.Context, usage Usage) {
+	if record, ok := ctx.Value(usageRecorderKey{}).(func(Usage)); ok && record != nil {
+		record(usage)
+	}
+}
@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUsageRecorder(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		client     func(url string) LLMClient
+		stream     bool
+		wantPrompt int
+		wantOutput int
+		wantModel  string
+	}{
+		{
+			name: "chat completion",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Add usage"}, "finish_reason": "stop"}],
+					"usage": {"prompt_tokens": 812, "completion_tokens": 9, "total_tokens": 821}}`))
+			},
+			client: func(url string) LLMClient {
+				return newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: url, APIKey: "key", Model: "m"})
+			},
+			wantPrompt: 812, wantOutput: 9, wantModel: "m",
+		},
+		{
+			name: "streamed chat completion",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				var req ChatCompletionRequest
+				body, _ := io.ReadAll(r.Body)
+				if err := json.Unmarshal(body, &req); err != nil || req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+					t.Errorf("Expected include_usage, got %s", body)
+				}
+				for _, event := range []string{
+					`{"choices":[{"index":0,"delta":{"content":"Add usage"}}]}`,
+					`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+					`{"choices":[],"usage":{"prompt_tokens":640,"completion_tokens":4}}`,
+					`[DONE]`,
+				} {
+					_, _ = w.Write([]byte("data: " + event + "\n\n"))
+				}
+			},
+			client: func(url string) LLMClient {
+				return newOpenAICompatibleClient(OpenAICompatibleConfig{Name: "Test", BaseURL: url, APIKey: "key", Model: "m"})
+			},
+			stream:     true,
+			wantPrompt: 640, wantOutput: 4, wantModel: "m",
+		},
+		{
+			name: "Gemini usage metadata",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"candidates": [{"content": {"parts": [{"text": "Add usage"}], "role": "model"}, "finishReason": "STOP"}],
+					"usageMetadata": {"promptTokenCount": 1500, "candidatesTokenCount": 12, "thoughtsTokenCount": 300, "totalTokenCount": 1812}}`))
+			},
+			client: func(url string) LLMClient {
+				return NewGeminiClientWithConfig("key", url, "gemini-1.5-flash")
+			},
+			wantPrompt: 1500, wantOutput: 312, wantModel: "gemini-1.5-flash",
+		},
+		{
+			name: "Anthropic usage",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"content": [{"type": "text", "text": "Add usage"}], "stop_reason": "end_turn",
+					"usage": {"input_tokens": 980, "output_tokens": 11}}`))
+			},
+			client: func(url string) LLMClient {
+				return NewAnthropicClientWithConfig("key", url, "claude-3-5-haiku-20241022")
+			},
+			wantPrompt: 980, wantOutput: 11, wantModel: "claude-3-5-haiku-20241022",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			var recorded []Usage
+			ctx := WithUsageRecorder(context.Background(), func(usage Usage) {
+				recorded = append(recorded, usage)
+			})
+
+			client := tt.client(server.URL)
+			var err error
+			if tt.stream {
+				_, err = Stream(ctx, client, &Request{Diff: "some diff"}, nil)
+			} else {
+				_, err = client.GenerateCommitMessage(ctx, &Request{Diff: "some diff"})
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(recorded) != 1 {
+				t.Fatalf("Expected 1 usage record, got %d", len(recorded))
+			}
+			usage := recorded[0]
+			if usage.PromptTokens != tt.wantPrompt || usage.CompletionTokens != tt.wantOutput || usage.Model != tt.wantModel {
+				t.Errorf("Unexpected usage %+v", usage)
+			}
+			if usage.Latency <= 0 {
+				t.Errorf("Expected a latency, got %s", usage.Latency)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package models
+
+// BedrockPrefix selects any Bedrock model or inference profile ID,
+// e.g. "bedrock/us.anthropic.claude-3-5-haiku-20241022-v1:0"
+const BedrockPrefix = "bedrock/"
+
+// BedrockModel returns model info for a Bedrock model ID that is not in the registry
+func BedrockModel(id string) *ModelInfo {
+	return &ModelInfo{
+		ID:          id,
+		ShortName:   BedrockPrefix + id,
+		Name:        id,
+		Provider:    "bedrock",
+		Company:     "AWS",
+		Description: "Bedrock model",
+	}
+}
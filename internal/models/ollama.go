@@ -0,0 +1,98 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OllamaPrefix selects a locally installed Ollama model, e.g. "ollama/qwen2.5-coder:7b"
+const OllamaPrefix = "ollama/"
+
+// ollamaTagsResponse is the response of Ollama's /api/tags endpoint
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name    string `json:"name"`
+		Size    int64  `json:"size"`
+		Details struct {
+			Family            string `json:"family"`
+			ParameterSize     string `json:"parameter_size"`
+			QuantizationLevel string `json:"quantization_level"`
+		} `json:"details"`
+	} `json:"models"`
+}
+
+// ollamaReasoningModels are the names of Ollama models that think before answering
+var ollamaReasoningModels = []string{"deepseek-r1", "qwq"}
+
+// OllamaModel returns model info for an Ollama model that is not in the registry
+func OllamaModel(name string) *ModelInfo {
+	return &ModelInfo{
+		ID:          name,
+		ShortName:   OllamaPrefix + name,
+		Name:        name,
+		Provider:    "ollama",
+		Company:     "Local",
+		Description: "Locally installed Ollama model",
+		Reasoning:   isOllamaReasoningModel(name),
+		Pricing:     free,
+	}
+}
+
+// isOllamaReasoningModel reports whether an Ollama model name, e.g. "deepseek-r1:14b", is a reasoning model
+func isOllamaReasoningModel(name string) bool {
+	base, _, _ := strings.Cut(name, ":")
+	for _, reasoning := range ollamaReasoningModels {
+		if base == reasoning {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchOllamaModels lists the models installed on an Ollama server
+func FetchOllamaModels(ctx context.Context, host string) ([]*ModelInfo, error) {
+	url := strings.TrimSuffix(host, "/") + "/api/tags"
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Ollama at %s: %w", host, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list Ollama models: status %d", resp.StatusCode)
+	}
+
+	var tags ollamaTagsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to parse Ollama tags: %w", err)
+	}
+
+	installed := make([]*ModelInfo, 0, len(tags.Models))
+	for _, tag := range tags.Models {
+		model := OllamaModel(tag.Name)
+		if tag.Details.Family != "" {
+			model.Company = tag.Details.Family
+		}
+		var details []string
+		for _, detail := range []string{tag.Details.ParameterSize, tag.Details.QuantizationLevel} {
+			if detail != "" {
+				details = append(details, detail)
+			}
+		}
+		if len(details) > 0 {
+			model.Description = "Installed locally, " + strings.Join(details, " ")
+		}
+		installed = append(installed, model)
+	}
+	return installed, nil
+}
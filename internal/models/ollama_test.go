@@ -0,0 +1,110 @@
+package models
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchOllamaModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("Expected /api/tags, got %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err := w.Write([]byte(`{"models": [
+			{"name": "llama3.2:latest", "size": 2019393189, "details": {"family": "llama", "parameter_size": "3.2B", "quantization_level": "Q4_K_M"}},
+			{"name": "qwen2.5-coder:7b", "details": {}}
+		]}`))
+		if err != nil {
+			t.Errorf("Failed to write response body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	installed, err := FetchOllamaModels(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(installed) != 2 {
+		t.Fatalf("Expected 2 models, got %d", len(installed))
+	}
+
+	if installed[0].ID != "llama3.2:latest" || installed[0].Provider != "ollama" {
+		t.Errorf("Unexpected model: %+v", installed[0])
+	}
+	if installed[0].Company != "llama" || installed[0].Description != "Installed locally, 3.2B Q4_K_M" {
+		t.Errorf("Expected details in model info, got %+v", installed[0])
+	}
+	if installed[1].Description != "Locally installed Ollama model" {
+		t.Errorf("Expected default description, got %q", installed[1].Description)
+	}
+}
+
+func TestFetchOllamaModels_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if _, err := FetchOllamaModels(context.Background(), server.URL); err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("Expected status error, got %v", err)
+	}
+
+	server.Close()
+	if _, err := FetchOllamaModels(context.Background(), server.URL); err == nil || !strings.Contains(err.Error(), "failed to reach Ollama") {
+		t.Errorf("Expected connection error, got %v", err)
+	}
+}
+
+func TestFindModel_Ollama(t *testing.T) {
+	tests := []struct {
+		query    string
+		wantID   string
+		wantName string
+	}{
+		{query: "ol", wantID: "llama3.2", wantName: "Llama 3.2 (Ollama)"},
+		{query: "ollama", wantID: "llama3.2", wantName: "Llama 3.2 (Ollama)"},
+		{query: "ollama/llama3.2", wantID: "llama3.2", wantName: "Llama 3.2 (Ollama)"},
+		{query: "ollama/qwen2.5-coder:7b", wantID: "qwen2.5-coder:7b", wantName: "qwen2.5-coder:7b"},
+		{query: "Ollama/hf.co/TheBloke/Mistral-GGUF:Q4_K_M", wantID: "hf.co/TheBloke/Mistral-GGUF:Q4_K_M", wantName: "hf.co/TheBloke/Mistral-GGUF:Q4_K_M"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			model, err := FindModel(tt.query)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if model.ID != tt.wantID || model.Name != tt.wantName || model.Provider != "ollama" {
+				t.Errorf("FindModel(%q) = %+v", tt.query, model)
+			}
+		})
+	}
+
+	if _, err := FindModel("ollama/"); err == nil {
+		t.Error("Expected error for empty Ollama model name")
+	}
+}
+
+func TestOllamaModel_Reasoning(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"deepseek-r1", true},
+		{"deepseek-r1:14b", true},
+		{"qwq:32b", true},
+		{"qwen2.5-coder:7b", false},
+		{"llama3.2", false},
+	}
+
+	for _, tt := range tests {
+		if got := OllamaModel(tt.name).Reasoning; got != tt.expected {
+			t.Errorf("Expected Reasoning = %v for %s, got %v", tt.expected, tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package models
+
+// Pricing is the list price of a model in US dollars per million tokens
+type Pricing struct {
+	Input  float64 // per million prompt tokens
+	Output float64 // per million completion tokens
+}
+
+// free is the pricing of local and free tier models
+var free = &Pricing{}
+
+// Cost returns the price of a call with the given token counts
+func (p *Pricing) Cost(promptTokens, completionTokens int) float64 {
+	if p == nil {
+		return 0
+	}
+	return (float64(promptTokens)*p.Input + float64(completionTokens)*p.Output) / 1_000_000
+}
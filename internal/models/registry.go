@@ -14,45 +14,215 @@ type ModelInfo struct {
 	ID          string // Full model ID (e.g., "deepseek/deepseek-chat")
 	ShortName   string // Short name (e.g., "deepseek", "qwen")
 	Name        string // Display name
-	Provider    string // Provider (novita, gemini, openrouter)
+	Provider    string // Provider (novita, gemini, openrouter, anthropic, bedrock, ollama, heuristic)
 	Company     string // Company that created the model
 	Description string // Brief description
 	ContextSize int    // Context window size
 	IsDefault   bool   // Whether this is the default for the provider
+	Endpoint    string // Named endpoint serving the model (openai-compatible provider only)
+
+	// StructuredOutput is set when the model can return the commit message as a JSON object
+	// (JSON schema response format, Gemini response schema or tool use). Other models use text mode.
+	StructuredOutput bool
+
+	// Reasoning is set for models that think before answering, in <think> blocks or a separate
+	// reasoning field. They get a larger output limit so that the answer is not cut off.
+	Reasoning bool
+
+	// Pricing is used to estimate the cost of each call, nil when the price is unknown
+	Pricing *Pricing
 }
 
 // ModelRegistry holds all available models
 var ModelRegistry = map[string]*ModelInfo{
+	// Novita.ai models (OpenAI-compatible API)
+	"novita/qwen2.5-7b-instruct": {
+		ID:          "qwen/qwen2.5-7b-instruct",
+		ShortName:   "q",
+		Name:        "Qwen2.5 7B Instruct",
+		Provider:    "novita",
+		Company:     "Alibaba",
+		Description: "Small and fast, good for everyday commits",
+		ContextSize: 32000,
+		IsDefault:   true,
+	},
+	"novita/qwen-2.5-72b-instruct": {
+		ID:          "qwen/qwen-2.5-72b-instruct",
+		ShortName:   "q72",
+		Name:        "Qwen2.5 72B Instruct",
+		Provider:    "novita",
+		Company:     "Alibaba",
+		Description: "Larger Qwen model for complex changes",
+		ContextSize: 32000,
+		IsDefault:   false,
+	},
+	"novita/deepseek-v3": {
+		ID:          "deepseek/deepseek-v3-0324",
+		ShortName:   "nd",
+		Name:        "DeepSeek V3 (Novita)",
+		Provider:    "novita",
+		Company:     "DeepSeek",
+		Description: "Large context window, excellent code understanding",
+		ContextSize: 163840,
+		IsDefault:   false,
+	},
+	"novita/llama-3.1-8b-instruct": {
+		ID:          "meta-llama/llama-3.1-8b-instruct",
+		ShortName:   "nl",
+		Name:        "Llama 3.1 8B Instruct (Novita)",
+		Provider:    "novita",
+		Company:     "Meta",
+		Description: "Lightweight and inexpensive",
+		ContextSize: 16384,
+		IsDefault:   false,
+	},
+
 	// Gemini models (direct Google provider)
 	"gemini-1.5-flash": {
-		ID:          "gemini-1.5-flash",
-		ShortName:   "g15",
-		Name:        "Gemini 1.5 Flash",
-		Provider:    "gemini",
-		Company:     "Google",
-		Description: "Fast and efficient model for quick tasks",
-		ContextSize: 1000000,
-		IsDefault:   false,
+		ID:               "gemini-1.5-flash",
+		ShortName:        "g15",
+		Name:             "Gemini 1.5 Flash",
+		Provider:         "gemini",
+		Company:          "Google",
+		Description:      "Fast and efficient model for quick tasks",
+		ContextSize:      1000000,
+		IsDefault:        false,
+		StructuredOutput: true,
+		Pricing:          &Pricing{Input: 0.075, Output: 0.30},
 	},
 	"gemini-1.5-pro": {
-		ID:          "gemini-1.5-pro",
-		ShortName:   "gp",
-		Name:        "Gemini 1.5 Pro",
-		Provider:    "gemini",
-		Company:     "Google",
-		Description: "More capable model for complex reasoning",
-		ContextSize: 2000000,
-		IsDefault:   false,
+		ID:               "gemini-1.5-pro",
+		ShortName:        "gp",
+		Name:             "Gemini 1.5 Pro",
+		Provider:         "gemini",
+		Company:          "Google",
+		Description:      "More capable model for complex reasoning",
+		ContextSize:      2000000,
+		IsDefault:        false,
+		StructuredOutput: true,
+		Pricing:          &Pricing{Input: 1.25, Output: 5.00},
 	},
 	"gemini-2.0-flash-exp": {
-		ID:          "gemini-2.0-flash-exp",
-		ShortName:   "g2",
-		Name:        "Gemini 2.0 Flash Experimental",
-		Provider:    "gemini",
-		Company:     "Google",
-		Description: "Latest experimental model with improved capabilities",
-		ContextSize: 1000000,
+		ID:               "gemini-2.0-flash-exp",
+		ShortName:        "g2",
+		Name:             "Gemini 2.0 Flash Experimental",
+		Provider:         "gemini",
+		Company:          "Google",
+		Description:      "Latest experimental model with improved capabilities",
+		ContextSize:      1000000,
+		IsDefault:        true,
+		StructuredOutput: true,
+		Pricing:          free,
+	},
+
+	// Anthropic models (direct Messages API)
+	"claude-3-5-haiku": {
+		ID:               "claude-3-5-haiku-20241022",
+		ShortName:        "ch",
+		Name:             "Claude 3.5 Haiku",
+		Provider:         "anthropic",
+		Company:          "Anthropic",
+		Description:      "Fast and inexpensive, well suited to commit messages",
+		ContextSize:      200000,
+		IsDefault:        true,
+		StructuredOutput: true,
+		Pricing:          &Pricing{Input: 0.80, Output: 4.00},
+	},
+	"claude-sonnet-4": {
+		ID:               "claude-sonnet-4-20250514",
+		ShortName:        "cs",
+		Name:             "Claude Sonnet 4",
+		Provider:         "anthropic",
+		Company:          "Anthropic",
+		Description:      "Strong code understanding for large or subtle changes",
+		ContextSize:      200000,
+		IsDefault:        false,
+		StructuredOutput: true,
+		Pricing:          &Pricing{Input: 3.00, Output: 15.00},
+	},
+	"claude-opus-4-1": {
+		ID:               "claude-opus-4-1-20250805",
+		ShortName:        "co",
+		Name:             "Claude Opus 4.1",
+		Provider:         "anthropic",
+		Company:          "Anthropic",
+		Description:      "Most capable Claude model for complex refactors",
+		ContextSize:      200000,
+		IsDefault:        false,
+		StructuredOutput: true,
+		Pricing:          &Pricing{Input: 15.00, Output: 75.00},
+	},
+
+	// AWS Bedrock models (any model or inference profile can be used via "bedrock/<id>")
+	"bedrock/claude-3-5-haiku": {
+		ID:          "anthropic.claude-3-5-haiku-20241022-v1:0",
+		ShortName:   "bch",
+		Name:        "Claude 3.5 Haiku (Bedrock)",
+		Provider:    "bedrock",
+		Company:     "Anthropic",
+		Description: "Fast Claude model in your AWS account",
+		ContextSize: 200000,
 		IsDefault:   true,
+		Pricing:     &Pricing{Input: 0.80, Output: 4.00},
+	},
+	"bedrock/claude-sonnet-4": {
+		ID:          "anthropic.claude-sonnet-4-20250514-v1:0",
+		ShortName:   "bcs",
+		Name:        "Claude Sonnet 4 (Bedrock)",
+		Provider:    "bedrock",
+		Company:     "Anthropic",
+		Description: "Strong code understanding, may need a regional inference profile",
+		ContextSize: 200000,
+		IsDefault:   false,
+		Pricing:     &Pricing{Input: 3.00, Output: 15.00},
+	},
+	"bedrock/llama3-1-70b": {
+		ID:          "meta.llama3-1-70b-instruct-v1:0",
+		ShortName:   "bl",
+		Name:        "Llama 3.1 70B Instruct (Bedrock)",
+		Provider:    "bedrock",
+		Company:     "Meta",
+		Description: "Open weights model hosted by AWS",
+		ContextSize: 128000,
+		IsDefault:   false,
+		Pricing:     &Pricing{Input: 0.72, Output: 0.72},
+	},
+	"bedrock/mistral-large": {
+		ID:          "mistral.mistral-large-2402-v1:0",
+		ShortName:   "bm",
+		Name:        "Mistral Large (Bedrock)",
+		Provider:    "bedrock",
+		Company:     "Mistral AI",
+		Description: "Capable multilingual model hosted by AWS",
+		ContextSize: 32000,
+		IsDefault:   false,
+		Pricing:     &Pricing{Input: 4.00, Output: 12.00},
+	},
+
+	// Offline heuristic generator (no model, no network)
+	"heuristic": {
+		ID:          "heuristic",
+		ShortName:   "h",
+		Name:        "Offline Heuristic",
+		Provider:    "heuristic",
+		Company:     "Rune",
+		Description: "Rule-based messages from the diff structure, works offline",
+		ContextSize: 0,
+		IsDefault:   true,
+		Pricing:     free,
+	},
+
+	// Ollama models (local server, any installed model can be used via "ollama/<name>")
+	"ollama/llama3.2": {
+		ID:          "llama3.2",
+		ShortName:   "ol",
+		Name:        "Llama 3.2 (Ollama)",
+		Provider:    "ollama",
+		Company:     "Meta",
+		Description: "Runs locally, nothing leaves your machine",
+		ContextSize: 131072,
+		IsDefault:   true,
+		Pricing:     free,
 	},
 
 	// OpenRouter models
@@ -65,6 +235,7 @@ var ModelRegistry = map[string]*ModelInfo{
 		Description: "Large context window, excellent code understanding",
 		ContextSize: 163840,
 		IsDefault:   true,
+		Pricing:     free,
 	},
 	"deepseek/r1": {
 		ID:          "deepseek/deepseek-r1-0528:free",
@@ -75,16 +246,20 @@ var ModelRegistry = map[string]*ModelInfo{
 		Description: "Advanced reasoning and code generation",
 		ContextSize: 163840,
 		IsDefault:   false,
+		Reasoning:   true,
+		Pricing:     free,
 	},
 	"google/gemini-2.0-flash-exp:free": {
-		ID:          "google/gemini-2.0-flash-exp:free",
-		ShortName:   "g2f",
-		Name:        "Gemini 2.0 Flash Experimental",
-		Provider:    "openrouter",
-		Company:     "Google",
-		Description: "Free tier, experimental model with large context",
-		ContextSize: 1048576,
-		IsDefault:   false,
+		ID:               "google/gemini-2.0-flash-exp:free",
+		ShortName:        "g2f",
+		Name:             "Gemini 2.0 Flash Experimental",
+		Provider:         "openrouter",
+		Company:          "Google",
+		Description:      "Free tier, experimental model with large context",
+		ContextSize:      1048576,
+		IsDefault:        false,
+		StructuredOutput: true,
+		Pricing:          free,
 	},
 	"mistralai/mistral-7b-instruct": {
 		ID:          "mistralai/mistral-7b-instruct",
@@ -105,6 +280,7 @@ var ModelRegistry = map[string]*ModelInfo{
 		Description: "Strong programming capabilities, excellent code understanding",
 		ContextSize: 65536,
 		IsDefault:   false,
+		Pricing:     free,
 	},
 	"gryphe/mythomax-l2-13b": {
 		ID:          "gryphe/mythomax-l2-13b",
@@ -125,25 +301,32 @@ var ModelRegistry = map[string]*ModelInfo{
 		Description: "Excellent for coding tasks, up to 450 tokens/sec",
 		ContextSize: 32768,
 		IsDefault:   false,
+		Reasoning:   true,
 	},
 }
 
 // Model aliases for even easier typing
 var ModelAliases = map[string]string{
 	// Ultra-short aliases (1-2 chars)
-	"d": "d",  // DeepSeek (default for OpenRouter)
-	"g": "g2", // Gemini 2.0 (default for Google)
-	"m": "m7", // Mistral 7B
-	"l": "l3", // Llama 3.1
+	"d": "dv3", // DeepSeek (default for OpenRouter)
+	"g": "g2",  // Gemini 2.0 (default for Google)
+	"m": "m7",  // Mistral 7B
+	"l": "l3",  // Llama 3.1
 
 	// Descriptive aliases
-	"deep":    "d",   // DeepSeek
+	"deep":    "dv3", // DeepSeek
 	"gemini":  "g2",  // Gemini 2.0
 	"mistral": "m7",  // Mistral 7B
 	"llama":   "l3",  // Llama 3.1
 	"mytho":   "mx",  // MythoMax
 	"qwen":    "qwq", // Qwen QwQ
 	"pro":     "gp",  // Gemini Pro
+	"claude":  "ch",  // Claude 3.5 Haiku
+	"haiku":   "ch",  // Claude 3.5 Haiku
+	"sonnet":  "cs",  // Claude Sonnet 4
+	"opus":    "co",  // Claude Opus 4.1
+	"offline": "h",   // Offline heuristic
+	"local":   "ol",  // Ollama
 
 	// Version-specific aliases
 	"g1":  "g15", // Gemini 1.5
@@ -153,13 +336,19 @@ var ModelAliases = map[string]string{
 	"l3":  "l3",  // Llama 3.1
 
 	// Provider shortcuts
-	"google":     "g2", // Default Google model
-	"openrouter": "d",  // Default OpenRouter model
+	"google":     "g2",  // Default Google model
+	"openrouter": "dv3", // Default OpenRouter model
+	"novita":     "q",   // Default Novita model
+	"anthropic":  "ch",  // Default Anthropic model
+	"bedrock":    "bch", // Default Bedrock model
+	"ollama":     "ol",  // Default Ollama model
 }
 
-// FindModel finds a model by ID, short name, or alias
+// FindModel finds a model by ID, short name, or alias. Registry lookups ignore case, while
+// Ollama model names and Bedrock model IDs after their prefix keep it.
 func FindModel(query string) (*ModelInfo, error) {
-	query = strings.TrimSpace(strings.ToLower(query))
+	original := strings.TrimSpace(query)
+	query = strings.ToLower(original)
 
 	// First try exact ID match
 	for id, model := range ModelRegistry {
@@ -168,6 +357,13 @@ func FindModel(query string) (*ModelInfo, error) {
 		}
 	}
 
+	// Then try the provider model ID, which is what the config stores
+	for _, model := range ModelRegistry {
+		if strings.ToLower(model.ID) == query {
+			return model, nil
+		}
+	}
+
 	// Then try short name match
 	for _, model := range ModelRegistry {
 		if strings.ToLower(model.ShortName) == query {
@@ -175,13 +371,21 @@ func FindModel(query string) (*ModelInfo, error) {
 		}
 	}
 
-	// Finally try alias match
+	// Then try alias match
 	if aliasTarget, exists := ModelAliases[query]; exists {
 		// Recursively resolve alias
 		return FindModel(aliasTarget)
 	}
 
-	return nil, fmt.Errorf("model not found: %s", query)
+	// Finally accept any locally installed Ollama model or Bedrock model ID
+	if strings.HasPrefix(query, OllamaPrefix) && len(original) > len(OllamaPrefix) {
+		return OllamaModel(original[len(OllamaPrefix):]), nil
+	}
+	if strings.HasPrefix(query, BedrockPrefix) && len(original) > len(BedrockPrefix) {
+		return BedrockModel(original[len(BedrockPrefix):]), nil
+	}
+
+	return nil, fmt.Errorf("model not found: %s", original)
 }
 
 // GetModelsByProvider returns all models for a specific provider
@@ -240,7 +444,7 @@ func FormatModelsHelp() string {
 	var help strings.Builder
 	help.WriteString("\nAvailable models:\n")
 
-	providers := []string{"gemini", "openrouter"}
+	providers := []string{"novita", "gemini", "openrouter", "anthropic", "bedrock", "ollama", "heuristic"}
 
 	for _, provider := range providers {
 		models := GetModelsByProvider(provider)
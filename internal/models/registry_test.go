@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestFindModel(t *testing.T) {
+	tests := []struct {
+		query        string
+		wantID       string
+		wantProvider string
+	}{
+		{query: "q", wantID: "qwen/qwen2.5-7b-instruct", wantProvider: "novita"},
+		{query: "novita", wantID: "qwen/qwen2.5-7b-instruct", wantProvider: "novita"},
+		{query: "qwen/qwen2.5-7b-instruct", wantID: "qwen/qwen2.5-7b-instruct", wantProvider: "novita"},
+		{query: "nd", wantID: "deepseek/deepseek-v3-0324", wantProvider: "novita"},
+		{query: "novita/llama-3.1-8b-instruct", wantID: "meta-llama/llama-3.1-8b-instruct", wantProvider: "novita"},
+		{query: "d", wantID: "deepseek/deepseek-chat-v3:free", wantProvider: "openrouter"},
+		{query: "deep", wantID: "deepseek/deepseek-chat-v3:free", wantProvider: "openrouter"},
+		{query: "G2", wantID: "gemini-2.0-flash-exp", wantProvider: "gemini"},
+		{query: "bedrock/arn:aws:bedrock:us-east-1:123456789012:application-inference-profile/AbCdEf", wantID: "arn:aws:bedrock:us-east-1:123456789012:application-inference-profile/AbCdEf", wantProvider: "bedrock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			model, err := FindModel(tt.query)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if model.ID != tt.wantID || model.Provider != tt.wantProvider {
+				t.Errorf("FindModel(%q) = %s (%s), want %s (%s)", tt.query, model.ID, model.Provider, tt.wantID, tt.wantProvider)
+			}
+		})
+	}
+
+	if _, err := FindModel("no-such-model"); err == nil {
+		t.Error("Expected error for unknown model")
+	}
+}
+
+func TestModelAliasesResolve(t *testing.T) {
+	for alias := range ModelAliases {
+		if _, err := FindModel(alias); err != nil {
+			t.Errorf("Alias %q does not resolve: %v", alias, err)
+		}
+	}
+}
+
+func TestGetDefaultModel(t *testing.T) {
+	for _, provider := range []string{"novita", "gemini", "openrouter", "anthropic", "ollama", "heuristic"} {
+		defaults := 0
+		for _, model := range GetModelsByProvider(provider) {
+			if model.IsDefault {
+				defaults++
+			}
+		}
+		if defaults != 1 {
+			t.Errorf("Expected exactly one default model for %s, got %d", provider, defaults)
+		}
+	}
+}
+
+func TestPricing_Cost(t *testing.T) {
+	haiku, err := FindModel("ch")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 10,000 prompt tokens at $0.80 and 200 completion tokens at $4.00 per million
+	if got, want := haiku.Pricing.Cost(10_000, 200), 0.0088; got < want-1e-9 || got > want+1e-9 {
+		t.Errorf("Expected cost %f, got %f", want, got)
+	}
+
+	var unknown *Pricing
+	if got := unknown.Cost(10_000, 200); got != 0 {
+		t.Errorf("Expected no cost for unknown pricing, got %f", got)
+	}
+	if local := OllamaModel("qwen2.5-coder:7b"); local.Pricing == nil || local.Pricing.Cost(1000, 1000) != 0 {
+		t.Error("Expected local Ollama models to be free")
+	}
+}
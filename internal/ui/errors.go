@@ -83,6 +83,156 @@ func TranslateError(err error) *UserError {
 		}
 	}
 
+	// AWS errors
+	if strings.Contains(errMsg, "failed to load AWS credentials") {
+		return &UserError{
+			Title:       "AWS credentials not found",
+			Description: "Bedrock requests are signed with your AWS credentials, but none were found.",
+			Suggestions: []string{
+				"Set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY",
+				"Configure a profile with 'aws configure' and set it in the \"bedrock\" section of ~/.config/rune/config.json",
+				"Refresh temporary credentials, e.g. with 'aws sso login'",
+			},
+			TechnicalError: err,
+		}
+	}
+
+	if strings.Contains(errMsg, "AccessDeniedException") || strings.Contains(errMsg, "UnrecognizedClientException") {
+		return &UserError{
+			Title:       "Bedrock access denied",
+			Description: "AWS rejected the request for this model.",
+			Suggestions: []string{
+				"Request access to the model in the Bedrock console for your region",
+				"Check that your IAM policy allows bedrock:InvokeModel",
+				"Newer models may require a regional inference profile, e.g. --model bedrock/us.<model-id>",
+			},
+			TechnicalError: err,
+		}
+	}
+
+	// Google Cloud errors
+	if strings.Contains(errMsg, "failed to load Google service account") || strings.Contains(errMsg, "failed to fetch Google access token") {
+		return &UserError{
+			Title:       "Vertex AI authentication failed",
+			Description: "Rune could not get an access token from your Google Cloud service account.",
+			Suggestions: []string{
+				"Set GOOGLE_APPLICATION_CREDENTIALS to a service account JSON key file",
+				"Or set \"credentials_file\" in the \"vertex\" section of ~/.config/rune/config.json",
+				"Check that the key has not been disabled or deleted in the Cloud console",
+			},
+			TechnicalError: err,
+		}
+	}
+
+	// Local model server errors
+	if strings.Contains(errMsg, "failed to reach Ollama") {
+		return &UserError{
+			Title:       "Ollama is not running",
+			Description: "Rune could not connect to the local Ollama server.",
+			Suggestions: []string{
+				"Start the server with 'ollama serve'",
+				"Set ollama_host in ~/.config/rune/config.json or OLLAMA_HOST if it runs elsewhere",
+				"Run 'rune models' to check which models are installed",
+			},
+			TechnicalError: err,
+		}
+	}
+
+	// Safety and content filters
+	if strings.Contains(errMsg, "content filter") {
+		return &UserError{
+			Title:       "Blocked by content filter",
+			Description: "The provider's safety or content filter rejected the diff or the generated message.",
+			Suggestions: []string{
+				"Check the diff for secrets, credentials or generated data and unstage them with 'git restore --staged <file>'",
+				"Switch to another model or provider with --model",
+				"Commit the flagged files separately with a handwritten message",
+				"On Azure OpenAI, ask your administrator to review the content filter policy for this deployment",
+			},
+			TechnicalError: err,
+		}
+	}
+
+	if strings.Contains(errMsg, "recited existing content") {
+		return &UserError{
+			Title:       "Blocked for reciting existing content",
+			Description: "Gemini stopped because the message repeated existing content, such as licensed or vendored code in the diff, too closely.",
+			Suggestions: []string{
+				"Unstage vendored, copied or generated files with 'git restore --staged <file>' and commit them separately",
+				"Switch to another model or provider with --model",
+				"Regenerate, the next attempt may phrase the message differently",
+			},
+			TechnicalError: err,
+		}
+	}
+
+	if strings.Contains(errMsg, "commit message was truncated") || strings.Contains(errMsg, "whole output limit") {
+		return &UserError{
+			Title:       "Commit message was cut off",
+			Description: "The model reached its output limit before finishing the message, even after retrying with a larger limit.",
+			Suggestions: []string{
+				"Raise the limit with --max-tokens or max_tokens in \"model_options\" of ~/.config/rune/config.json",
+				"Reasoning models need more room, or switch to a non-reasoning model with --model",
+				"Stage fewer files to get a shorter message",
+			},
+			TechnicalError: err,
+		}
+	}
+
+	if strings.Contains(errMsg, "did not write a commit message") {
+		return &UserError{
+			Title:       "No commit message in the answer",
+			Description: "The model answered with a refusal or a question instead of a commit message.",
+			Suggestions: []string{
+				"Run rune again, models often answer differently on a retry",
+				"Switch to another model with --model",
+				"Check that the staged changes contain the code you meant to commit",
+			},
+			TechnicalError: err,
+		}
+	}
+
+	if strings.Contains(errMsg, "Azure OpenAI quota exceeded") {
+		return &UserError{
+			Title:       "Azure OpenAI quota exceeded",
+			Description: "The deployment has hit its tokens-per-minute or request rate limit.",
+			Suggestions: []string{
+				"Wait a minute and try again",
+				"Reduce the size of the diff or stage fewer files",
+				"Ask your Azure administrator to raise the deployment's quota",
+				"Map another deployment in the \"azure\" section of ~/.config/rune/config.json",
+			},
+			TechnicalError: err,
+		}
+	}
+
+	if strings.Contains(errMsg, "Azure OpenAI deployment not found") || strings.Contains(errMsg, "no Azure deployment configured") {
+		return &UserError{
+			Title:       "Azure deployment not found",
+			Description: "The model is not mapped to an existing deployment on your Azure OpenAI resource.",
+			Suggestions: []string{
+				"Check the \"deployments\" map in the \"azure\" section of ~/.config/rune/config.json",
+				"Verify the deployment name in the Azure portal",
+				"Check the endpoint and api_version settings",
+			},
+			TechnicalError: err,
+		}
+	}
+
+	// Rate limits that outlasted the retries
+	if strings.Contains(errMsg, "failed with status 429") {
+		return &UserError{
+			Title:       "Rate limit reached",
+			Description: "The AI provider kept rejecting requests after several retries.",
+			Suggestions: []string{
+				"Wait a minute and try again",
+				"Free models (\":free\") have low rate limits, try a paid model with --model",
+				"Check the usage limits of your API key",
+			},
+			TechnicalError: err,
+		}
+	}
+
 	// Network/LLM errors
 	if strings.Contains(errMsg, "failed to generate commit message") {
 		return &UserError{
@@ -126,6 +276,19 @@ func TranslateError(err error) *UserError {
 		}
 	}
 
+	if strings.Contains(errMsg, "endpoint not configured") || strings.Contains(errMsg, "no endpoint selected") {
+		return &UserError{
+			Title:       "Endpoint not configured",
+			Description: "The OpenAI-compatible endpoint is missing from your configuration.",
+			Suggestions: []string{
+				"Add it under \"endpoints\" in ~/.config/rune/config.json",
+				"Run 'rune --setup' and choose the OpenAI-compatible option",
+				"Run 'rune models' to see the configured endpoints",
+			},
+			TechnicalError: err,
+		}
+	}
+
 	if strings.Contains(errMsg, "failed to resolve model") {
 		return &UserError{
 			Title:       "Invalid model",
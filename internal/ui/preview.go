@@ -17,6 +17,9 @@ const (
 	ColorCyan   = "\033[36m"
 )
 
+// previewWidth is the width of the preview box; streamed text is wrapped to fit it
+const previewWidth = 64
+
 // PreviewCommitMessage displays a nicely formatted commit message preview
 func PreviewCommitMessage(message string) {
 	lines := strings.Split(message, "\n")
@@ -24,10 +27,7 @@ func PreviewCommitMessage(message string) {
 		return
 	}
 
-	// Header
-	fmt.Printf("\n%s┏━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━┓%s\n", ColorBold, ColorReset)
-	fmt.Printf("%s┃%s                    %sGenerated Commit Message%s                  %s┃%s\n", ColorBold, ColorReset, ColorCyan+ColorBold, ColorReset, ColorBold, ColorReset)
-	fmt.Printf("%s┗━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━┛%s\n", ColorBold, ColorReset)
+	printPreviewHeader()
 
 	// Subject line (first line)
 	if len(lines) > 0 && strings.TrimSpace(lines[0]) != "" {
@@ -71,14 +71,167 @@ func PreviewCommitMessage(message string) {
 	fmt.Printf("\n%s%s%s\n", ColorDim, strings.Repeat("─", 60), ColorReset)
 }
 
-// ShowCommitOptions displays the interactive menu with better formatting
-func ShowCommitOptions() {
+// printPreviewHeader prints the box above the commit message. It spans four lines.
+func printPreviewHeader() {
+	fmt.Printf("\n%s┏━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━┓%s\n", ColorBold, ColorReset)
+	fmt.Printf("%s┃%s                    %sGenerated Commit Message%s                  %s┃%s\n", ColorBold, ColorReset, ColorCyan+ColorBold, ColorReset, ColorBold, ColorReset)
+	fmt.Printf("%s┗━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━┛%s\n", ColorBold, ColorReset)
+}
+
+// StreamPreview shows a commit message in the preview box while it is being generated.
+// It tracks the lines it prints so that they can be replaced by the formatted preview.
+type StreamPreview struct {
+	lines  int // completed lines printed so far
+	column int // runes printed on the current line
+}
+
+// NewStreamPreview creates a preview that draws its box when the first text arrives
+func NewStreamPreview() *StreamPreview {
+	return &StreamPreview{}
+}
+
+// Write appends generated text to the preview
+func (p *StreamPreview) Write(chunk string) {
+	if chunk == "" {
+		return
+	}
+	if p.lines == 0 && p.column == 0 {
+		printPreviewHeader()
+		fmt.Println()
+		p.lines = 5
+	}
+
+	// Wrap long lines ourselves so the line count stays exact on any terminal at least as wide as the box
+	var out strings.Builder
+	out.WriteString(ColorDim)
+	for _, r := range chunk {
+		if r == '\n' || p.column == previewWidth {
+			out.WriteRune('\n')
+			p.lines++
+			p.column = 0
+			if r == '\n' {
+				continue
+			}
+		}
+		if r == '\r' {
+			continue
+		}
+		out.WriteRune(r)
+		p.column++
+	}
+	out.WriteString(ColorReset)
+	fmt.Print(out.String())
+}
+
+// Clear erases everything the preview has printed
+func (p *StreamPreview) Clear() {
+	if p.lines == 0 && p.column == 0 {
+		return
+	}
+
+	fmt.Print("\r" + clearLine())
+	for i := 0; i < p.lines; i++ {
+		fmt.Print("\033[1A" + clearLine())
+	}
+	p.lines = 0
+	p.column = 0
+}
+
+// ShowBreakingChangeWarning warns that the staged changes break the exported API
+func ShowBreakingChangeWarning(changes []string) {
+	if len(changes) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s%s⚠️  Breaking API change detected%s\n", ColorBold, ColorYellow, ColorReset)
+	for _, change := range changes {
+		fmt.Printf("%s   • %s%s\n", ColorYellow, change, ColorReset)
+	}
+}
+
+// ShowReasoning prints the reasoning of a reasoning model, dimmed to set it apart from the message
+func ShowReasoning(reasoning string) {
+	fmt.Printf("\n%s💭 Reasoning:%s\n", ColorBold, ColorReset)
+	for _, line := range strings.Split(strings.TrimSpace(reasoning), "\n") {
+		fmt.Printf("%s   %s%s\n", ColorDim, line, ColorReset)
+	}
+}
+
+// ShowCandidates lists the subjects of all messages generated in this session, marking the one shown above
+func ShowCandidates(subjects []string, current int) {
+	fmt.Printf("\n%sCandidates (%d):%s\n", ColorBold, len(subjects), ColorReset)
+	for i, subject := range subjects {
+		if i == current {
+			fmt.Printf("  %s%s▸ %d. %s%s\n", ColorBold, ColorGreen, i+1, subject, ColorReset)
+		} else {
+			fmt.Printf("  %s  %d. %s%s\n", ColorDim, i+1, subject, ColorReset)
+		}
+	}
+}
+
+// ShowCommitOptions displays the interactive menu with better formatting.
+// Choosing another candidate is offered when the session has more than one, undo after a refinement.
+func ShowCommitOptions(candidateCount int, canUndo bool) {
+	choices := 4
 	fmt.Println("\n" + ColorBold + "What would you like to do?" + ColorReset)
 	fmt.Printf("  %s1.%s 🔄 Re-generate commit message\n", ColorBold, ColorReset)
 	fmt.Printf("  %s2.%s ✅ Commit as-is\n", ColorBold, ColorReset)
 	fmt.Printf("  %s3.%s 📝 Edit and commit\n", ColorBold, ColorReset)
 	fmt.Printf("  %s4.%s 🚫 Quit (cleanup staged files)\n", ColorBold, ColorReset)
-	fmt.Printf("\n%sEnter your choice (1-4): %s", ColorBold, ColorReset)
+	if candidateCount > 1 {
+		choices = 5
+		fmt.Printf("  %s5.%s 🔢 Choose another candidate\n", ColorBold, ColorReset)
+	}
+	fmt.Printf("  %sr.%s ✏️  Refine…\n", ColorBold, ColorReset)
+	fmt.Printf("  %sh.%s 💡 Explain why and re-generate…\n", ColorBold, ColorReset)
+	letters := "r, h"
+	if canUndo {
+		fmt.Printf("  %su.%s ↩️  Undo the last refinement\n", ColorBold, ColorReset)
+		letters = "r, h, u"
+	}
+	fmt.Printf("\n%sEnter your choice (1-%d, %s): %s", ColorBold, choices, letters, ColorReset)
+}
+
+// ShowIntentPrompt asks why the change was made, showing the current answer if there is one
+func ShowIntentPrompt(current string) {
+	if current != "" {
+		fmt.Printf("%sCurrent reason:%s %s\n", ColorCyan, ColorReset, current)
+	}
+	fmt.Printf("%sWhy was this change made? (empty to clear): %s", ColorBold, ColorReset)
+}
+
+// ShowRefinePrompt asks how the current message should change
+func ShowRefinePrompt() {
+	fmt.Printf("%sHow should the message change? (e.g. \"shorter, no body\" or \"mention the migration\"): %s", ColorBold, ColorReset)
+}
+
+// ShowCandidatePrompt asks which candidate to show
+func ShowCandidatePrompt(candidateCount int) {
+	fmt.Printf("%sEnter candidate number (1-%d): %s", ColorBold, candidateCount, ColorReset)
+}
+
+// ShowPromptTooLarge explains that the prompt exceeds the context window of a model and lists
+// the ways forward as numbered options
+func ShowPromptTooLarge(model string, tokens, limit int, exact bool, options []string) {
+	count := "about "
+	if exact {
+		count = ""
+	}
+	fmt.Printf("\n%s%s⚠️  The diff does not fit %s%s\n", ColorBold, ColorYellow, model, ColorReset)
+	fmt.Printf("%sThe prompt is %s%d tokens, the model accepts %d.%s\n", ColorYellow, count, tokens, limit, ColorReset)
+
+	fmt.Println("\n" + ColorBold + "What would you like to do?" + ColorReset)
+	for i, option := range options {
+		fmt.Printf("  %s%d.%s %s\n", ColorBold, i+1, ColorReset, option)
+	}
+	fmt.Printf("\n%sEnter your choice (1-%d): %s", ColorBold, len(options), ColorReset)
+}
+
+// ShowCostConfirmation asks whether to send a request with a high estimated cost
+func ShowCostConfirmation(model, cost, threshold string) {
+	fmt.Printf("\n%s%s💰 Generating with %s will cost up to about %s (above your %s threshold).%s\n",
+		ColorBold, ColorYellow, model, cost, threshold, ColorReset)
+	fmt.Printf("%sContinue? (y/N): %s", ColorBold, ColorReset)
 }
 
 // ShowSetupWelcome displays a welcome message for setup
@@ -0,0 +1,167 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	// LedgerFile is the name of the ledger in the config directory
+	LedgerFile = "usage.jsonl"
+
+	ledgerFilePerm = 0600
+	// maxLineSize bounds a single ledger line, entries are far smaller
+	maxLineSize = 64 * 1024
+)
+
+// Period layouts used to group entries
+const (
+	Daily   = "2006-01-02"
+	Monthly = "2006-01"
+)
+
+// Entry is a single provider call recorded in the ledger
+type Entry struct {
+	Time             time.Time `json:"time"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	LatencyMs        int64     `json:"latency_ms"`
+	Cost             float64   `json:"cost_usd"`
+	Priced           bool      `json:"priced"` // false when the model has no known price
+}
+
+// Total aggregates the entries of one model in one period
+type Total struct {
+	Period           string // e.g. "2025-03-14" or "2025-03"
+	Provider         string
+	Model            string
+	Calls            int
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+	Unpriced         int // calls of models without a known price, not included in Cost
+}
+
+// Ledger is an append-only file with one JSON entry per line
+type Ledger struct {
+	path string
+}
+
+// NewLedger returns the ledger stored at path. The file is created on the first Append.
+func NewLedger(path string) *Ledger {
+	return &Ledger{path: path}
+}
+
+// Append adds entries to the end of the ledger
+func (l *Ledger) Append(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, ledgerFilePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to write usage ledger: %w", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write usage ledger: %w", err)
+	}
+	return nil
+}
+
+// Entries reads all entries of the ledger. A missing ledger has no entries,
+// and lines that cannot be parsed (e.g. after an interrupted write) are skipped.
+func (l *Ledger) Entries() ([]Entry, error) {
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+	return entries, nil
+}
+
+// MonthCost returns the estimated cost of all calls in the calendar month of now
+func MonthCost(entries []Entry, now time.Time) float64 {
+	month := now.Format(Monthly)
+
+	var cost float64
+	for _, entry := range entries {
+		if entry.Time.In(now.Location()).Format(Monthly) == month {
+			cost += entry.Cost
+		}
+	}
+	return cost
+}
+
+// Summarize groups the entries since the given time by period and model. layout is Daily
+// or Monthly. Totals are sorted by period, newest first, then by cost and model.
+func Summarize(entries []Entry, layout string, since time.Time) []Total {
+	type key struct{ period, provider, model string }
+
+	totals := make(map[key]*Total)
+	for _, entry := range entries {
+		if entry.Time.Before(since) {
+			continue
+		}
+
+		k := key{entry.Time.In(since.Location()).Format(layout), entry.Provider, entry.Model}
+		total, ok := totals[k]
+		if !ok {
+			total = &Total{Period: k.period, Provider: k.provider, Model: k.model}
+			totals[k] = total
+		}
+		total.Calls++
+		total.PromptTokens += entry.PromptTokens
+		total.CompletionTokens += entry.CompletionTokens
+		total.Cost += entry.Cost
+		if !entry.Priced {
+			total.Unpriced++
+		}
+	}
+
+	result := make([]Total, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Period != result[j].Period {
+			return result[i].Period > result[j].Period
+		}
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		return result[i].Model < result[j].Model
+	})
+	return result
+}
@@ -0,0 +1,79 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLedger(t *testing.T) {
+	ledger := NewLedger(filepath.Join(t.TempDir(), LedgerFile))
+
+	// A missing ledger has no entries
+	entries, err := ledger.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Expected no entries, got %v (%v)", entries, err)
+	}
+
+	first := Entry{Time: time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC), Provider: "Anthropic", Model: "claude-3-5-haiku-20241022", PromptTokens: 1200, CompletionTokens: 40, LatencyMs: 900, Cost: 0.00112, Priced: true}
+	second := Entry{Time: time.Date(2025, 3, 15, 9, 0, 0, 0, time.UTC), Provider: "Ollama", Model: "llama3.2", PromptTokens: 800, CompletionTokens: 30, LatencyMs: 4000, Priced: true}
+	if err := ledger.Append(first); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := ledger.Append(second); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entries, err = ledger.Entries()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(entries, []Entry{first, second}) {
+		t.Errorf("Unexpected entries %+v", entries)
+	}
+
+	// A truncated line from an interrupted write is skipped
+	file, err := os.OpenFile(ledger.path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_, _ = file.WriteString(`{"time": "2025-03-16T`)
+	_ = file.Close()
+
+	entries, err = ledger.Entries()
+	if err != nil || len(entries) != 2 {
+		t.Errorf("Expected the 2 valid entries, got %d (%v)", len(entries), err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2025, 3, d, hour, 0, 0, 0, time.UTC) }
+	entries := []Entry{
+		{Time: day(1, 9), Provider: "Anthropic", Model: "haiku", PromptTokens: 100, CompletionTokens: 10, Cost: 0.5, Priced: true},
+		{Time: day(1, 17), Provider: "Anthropic", Model: "haiku", PromptTokens: 200, CompletionTokens: 20, Cost: 0.25, Priced: true},
+		{Time: day(1, 18), Provider: "Novita.ai", Model: "qwen", PromptTokens: 50, CompletionTokens: 5},
+		{Time: day(2, 9), Provider: "Gemini", Model: "flash", PromptTokens: 300, CompletionTokens: 30, Cost: 1, Priced: true},
+		{Time: time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC), Provider: "Gemini", Model: "flash", Cost: 2, Priced: true},
+	}
+
+	daily := Summarize(entries, Daily, day(1, 0))
+	expected := []Total{
+		{Period: "2025-03-02", Provider: "Gemini", Model: "flash", Calls: 1, PromptTokens: 300, CompletionTokens: 30, Cost: 1},
+		{Period: "2025-03-01", Provider: "Anthropic", Model: "haiku", Calls: 2, PromptTokens: 300, CompletionTokens: 30, Cost: 0.75},
+		{Period: "2025-03-01", Provider: "Novita.ai", Model: "qwen", Calls: 1, PromptTokens: 50, CompletionTokens: 5, Unpriced: 1},
+	}
+	if !reflect.DeepEqual(daily, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, daily)
+	}
+
+	monthly := Summarize(entries, Monthly, time.Time{}.In(time.UTC))
+	if len(monthly) != 4 || monthly[len(monthly)-1].Period != "2025-02" {
+		t.Errorf("Expected 4 monthly totals ending with February, got %+v", monthly)
+	}
+
+	if got := MonthCost(entries, day(20, 12)); got != 1.75 {
+		t.Errorf("Expected March cost 1.75, got %f", got)
+	}
+}